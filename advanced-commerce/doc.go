@@ -0,0 +1,36 @@
+// Package AdvancedCommerce is the Go SDK client for Apple's Advanced
+// Commerce API, which lets apps with large or dynamic catalogs sell
+// one-time charges and subscriptions whose SKUs, names and prices are
+// defined at purchase time rather than in App Store Connect.
+//
+// The API has two halves:
+//
+//   - Server endpoints (migrate a subscription, change its metadata,
+//     cancel or revoke it, refund a transaction, check a request's
+//     status), served from the App Store Server API hosts with the same
+//     JWT. They are methods on *Service, which wraps an
+//     *AppStoreServer.Service so it shares its environment, base URL
+//     and *AppStoreServer.APIError errors.
+//
+//   - In-app requests, which your server builds and signs and the app
+//     passes to StoreKit as a purchase option. [Service.SignInAppRequest]
+//     returns that JWS, signed with the same key as the *Apple.Client.
+//
+// Signing an in-app request:
+//
+//	svc := AdvancedCommerce.NewService(client)
+//	signed, err := svc.SignInAppRequest(&AdvancedCommerce.SubscriptionCreateRequest{
+//	    RequestInfo: AdvancedCommerce.NewRequestInfo(),
+//	    Currency:    "USD",
+//	    TaxCode:     "C003-00-2",
+//	    Period:      AdvancedCommerce.PeriodP1M,
+//	    Descriptors: AdvancedCommerce.Descriptors{DisplayName: "News+", Description: "All sections"},
+//	    Items: []AdvancedCommerce.SubscriptionCreateItem{
+//	        {SKU: "news.all", DisplayName: "All", Description: "Every section", Price: 9990},
+//	    },
+//	})
+//
+// Prices are in milliunits of Currency (9990 is 9.99). Responses carry
+// signed transactions and renewal info as types.JWSTransaction and
+// types.JWSRenewalInfo; decode them with Decrypt or DecryptWith.
+package AdvancedCommerce
@@ -0,0 +1,338 @@
+package AdvancedCommerce
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/godrealms/go-apple-sdk/types"
+)
+
+// InAppAudience is the aud claim of a signed in-app request.
+const InAppAudience = "advanced-commerce-api"
+
+// Operation Identifies the kind of an in-app request.
+type Operation string
+
+const (
+	OperationCreateOneTimeCharge    Operation = "CREATE_ONE_TIME_CHARGE"
+	OperationCreateSubscription     Operation = "CREATE_SUBSCRIPTION"
+	OperationModifySubscription     Operation = "MODIFY_SUBSCRIPTION"
+	OperationReactivateSubscription Operation = "REACTIVATE_SUBSCRIPTION"
+)
+
+// ChangeReason Why a subscription item changes.
+type ChangeReason string
+
+const (
+	ChangeReasonUpgrade    ChangeReason = "UPGRADE"
+	ChangeReasonDowngrade  ChangeReason = "DOWNGRADE"
+	ChangeReasonApplyOffer ChangeReason = "APPLY_OFFER"
+)
+
+// InAppRequest is a request the app passes to StoreKit. Sign one with
+// [Service.SignInAppRequest]; the operation and version fields are
+// added when it is signed.
+type InAppRequest interface {
+	Operation() Operation
+	Validate() error
+}
+
+// OneTimeChargeItem The product a one-time charge sells.
+type OneTimeChargeItem struct {
+	SKU         string `json:"SKU"`
+	Description string `json:"description"`
+	DisplayName string `json:"displayName"`
+	Price       int64  `json:"price"`
+}
+
+// OneTimeChargeCreateRequest An in-app request for a one-time charge.
+type OneTimeChargeCreateRequest struct {
+	RequestInfo RequestInfo       `json:"requestInfo"`
+	Currency    string            `json:"currency"`
+	TaxCode     string            `json:"taxCode"`
+	Item        OneTimeChargeItem `json:"item"`
+	Storefront  string            `json:"storefront,omitempty"`
+}
+
+// Operation returns [OperationCreateOneTimeCharge].
+func (*OneTimeChargeCreateRequest) Operation() Operation { return OperationCreateOneTimeCharge }
+
+// Validate checks the required fields and Apple's field limits.
+func (r *OneTimeChargeCreateRequest) Validate() error {
+	if r == nil {
+		return errors.New("advanced commerce: one-time charge: request is nil")
+	}
+	if err := r.RequestInfo.validate(); err != nil {
+		return err
+	}
+	if err := validateCurrency(r.Currency); err != nil {
+		return err
+	}
+	if r.TaxCode == "" {
+		return errTaxCodeRequired
+	}
+	if err := validateSKU("item", r.Item.SKU); err != nil {
+		return err
+	}
+	if err := validateText("item", r.Item.Description, r.Item.DisplayName); err != nil {
+		return err
+	}
+	return validatePrice("item", r.Item.Price)
+}
+
+// SubscriptionCreateItem An item of a new subscription.
+type SubscriptionCreateItem struct {
+	SKU         string `json:"SKU"`
+	Description string `json:"description"`
+	DisplayName string `json:"displayName"`
+	Price       int64  `json:"price"`
+}
+
+// SubscriptionCreateRequest An in-app request for a new auto-renewable subscription.
+type SubscriptionCreateRequest struct {
+	RequestInfo RequestInfo              `json:"requestInfo"`
+	Currency    string                   `json:"currency"`
+	TaxCode     string                   `json:"taxCode"`
+	Period      Period                   `json:"period"`
+	Descriptors Descriptors              `json:"descriptors"`
+	Items       []SubscriptionCreateItem `json:"items"`
+	// The transaction ID of a previous subscription, to resubscribe a customer.
+	PreviousTransactionId string `json:"previousTransactionId,omitempty"`
+	Storefront            string `json:"storefront,omitempty"`
+}
+
+// Operation returns [OperationCreateSubscription].
+func (*SubscriptionCreateRequest) Operation() Operation { return OperationCreateSubscription }
+
+// Validate checks the required fields and Apple's field limits.
+func (r *SubscriptionCreateRequest) Validate() error {
+	if r == nil {
+		return errors.New("advanced commerce: create subscription: request is nil")
+	}
+	if err := r.RequestInfo.validate(); err != nil {
+		return err
+	}
+	if err := validateCurrency(r.Currency); err != nil {
+		return err
+	}
+	if r.TaxCode == "" {
+		return errTaxCodeRequired
+	}
+	if err := validatePeriod(r.Period); err != nil {
+		return err
+	}
+	if err := r.Descriptors.validate(); err != nil {
+		return err
+	}
+	if len(r.Items) == 0 {
+		return errors.New("advanced commerce: create subscription: at least one item is required")
+	}
+	for i, item := range r.Items {
+		field := fmt.Sprintf("items[%d]", i)
+		if err := validateSKU(field, item.SKU); err != nil {
+			return err
+		}
+		if err := validateText(field, item.Description, item.DisplayName); err != nil {
+			return err
+		}
+		if err := validatePrice(field, item.Price); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SubscriptionModifyAddItem An item to add to a subscription.
+type SubscriptionModifyAddItem struct {
+	SKU         string `json:"SKU"`
+	Description string `json:"description"`
+	DisplayName string `json:"displayName"`
+	Price       int64  `json:"price"`
+	// The price for the rest of the current billing cycle, when it differs from Price.
+	ProratedPrice *int64 `json:"proratedPrice,omitempty"`
+}
+
+// SubscriptionModifyChangeItem An item to replace in a subscription.
+type SubscriptionModifyChangeItem struct {
+	CurrentSKU    string       `json:"currentSKU"`
+	SKU           string       `json:"SKU"`
+	Description   string       `json:"description"`
+	DisplayName   string       `json:"displayName"`
+	Price         int64        `json:"price"`
+	ProratedPrice *int64       `json:"proratedPrice,omitempty"`
+	Reason        ChangeReason `json:"reason"`
+	Effective     Effective    `json:"effective"`
+}
+
+// SubscriptionModifyRemoveItem An item to remove from a subscription.
+type SubscriptionModifyRemoveItem struct {
+	SKU string `json:"SKU"`
+}
+
+// SubscriptionModifyInAppRequest An in-app request that adds, changes or removes subscription items.
+type SubscriptionModifyInAppRequest struct {
+	RequestInfo RequestInfo `json:"requestInfo"`
+	// (Required) The transaction ID of the subscription to modify.
+	TransactionId string                         `json:"transactionId"`
+	AddItems      []SubscriptionModifyAddItem    `json:"addItems,omitempty"`
+	ChangeItems   []SubscriptionModifyChangeItem `json:"changeItems,omitempty"`
+	RemoveItems   []SubscriptionModifyRemoveItem `json:"removeItems,omitempty"`
+	Descriptors   *Descriptors                   `json:"descriptors,omitempty"`
+	Currency      string                         `json:"currency,omitempty"`
+	TaxCode       string                         `json:"taxCode,omitempty"`
+	// Whether the subscription keeps its current renewal date.
+	RetainBillingCycle bool   `json:"retainBillingCycle"`
+	Storefront         string `json:"storefront,omitempty"`
+}
+
+// Operation returns [OperationModifySubscription].
+func (*SubscriptionModifyInAppRequest) Operation() Operation { return OperationModifySubscription }
+
+// Validate checks the required fields and Apple's field limits.
+func (r *SubscriptionModifyInAppRequest) Validate() error {
+	if r == nil {
+		return errors.New("advanced commerce: modify subscription: request is nil")
+	}
+	if err := r.RequestInfo.validate(); err != nil {
+		return err
+	}
+	if r.TransactionId == "" {
+		return errors.New("advanced commerce: modify subscription: transactionId is required")
+	}
+	if len(r.AddItems) == 0 && len(r.ChangeItems) == 0 && len(r.RemoveItems) == 0 && r.Descriptors == nil {
+		return errors.New("advanced commerce: modify subscription: nothing to change")
+	}
+	priced := len(r.AddItems) > 0 || len(r.ChangeItems) > 0
+	if priced {
+		if err := validateCurrency(r.Currency); err != nil {
+			return err
+		}
+	}
+	for i, item := range r.AddItems {
+		field := fmt.Sprintf("addItems[%d]", i)
+		if err := validateSKU(field, item.SKU); err != nil {
+			return err
+		}
+		if err := validateText(field, item.Description, item.DisplayName); err != nil {
+			return err
+		}
+		if err := validatePrice(field, item.Price); err != nil {
+			return err
+		}
+	}
+	for i, item := range r.ChangeItems {
+		field := fmt.Sprintf("changeItems[%d]", i)
+		if err := validateSKU(field, item.CurrentSKU); err != nil {
+			return err
+		}
+		if err := validateSKU(field, item.SKU); err != nil {
+			return err
+		}
+		if err := validateText(field, item.Description, item.DisplayName); err != nil {
+			return err
+		}
+		if err := validatePrice(field, item.Price); err != nil {
+			return err
+		}
+		switch item.Reason {
+		case ChangeReasonUpgrade, ChangeReasonDowngrade, ChangeReasonApplyOffer:
+		default:
+			return fmt.Errorf("advanced commerce: %s: invalid reason %q", field, item.Reason)
+		}
+		if err := validateEffective(field, item.Effective, true); err != nil {
+			return err
+		}
+	}
+	for i, item := range r.RemoveItems {
+		if err := validateSKU(fmt.Sprintf("removeItems[%d]", i), item.SKU); err != nil {
+			return err
+		}
+	}
+	if r.Descriptors != nil {
+		return r.Descriptors.validate()
+	}
+	return nil
+}
+
+// SubscriptionReactivateItem An item to restore when a subscription is reactivated.
+type SubscriptionReactivateItem struct {
+	SKU string `json:"SKU"`
+}
+
+// SubscriptionReactivateInAppRequest An in-app request that turns automatic renewal back on.
+type SubscriptionReactivateInAppRequest struct {
+	RequestInfo RequestInfo `json:"requestInfo"`
+	// (Required) The transaction ID of the subscription to reactivate.
+	TransactionId string `json:"transactionId"`
+	// The items to reactivate; all items when empty.
+	Items      []SubscriptionReactivateItem `json:"items,omitempty"`
+	Storefront string                       `json:"storefront,omitempty"`
+}
+
+// Operation returns [OperationReactivateSubscription].
+func (*SubscriptionReactivateInAppRequest) Operation() Operation {
+	return OperationReactivateSubscription
+}
+
+// Validate checks the required fields.
+func (r *SubscriptionReactivateInAppRequest) Validate() error {
+	if r == nil {
+		return errors.New("advanced commerce: reactivate subscription: request is nil")
+	}
+	if err := r.RequestInfo.validate(); err != nil {
+		return err
+	}
+	if r.TransactionId == "" {
+		return errors.New("advanced commerce: reactivate subscription: transactionId is required")
+	}
+	for i, item := range r.Items {
+		if err := validateSKU(fmt.Sprintf("items[%d]", i), item.SKU); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encodeInAppRequest validates req and returns its JSON with the
+// operation and version fields set, base64-encoded as Apple expects
+// in the request claim.
+func encodeInAppRequest(req InAppRequest) (string, error) {
+	if req == nil {
+		return "", errors.New("advanced commerce: in-app request is nil")
+	}
+	if err := req.Validate(); err != nil {
+		return "", err
+	}
+	raw, err := json.Marshal(req)
+	if err != nil {
+		return "", fmt.Errorf("advanced commerce: encode in-app request: %w", err)
+	}
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return "", fmt.Errorf("advanced commerce: encode in-app request: %w", err)
+	}
+	fields["operation"], _ = json.Marshal(req.Operation())
+	fields["version"], _ = json.Marshal(Version)
+	if raw, err = json.Marshal(fields); err != nil {
+		return "", fmt.Errorf("advanced commerce: encode in-app request: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(raw), nil
+}
+
+// inAppClaims returns the claims of a signed in-app request; the
+// signer adds iss, bid and iat.
+func inAppClaims(req InAppRequest) (jwt.MapClaims, error) {
+	encoded, err := encodeInAppRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	return jwt.MapClaims{
+		"aud":     InAppAudience,
+		"nonce":   types.NewRequestIdentifier().String(),
+		"request": encoded,
+	}, nil
+}
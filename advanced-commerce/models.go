@@ -0,0 +1,186 @@
+package AdvancedCommerce
+
+import (
+	"errors"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/godrealms/go-apple-sdk/types"
+)
+
+// Version is the request schema version the SDK writes into in-app requests.
+const Version = "1"
+
+// Field limits Apple documents for items and descriptors.
+const (
+	MaxSKULength         = 128
+	MaxDescriptionLength = 45
+	MaxDisplayNameLength = 30
+)
+
+// Period The duration of a single subscription billing cycle, in ISO 8601 form.
+type Period string
+
+const (
+	PeriodP1W Period = "P1W" // One week.
+	PeriodP1M Period = "P1M" // One month.
+	PeriodP2M Period = "P2M" // Two months.
+	PeriodP3M Period = "P3M" // Three months.
+	PeriodP6M Period = "P6M" // Six months.
+	PeriodP1Y Period = "P1Y" // One year.
+)
+
+// Effective When a subscription change takes effect.
+type Effective string
+
+const (
+	EffectiveImmediately   Effective = "IMMEDIATELY"     // The change applies right away.
+	EffectiveNextBillCycle Effective = "NEXT_BILL_CYCLE" // The change applies at the next renewal.
+)
+
+// RefundReason The reason for a refund or revocation.
+type RefundReason string
+
+const (
+	RefundReasonUnintendedPurchase      RefundReason = "UNINTENDED_PURCHASE"
+	RefundReasonFulfillmentIssue        RefundReason = "FULFILLMENT_ISSUE"
+	RefundReasonUnsatisfiedWithPurchase RefundReason = "UNSATISFIED_WITH_PURCHASE"
+	RefundReasonLegal                   RefundReason = "LEGAL"
+	RefundReasonOther                   RefundReason = "OTHER"
+	RefundReasonModifyItemsRefund       RefundReason = "MODIFY_ITEMS_REFUND"
+	RefundReasonSimulateRefundDecline   RefundReason = "SIMULATE_REFUND_DECLINE"
+)
+
+// RefundType How much of a purchase to refund.
+type RefundType string
+
+const (
+	RefundTypeFull     RefundType = "FULL"     // Refund the full amount.
+	RefundTypeProrated RefundType = "PRORATED" // Refund the unused part of the billing cycle.
+	RefundTypeCustom   RefundType = "CUSTOM"   // Refund the amount given in refundAmount.
+)
+
+// RequestInfo Identifies a request and, optionally, the customer account it belongs to.
+type RequestInfo struct {
+	// (Required) A UUID you generate to identify the request; reuse it when retrying.
+	RequestReferenceId types.UUID `json:"requestReferenceId"`
+	// The UUID that associates the purchase with a customer on your service.
+	AppAccountToken types.UUID `json:"appAccountToken,omitempty"`
+	// The consistency token from a previous response, to order requests for the same subscription.
+	ConsistencyToken string `json:"consistencyToken,omitempty"`
+}
+
+// NewRequestInfo returns a RequestInfo with a random requestReferenceId.
+func NewRequestInfo() RequestInfo {
+	return RequestInfo{RequestReferenceId: types.UUID(types.NewRequestIdentifier())}
+}
+
+func (r RequestInfo) validate() error {
+	if !r.RequestReferenceId.IsValidUUID() {
+		return fmt.Errorf("advanced commerce: requestReferenceId %q is not a UUID", r.RequestReferenceId)
+	}
+	if r.AppAccountToken != "" && !r.AppAccountToken.IsValidUUID() {
+		return fmt.Errorf("advanced commerce: appAccountToken %q is not a UUID", r.AppAccountToken)
+	}
+	return nil
+}
+
+// Descriptors The display name and description of a subscription, shown to the customer.
+type Descriptors struct {
+	Description string `json:"description"`
+	DisplayName string `json:"displayName"`
+}
+
+func (d Descriptors) validate() error {
+	return validateText("descriptors", d.Description, d.DisplayName)
+}
+
+// validateSKU checks an item's SKU.
+func validateSKU(field, sku string) error {
+	if sku == "" {
+		return fmt.Errorf("advanced commerce: %s: SKU is required", field)
+	}
+	if len(sku) > MaxSKULength {
+		return fmt.Errorf("advanced commerce: %s: SKU longer than %d characters", field, MaxSKULength)
+	}
+	return nil
+}
+
+// validateText checks a required description and display name.
+func validateText(field, description, displayName string) error {
+	switch {
+	case description == "" || displayName == "":
+		return fmt.Errorf("advanced commerce: %s: description and displayName are required", field)
+	case utf8.RuneCountInString(description) > MaxDescriptionLength:
+		return fmt.Errorf("advanced commerce: %s: description longer than %d characters", field, MaxDescriptionLength)
+	case utf8.RuneCountInString(displayName) > MaxDisplayNameLength:
+		return fmt.Errorf("advanced commerce: %s: displayName longer than %d characters", field, MaxDisplayNameLength)
+	}
+	return nil
+}
+
+// validateOptionalText checks a description and display name that may be left empty.
+func validateOptionalText(field, description, displayName string) error {
+	switch {
+	case utf8.RuneCountInString(description) > MaxDescriptionLength:
+		return fmt.Errorf("advanced commerce: %s: description longer than %d characters", field, MaxDescriptionLength)
+	case utf8.RuneCountInString(displayName) > MaxDisplayNameLength:
+		return fmt.Errorf("advanced commerce: %s: displayName longer than %d characters", field, MaxDisplayNameLength)
+	}
+	return nil
+}
+
+func validateCurrency(currency string) error {
+	if len(currency) != 3 {
+		return fmt.Errorf("advanced commerce: currency %q is not an ISO 4217 code", currency)
+	}
+	for _, c := range currency {
+		if c < 'A' || c > 'Z' {
+			return fmt.Errorf("advanced commerce: currency %q is not an ISO 4217 code", currency)
+		}
+	}
+	return nil
+}
+
+func validatePrice(field string, price int64) error {
+	if price < 0 {
+		return fmt.Errorf("advanced commerce: %s: negative price %d", field, price)
+	}
+	return nil
+}
+
+func validatePeriod(p Period) error {
+	switch p {
+	case PeriodP1W, PeriodP1M, PeriodP2M, PeriodP3M, PeriodP6M, PeriodP1Y:
+		return nil
+	}
+	return fmt.Errorf("advanced commerce: unknown period %q", p)
+}
+
+func validateEffective(field string, e Effective, required bool) error {
+	switch e {
+	case EffectiveImmediately, EffectiveNextBillCycle:
+		return nil
+	case "":
+		if !required {
+			return nil
+		}
+	}
+	return fmt.Errorf("advanced commerce: %s: invalid effective %q", field, e)
+}
+
+func validateRefund(reason RefundReason, refundType RefundType) error {
+	switch reason {
+	case RefundReasonUnintendedPurchase, RefundReasonFulfillmentIssue, RefundReasonUnsatisfiedWithPurchase,
+		RefundReasonLegal, RefundReasonOther, RefundReasonModifyItemsRefund, RefundReasonSimulateRefundDecline:
+	default:
+		return fmt.Errorf("advanced commerce: unknown refundReason %q", reason)
+	}
+	switch refundType {
+	case RefundTypeFull, RefundTypeProrated, RefundTypeCustom:
+		return nil
+	}
+	return fmt.Errorf("advanced commerce: unknown refundType %q", refundType)
+}
+
+var errTaxCodeRequired = errors.New("advanced commerce: taxCode is required")
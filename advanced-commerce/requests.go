@@ -0,0 +1,307 @@
+package AdvancedCommerce
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/godrealms/go-apple-sdk/types"
+)
+
+// SubscriptionMigrateItem An item of an auto-renewable subscription being migrated to the Advanced Commerce API.
+type SubscriptionMigrateItem struct {
+	SKU         string `json:"SKU"`
+	Description string `json:"description"`
+	DisplayName string `json:"displayName"`
+}
+
+// SubscriptionMigrateRequest The request body for migrating a subscription to the Advanced Commerce API.
+type SubscriptionMigrateRequest struct {
+	RequestInfo RequestInfo `json:"requestInfo"`
+	// (Required) The descriptors the migrated subscription displays.
+	Descriptors Descriptors `json:"descriptors"`
+	// (Required) The items of the migrated subscription.
+	Items []SubscriptionMigrateItem `json:"items"`
+	// The items the subscription renews with, when they differ from Items.
+	RenewalItems []SubscriptionMigrateItem `json:"renewalItems,omitempty"`
+	// (Required) The product identifier of the Advanced Commerce generic subscription to migrate to.
+	TargetProductId string `json:"targetProductId"`
+	// (Required) The tax code that applies to the subscription.
+	TaxCode string `json:"taxCode"`
+	// The storefront the request applies to.
+	Storefront string `json:"storefront,omitempty"`
+}
+
+// Validate checks the required fields and Apple's field limits.
+func (r *SubscriptionMigrateRequest) Validate() error {
+	if r == nil {
+		return errors.New("advanced commerce: migrate: request is nil")
+	}
+	if err := r.RequestInfo.validate(); err != nil {
+		return err
+	}
+	if err := r.Descriptors.validate(); err != nil {
+		return err
+	}
+	if len(r.Items) == 0 {
+		return errors.New("advanced commerce: migrate: at least one item is required")
+	}
+	for i, item := range r.Items {
+		if err := validateMigrateItem(fmt.Sprintf("items[%d]", i), item); err != nil {
+			return err
+		}
+	}
+	for i, item := range r.RenewalItems {
+		if err := validateMigrateItem(fmt.Sprintf("renewalItems[%d]", i), item); err != nil {
+			return err
+		}
+	}
+	if r.TargetProductId == "" {
+		return errors.New("advanced commerce: migrate: targetProductId is required")
+	}
+	if r.TaxCode == "" {
+		return errTaxCodeRequired
+	}
+	return nil
+}
+
+func validateMigrateItem(field string, item SubscriptionMigrateItem) error {
+	if err := validateSKU(field, item.SKU); err != nil {
+		return err
+	}
+	return validateText(field, item.Description, item.DisplayName)
+}
+
+// SubscriptionChangeMetadataDescriptors New descriptors for a subscription and when they take effect.
+type SubscriptionChangeMetadataDescriptors struct {
+	Description string    `json:"description,omitempty"`
+	DisplayName string    `json:"displayName,omitempty"`
+	Effective   Effective `json:"effective"`
+}
+
+// SubscriptionChangeMetadataItem New metadata for one subscription item. Empty fields are left unchanged.
+type SubscriptionChangeMetadataItem struct {
+	// (Required) The SKU of the item to change.
+	CurrentSKU string `json:"currentSKU"`
+	// The item's new SKU.
+	SKU         string    `json:"SKU,omitempty"`
+	Description string    `json:"description,omitempty"`
+	DisplayName string    `json:"displayName,omitempty"`
+	Effective   Effective `json:"effective"`
+}
+
+// SubscriptionChangeMetadataRequest The request body for changing a subscription's SKUs, names and descriptions
+// without changing its price.
+type SubscriptionChangeMetadataRequest struct {
+	RequestInfo RequestInfo                            `json:"requestInfo"`
+	Descriptors *SubscriptionChangeMetadataDescriptors `json:"descriptors,omitempty"`
+	Items       []SubscriptionChangeMetadataItem       `json:"items,omitempty"`
+	// The new tax code, applied at the next renewal.
+	TaxCode    string `json:"taxCode,omitempty"`
+	Storefront string `json:"storefront,omitempty"`
+}
+
+// Validate checks the required fields and Apple's field limits.
+func (r *SubscriptionChangeMetadataRequest) Validate() error {
+	if r == nil {
+		return errors.New("advanced commerce: change metadata: request is nil")
+	}
+	if err := r.RequestInfo.validate(); err != nil {
+		return err
+	}
+	if r.Descriptors == nil && len(r.Items) == 0 && r.TaxCode == "" {
+		return errors.New("advanced commerce: change metadata: nothing to change")
+	}
+	if d := r.Descriptors; d != nil {
+		if err := validateOptionalText("descriptors", d.Description, d.DisplayName); err != nil {
+			return err
+		}
+		if err := validateEffective("descriptors", d.Effective, true); err != nil {
+			return err
+		}
+	}
+	for i, item := range r.Items {
+		field := fmt.Sprintf("items[%d]", i)
+		if err := validateSKU(field, item.CurrentSKU); err != nil {
+			return err
+		}
+		if item.SKU != "" {
+			if err := validateSKU(field, item.SKU); err != nil {
+				return err
+			}
+		}
+		if err := validateOptionalText(field, item.Description, item.DisplayName); err != nil {
+			return err
+		}
+		if err := validateEffective(field, item.Effective, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SubscriptionPriceChangeItem A new price for one subscription item, applied at the next renewal.
+type SubscriptionPriceChangeItem struct {
+	SKU   string `json:"SKU"`
+	Price int64  `json:"price"`
+	// SKUs of other items whose price change is tied to this one.
+	DependentSKUs []string `json:"dependentSKUs,omitempty"`
+}
+
+// SubscriptionPriceChangeRequest The request body for changing the price of subscription items.
+type SubscriptionPriceChangeRequest struct {
+	RequestInfo RequestInfo                   `json:"requestInfo"`
+	Items       []SubscriptionPriceChangeItem `json:"items"`
+	Storefront  string                        `json:"storefront,omitempty"`
+}
+
+// Validate checks the required fields and Apple's field limits.
+func (r *SubscriptionPriceChangeRequest) Validate() error {
+	if r == nil {
+		return errors.New("advanced commerce: change price: request is nil")
+	}
+	if err := r.RequestInfo.validate(); err != nil {
+		return err
+	}
+	if len(r.Items) == 0 {
+		return errors.New("advanced commerce: change price: at least one item is required")
+	}
+	for i, item := range r.Items {
+		field := fmt.Sprintf("items[%d]", i)
+		if err := validateSKU(field, item.SKU); err != nil {
+			return err
+		}
+		if err := validatePrice(field, item.Price); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SubscriptionCancelRequest The request body for turning off a subscription's automatic renewal.
+type SubscriptionCancelRequest struct {
+	RequestInfo RequestInfo `json:"requestInfo"`
+	Storefront  string      `json:"storefront,omitempty"`
+}
+
+// Validate checks the required fields.
+func (r *SubscriptionCancelRequest) Validate() error {
+	if r == nil {
+		return errors.New("advanced commerce: cancel: request is nil")
+	}
+	return r.RequestInfo.validate()
+}
+
+// SubscriptionRevokeRequest The request body for immediately ending a subscription and refunding it.
+type SubscriptionRevokeRequest struct {
+	RequestInfo  RequestInfo  `json:"requestInfo"`
+	RefundReason RefundReason `json:"refundReason"`
+	RefundType   RefundType   `json:"refundType"`
+	// Whether you prefer Apple to grant the refund when its risk checks are inconclusive.
+	RefundRiskingPreference bool   `json:"refundRiskingPreference"`
+	Storefront              string `json:"storefront,omitempty"`
+}
+
+// Validate checks the required fields. Revocations cannot use [RefundTypeCustom].
+func (r *SubscriptionRevokeRequest) Validate() error {
+	if r == nil {
+		return errors.New("advanced commerce: revoke: request is nil")
+	}
+	if err := r.RequestInfo.validate(); err != nil {
+		return err
+	}
+	if err := validateRefund(r.RefundReason, r.RefundType); err != nil {
+		return err
+	}
+	if r.RefundType == RefundTypeCustom {
+		return errors.New("advanced commerce: revoke: refundType CUSTOM is not supported")
+	}
+	return nil
+}
+
+// RequestRefundItem The refund of one item of a transaction.
+type RequestRefundItem struct {
+	SKU          string       `json:"SKU"`
+	RefundReason RefundReason `json:"refundReason"`
+	RefundType   RefundType   `json:"refundType"`
+	// The amount to refund, in milliunits; required for, and only allowed with, RefundTypeCustom.
+	RefundAmount int64 `json:"refundAmount,omitempty"`
+	// Whether to also revoke the customer's access to the item.
+	Revoke bool `json:"revoke"`
+}
+
+// RequestRefundRequest The request body for refunding a transaction.
+type RequestRefundRequest struct {
+	RequestInfo RequestInfo         `json:"requestInfo"`
+	Items       []RequestRefundItem `json:"items"`
+	// The currency of any RefundAmount, as an ISO 4217 code.
+	Currency                string `json:"currency,omitempty"`
+	RefundRiskingPreference bool   `json:"refundRiskingPreference"`
+	Storefront              string `json:"storefront,omitempty"`
+}
+
+// Validate checks the required fields and that custom amounts come with a currency.
+func (r *RequestRefundRequest) Validate() error {
+	if r == nil {
+		return errors.New("advanced commerce: refund: request is nil")
+	}
+	if err := r.RequestInfo.validate(); err != nil {
+		return err
+	}
+	if len(r.Items) == 0 {
+		return errors.New("advanced commerce: refund: at least one item is required")
+	}
+	custom := false
+	for i, item := range r.Items {
+		field := fmt.Sprintf("items[%d]", i)
+		if err := validateSKU(field, item.SKU); err != nil {
+			return err
+		}
+		if err := validateRefund(item.RefundReason, item.RefundType); err != nil {
+			return err
+		}
+		switch {
+		case item.RefundType == RefundTypeCustom && item.RefundAmount <= 0:
+			return fmt.Errorf("advanced commerce: %s: refundAmount is required for CUSTOM refunds", field)
+		case item.RefundType != RefundTypeCustom && item.RefundAmount != 0:
+			return fmt.Errorf("advanced commerce: %s: refundAmount is only allowed for CUSTOM refunds", field)
+		}
+		custom = custom || item.RefundType == RefundTypeCustom
+	}
+	if custom {
+		return validateCurrency(r.Currency)
+	}
+	return nil
+}
+
+// RequestStatus The processing state of an Advanced Commerce request.
+type RequestStatus string
+
+const (
+	RequestStatusPending   RequestStatus = "PENDING"   // Apple is still processing the request.
+	RequestStatusSucceeded RequestStatus = "SUCCEEDED" // The request completed.
+	RequestStatusFailed    RequestStatus = "FAILED"    // The request failed; see ErrorCode.
+)
+
+// SubscriptionResponse The response to a request that changes a subscription.
+type SubscriptionResponse struct {
+	// The subscription's renewal information, signed by the App Store.
+	SignedRenewalInfo types.JWSRenewalInfo `json:"signedRenewalInfo"`
+	// The transaction the change produced, signed by the App Store.
+	SignedTransactionInfo types.JWSTransaction `json:"signedTransactionInfo"`
+}
+
+// RequestRefundResponse The response to a refund request.
+type RequestRefundResponse struct {
+	// The refunded transaction, signed by the App Store.
+	SignedTransactionInfo types.JWSTransaction `json:"signedTransactionInfo"`
+}
+
+// RequestStatusResponse The processing state of a request you sent earlier.
+type RequestStatusResponse struct {
+	RequestReferenceId types.UUID    `json:"requestReferenceId"`
+	Status             RequestStatus `json:"status"`
+	// The Apple error code when Status is RequestStatusFailed.
+	ErrorCode int64 `json:"errorCode,omitempty"`
+	// The resulting transaction when Status is RequestStatusSucceeded.
+	SignedTransactionInfo types.JWSTransaction `json:"signedTransactionInfo,omitempty"`
+}
@@ -0,0 +1,164 @@
+package AdvancedCommerce
+
+import (
+	"context"
+	"errors"
+
+	Apple "github.com/godrealms/go-apple-sdk"
+	AppStoreServer "github.com/godrealms/go-apple-sdk/app-store-server"
+	"github.com/godrealms/go-apple-sdk/types"
+)
+
+// Service is the entry point into Advanced Commerce API endpoints.
+// Create one with [New] or [NewService] and reuse it.
+//
+// Service is safe for concurrent use by multiple goroutines.
+type Service struct {
+	client *Apple.Client
+	server *AppStoreServer.Service
+}
+
+// New constructs a [Service] from client. cfg selects the host exactly
+// as it does for [AppStoreServer.New]. client must be non-nil.
+func New(client *Apple.Client, cfg AppStoreServer.Config) *Service {
+	if client == nil {
+		panic("AdvancedCommerce.New: client is required")
+	}
+	return &Service{client: client, server: AppStoreServer.New(client, cfg)}
+}
+
+// NewService is shorthand for New(client, AppStoreServer.Config{}): a
+// Service for the client's own environment.
+func NewService(client *Apple.Client) *Service {
+	return New(client, AppStoreServer.Config{})
+}
+
+// Environment returns the environment the service sends requests to.
+func (s *Service) Environment() types.Environment { return s.server.Environment() }
+
+// SignInAppRequest validates req and returns it as a compact JWS,
+// signed with the client's key, for the app to pass to StoreKit.
+func (s *Service) SignInAppRequest(req InAppRequest) (string, error) {
+	claims, err := inAppClaims(req)
+	if err != nil {
+		return "", err
+	}
+	return s.client.SignJWS(claims)
+}
+
+// MigrateSubscription Migrate an auto-renewable subscription to an Advanced Commerce subscription.
+func (s *Service) MigrateSubscription(ctx context.Context, transactionId string, req *SubscriptionMigrateRequest) (*SubscriptionResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	result := new(SubscriptionResponse)
+	if err := s.post(ctx, "/advancedCommerce/v1/subscription/migrate/{transactionId}", transactionId, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ChangeSubscriptionMetadata Change the SKUs, descriptions, display names or tax code of a subscription.
+func (s *Service) ChangeSubscriptionMetadata(ctx context.Context, transactionId string, req *SubscriptionChangeMetadataRequest) (*SubscriptionResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	result := new(SubscriptionResponse)
+	if err := s.post(ctx, "/advancedCommerce/v1/subscription/changeMetadata/{transactionId}", transactionId, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ChangeSubscriptionPrice Change the price of subscription items from the next renewal.
+func (s *Service) ChangeSubscriptionPrice(ctx context.Context, transactionId string, req *SubscriptionPriceChangeRequest) (*SubscriptionResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	result := new(SubscriptionResponse)
+	if err := s.post(ctx, "/advancedCommerce/v1/subscription/changePrice/{transactionId}", transactionId, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// CancelSubscription Turn off automatic renewal; the customer keeps access until the period ends.
+func (s *Service) CancelSubscription(ctx context.Context, transactionId string, req *SubscriptionCancelRequest) (*SubscriptionResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	result := new(SubscriptionResponse)
+	if err := s.post(ctx, "/advancedCommerce/v1/subscription/cancel/{transactionId}", transactionId, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// RevokeSubscription End a subscription immediately and refund it.
+func (s *Service) RevokeSubscription(ctx context.Context, transactionId string, req *SubscriptionRevokeRequest) (*SubscriptionResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	result := new(SubscriptionResponse)
+	if err := s.post(ctx, "/advancedCommerce/v1/subscription/revoke/{transactionId}", transactionId, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// RequestRefund Refund some or all items of a one-time charge or subscription transaction.
+func (s *Service) RequestRefund(ctx context.Context, transactionId string, req *RequestRefundRequest) (*RequestRefundResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	result := new(RequestRefundResponse)
+	if err := s.post(ctx, "/advancedCommerce/v1/transaction/requestRefund/{transactionId}", transactionId, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetRequestStatus Get the processing state of a request by its requestReferenceId.
+func (s *Service) GetRequestStatus(ctx context.Context, requestReferenceId types.UUID) (*RequestStatusResponse, error) {
+	if !requestReferenceId.IsValidUUID() {
+		return nil, errors.New("advanced commerce: requestReferenceId is not a UUID")
+	}
+	result := new(RequestStatusResponse)
+	params := Apple.RequestParams{
+		Ctx:    ctx,
+		Method: "GET",
+		Path:   "/advancedCommerce/v1/request/{requestReferenceId}/status",
+		Result: result,
+		Headers: map[string]string{
+			"Accept": "application/json",
+		},
+		PathParams: map[string]string{
+			"requestReferenceId": string(requestReferenceId),
+		},
+	}
+	if err := s.server.Do(params); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// post sends a JSON body to a transaction-scoped endpoint.
+func (s *Service) post(ctx context.Context, path, transactionId string, body, result any) error {
+	if transactionId == "" {
+		return errors.New("advanced commerce: transactionId is required")
+	}
+	return s.server.Do(Apple.RequestParams{
+		Ctx:    ctx,
+		Method: "POST",
+		Path:   path,
+		Body:   body,
+		Result: result,
+		Headers: map[string]string{
+			"Content-Type": "application/json",
+			"Accept":       "application/json",
+		},
+		PathParams: map[string]string{
+			"transactionId": transactionId,
+		},
+	})
+}
@@ -0,0 +1,248 @@
+package AdvancedCommerce
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	Apple "github.com/godrealms/go-apple-sdk"
+	AppStoreServer "github.com/godrealms/go-apple-sdk/app-store-server"
+)
+
+const testRequestID = "a1b2c3d4-0000-4000-8000-000000000001"
+
+// newTestService returns a Service pointed at handler and the key its
+// client signs with.
+func newTestService(t *testing.T, handler http.Handler) (*Service, *ecdsa.PrivateKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+	pemKey := string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	client := Apple.NewClient(true, "KID123", "issuer-id", "com.example.app", pemKey)
+	return New(client, AppStoreServer.Config{BaseURL: srv.URL}), key
+}
+
+func TestService_Endpoints(t *testing.T) {
+	var mu sync.Mutex
+	var got []string
+	svc, _ := newTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		got = append(got, r.Method+" "+r.URL.Path)
+		mu.Unlock()
+		if r.Method == http.MethodPost && !strings.Contains(string(body), `"requestReferenceId":"`+testRequestID+`"`) {
+			t.Errorf("%s: body = %s", r.URL.Path, body)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/status"):
+			_, _ = w.Write([]byte(`{"requestReferenceId":"` + testRequestID + `","status":"SUCCEEDED","signedTransactionInfo":"t.t.t"}`))
+		case strings.Contains(r.URL.Path, "/transaction/"):
+			_, _ = w.Write([]byte(`{"signedTransactionInfo":"t.t.t"}`))
+		default:
+			_, _ = w.Write([]byte(`{"signedRenewalInfo":"r.r.r","signedTransactionInfo":"t.t.t"}`))
+		}
+	}))
+	ctx := context.Background()
+	info := RequestInfo{RequestReferenceId: testRequestID}
+
+	responses := []func() (*SubscriptionResponse, error){
+		func() (*SubscriptionResponse, error) {
+			return svc.MigrateSubscription(ctx, "1000", &SubscriptionMigrateRequest{
+				RequestInfo:     info,
+				Descriptors:     Descriptors{Description: "All sections", DisplayName: "News+"},
+				Items:           []SubscriptionMigrateItem{{SKU: "news.all", Description: "Every section", DisplayName: "All"}},
+				TargetProductId: "com.example.generic",
+				TaxCode:         "C003-00-2",
+			})
+		},
+		func() (*SubscriptionResponse, error) {
+			return svc.ChangeSubscriptionMetadata(ctx, "1000", &SubscriptionChangeMetadataRequest{
+				RequestInfo: info,
+				Items:       []SubscriptionChangeMetadataItem{{CurrentSKU: "news.all", DisplayName: "Everything", Effective: EffectiveNextBillCycle}},
+			})
+		},
+		func() (*SubscriptionResponse, error) {
+			return svc.ChangeSubscriptionPrice(ctx, "1000", &SubscriptionPriceChangeRequest{
+				RequestInfo: info,
+				Items:       []SubscriptionPriceChangeItem{{SKU: "news.all", Price: 12990}},
+			})
+		},
+		func() (*SubscriptionResponse, error) {
+			return svc.CancelSubscription(ctx, "1000", &SubscriptionCancelRequest{RequestInfo: info})
+		},
+		func() (*SubscriptionResponse, error) {
+			return svc.RevokeSubscription(ctx, "1000", &SubscriptionRevokeRequest{
+				RequestInfo: info, RefundReason: RefundReasonLegal, RefundType: RefundTypeFull,
+			})
+		},
+	}
+	for i, call := range responses {
+		resp, err := call()
+		if err != nil || resp.SignedRenewalInfo != "r.r.r" || resp.SignedTransactionInfo != "t.t.t" {
+			t.Errorf("call %d = %+v, %v", i, resp, err)
+		}
+	}
+	refund, err := svc.RequestRefund(ctx, "1000", &RequestRefundRequest{
+		RequestInfo: info,
+		Currency:    "USD",
+		Items:       []RequestRefundItem{{SKU: "news.all", RefundReason: RefundReasonOther, RefundType: RefundTypeCustom, RefundAmount: 1000}},
+	})
+	if err != nil || refund.SignedTransactionInfo != "t.t.t" {
+		t.Errorf("RequestRefund = %+v, %v", refund, err)
+	}
+	status, err := svc.GetRequestStatus(ctx, testRequestID)
+	if err != nil || status.Status != RequestStatusSucceeded {
+		t.Errorf("GetRequestStatus = %+v, %v", status, err)
+	}
+
+	want := []string{
+		"POST /advancedCommerce/v1/subscription/migrate/1000",
+		"POST /advancedCommerce/v1/subscription/changeMetadata/1000",
+		"POST /advancedCommerce/v1/subscription/changePrice/1000",
+		"POST /advancedCommerce/v1/subscription/cancel/1000",
+		"POST /advancedCommerce/v1/subscription/revoke/1000",
+		"POST /advancedCommerce/v1/transaction/requestRefund/1000",
+		"GET /advancedCommerce/v1/request/" + testRequestID + "/status",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("requests:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestService_APIError(t *testing.T) {
+	svc, _ := newTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"errorCode":4040010,"errorMessage":"Transaction id not found."}`))
+	}))
+	_, err := svc.CancelSubscription(context.Background(), "1000", &SubscriptionCancelRequest{RequestInfo: NewRequestInfo()})
+	var apiErr *AppStoreServer.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound || apiErr.ErrorCode != 4040010 {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestService_ValidationStaysLocal(t *testing.T) {
+	var calls int
+	svc, _ := newTestService(t, http.HandlerFunc(func(http.ResponseWriter, *http.Request) { calls++ }))
+	ctx := context.Background()
+	info := NewRequestInfo()
+
+	checks := map[string]error{
+		"bad reference id": func() error {
+			_, err := svc.CancelSubscription(ctx, "1000", &SubscriptionCancelRequest{RequestInfo: RequestInfo{RequestReferenceId: "x"}})
+			return err
+		}(),
+		"missing transaction id": func() error {
+			_, err := svc.CancelSubscription(ctx, "", &SubscriptionCancelRequest{RequestInfo: info})
+			return err
+		}(),
+		"custom revoke": func() error {
+			_, err := svc.RevokeSubscription(ctx, "1000", &SubscriptionRevokeRequest{RequestInfo: info, RefundReason: RefundReasonLegal, RefundType: RefundTypeCustom})
+			return err
+		}(),
+		"custom refund without currency": func() error {
+			_, err := svc.RequestRefund(ctx, "1000", &RequestRefundRequest{RequestInfo: info, Items: []RequestRefundItem{
+				{SKU: "a", RefundReason: RefundReasonOther, RefundType: RefundTypeCustom, RefundAmount: 10},
+			}})
+			return err
+		}(),
+		"empty metadata change": func() error {
+			_, err := svc.ChangeSubscriptionMetadata(ctx, "1000", &SubscriptionChangeMetadataRequest{RequestInfo: info})
+			return err
+		}(),
+		"migrate without tax code": func() error {
+			_, err := svc.MigrateSubscription(ctx, "1000", &SubscriptionMigrateRequest{
+				RequestInfo: info, Descriptors: Descriptors{Description: "d", DisplayName: "n"},
+				Items: []SubscriptionMigrateItem{{SKU: "a", Description: "d", DisplayName: "n"}}, TargetProductId: "p",
+			})
+			return err
+		}(),
+		"long display name": func() error {
+			_, err := svc.SignInAppRequest(&OneTimeChargeCreateRequest{RequestInfo: info, Currency: "USD", TaxCode: "C003-00-2",
+				Item: OneTimeChargeItem{SKU: "a", Description: "d", DisplayName: strings.Repeat("x", MaxDisplayNameLength+1), Price: 1}})
+			return err
+		}(),
+		"bad status id": func() error {
+			_, err := svc.GetRequestStatus(ctx, "x")
+			return err
+		}(),
+	}
+	for name, err := range checks {
+		if err == nil {
+			t.Errorf("%s: accepted", name)
+		}
+	}
+	if calls != 0 {
+		t.Errorf("invalid input reached the server %d times", calls)
+	}
+}
+
+func TestService_SignInAppRequest(t *testing.T) {
+	svc, key := newTestService(t, http.NotFoundHandler())
+	signed, err := svc.SignInAppRequest(&SubscriptionCreateRequest{
+		RequestInfo: RequestInfo{RequestReferenceId: testRequestID},
+		Currency:    "USD",
+		TaxCode:     "C003-00-2",
+		Period:      PeriodP1M,
+		Descriptors: Descriptors{DisplayName: "News+", Description: "All sections"},
+		Items:       []SubscriptionCreateItem{{SKU: "news.all", DisplayName: "All", Description: "Every section", Price: 9990}},
+	})
+	if err != nil {
+		t.Fatalf("SignInAppRequest: %v", err)
+	}
+
+	claims := jwt.MapClaims{}
+	token, err := jwt.ParseWithClaims(signed, claims, func(*jwt.Token) (any, error) { return &key.PublicKey, nil },
+		jwt.WithValidMethods([]string{"ES256"}), jwt.WithAudience(InAppAudience))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if token.Header["kid"] != "KID123" || claims["iss"] != "issuer-id" || claims["bid"] != "com.example.app" || claims["nonce"] == "" {
+		t.Errorf("header = %v, claims = %v", token.Header, claims)
+	}
+	raw, err := base64.StdEncoding.DecodeString(claims["request"].(string))
+	if err != nil {
+		t.Fatalf("decode request claim: %v", err)
+	}
+	var request struct {
+		Operation   Operation   `json:"operation"`
+		Version     string      `json:"version"`
+		RequestInfo RequestInfo `json:"requestInfo"`
+		Period      Period      `json:"period"`
+		Items       []struct {
+			SKU   string `json:"SKU"`
+			Price int64  `json:"price"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(raw, &request); err != nil {
+		t.Fatalf("unmarshal request claim: %v", err)
+	}
+	if request.Operation != OperationCreateSubscription || request.Version != Version || request.RequestInfo.RequestReferenceId != testRequestID ||
+		request.Period != PeriodP1M || len(request.Items) != 1 || request.Items[0].SKU != "news.all" || request.Items[0].Price != 9990 {
+		t.Errorf("request = %s", raw)
+	}
+}
@@ -0,0 +1,178 @@
+package AppStoreConnect
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/godrealms/go-apple-sdk/internal/backoff"
+)
+
+// Response headers carrying per-request metadata. Apple documents
+// X-Rate-Limit; the request ID lets Apple support trace a call.
+const (
+	headerRateLimit = "X-Rate-Limit"
+	headerRequestID = "X-Request-Id"
+)
+
+// RateLimit is the hourly request quota App Store Connect reports in
+// the X-Rate-Limit response header, e.g.
+// "user-hour-lim:3600;user-hour-rem:3545;".
+// See https://developer.apple.com/documentation/appstoreconnectapi/identifying-rate-limits
+type RateLimit struct {
+	// Limit is the number of requests allowed per rolling hour.
+	Limit int
+	// Remaining is how many of them are left.
+	Remaining int
+	// ObservedAt is when the response carrying the header arrived.
+	ObservedAt time.Time
+}
+
+// parseRateLimit parses an X-Rate-Limit header. ok is false when the
+// header is missing or carries no limit.
+func parseRateLimit(value string, now time.Time) (RateLimit, bool) {
+	rl := RateLimit{Remaining: -1, ObservedAt: now}
+	for _, part := range strings.Split(value, ";") {
+		name, raw, found := strings.Cut(strings.TrimSpace(part), ":")
+		if !found {
+			continue
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(raw))
+		if err != nil {
+			continue
+		}
+		switch strings.TrimSpace(name) {
+		case "user-hour-lim":
+			rl.Limit = n
+		case "user-hour-rem":
+			rl.Remaining = n
+		}
+	}
+	if rl.Limit <= 0 || rl.Remaining < 0 {
+		return RateLimit{}, false
+	}
+	return rl, true
+}
+
+// responseRequestID returns Apple's request ID from resp, or "".
+func responseRequestID(resp *http.Response) string {
+	if resp == nil {
+		return ""
+	}
+	return resp.Header.Get(headerRequestID)
+}
+
+// rateLimitState is the last quota a Service has seen.
+type rateLimitState struct {
+	mu   sync.Mutex
+	last RateLimit
+	seen bool
+}
+
+func (st *rateLimitState) observe(resp *http.Response) (RateLimit, bool) {
+	if resp == nil {
+		return RateLimit{}, false
+	}
+	rl, ok := parseRateLimit(resp.Header.Get(headerRateLimit), time.Now())
+	if !ok {
+		return RateLimit{}, false
+	}
+	st.mu.Lock()
+	st.last, st.seen = rl, true
+	st.mu.Unlock()
+	return rl, true
+}
+
+func (st *rateLimitState) get() (RateLimit, bool) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return st.last, st.seen
+}
+
+// RateLimit returns the quota reported by the most recent response
+// that carried an X-Rate-Limit header. ok is false until one arrives.
+func (s *Service) RateLimit() (rl RateLimit, ok bool) {
+	return s.rateLimit.get()
+}
+
+// Limiter paces requests against the hourly quota. Observe is called
+// with the quota every response reports, Wait before every request
+// attempt; Wait may block to slow the caller down, and a non-nil error
+// aborts the request. Since the quota is per API key, a Limiter keeps
+// the last quota it observed itself, so one Limiter shared by several
+// Services paces all of them.
+//
+// Implementations must be safe for concurrent use.
+type Limiter interface {
+	Observe(quota RateLimit)
+	Wait(ctx context.Context) error
+}
+
+// QuotaLimiter is a [Limiter] that lets requests through unhindered
+// while plenty of hourly quota is left, then paces them at Apple's
+// sustainable rate (one request per hour/Limit) so a long-running job
+// slows down instead of running into HTTP 429.
+//
+// A QuotaLimiter should be shared by every Service using the same API
+// key, since the quota is per key.
+type QuotaLimiter struct {
+	// Reserve is the remaining-quota level at which pacing starts.
+	// Zero means 10% of the limit.
+	Reserve int
+
+	mu    sync.Mutex
+	quota RateLimit
+	next  time.Time
+}
+
+// NewQuotaLimiter returns a [QuotaLimiter] that starts pacing when
+// fewer than reserve requests are left in the hour.
+func NewQuotaLimiter(reserve int) *QuotaLimiter {
+	return &QuotaLimiter{Reserve: reserve}
+}
+
+// Observe implements [Limiter]. A quota observed before the one already
+// held, as happens when concurrent responses race, is ignored.
+func (l *QuotaLimiter) Observe(quota RateLimit) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if quota.ObservedAt.Before(l.quota.ObservedAt) {
+		return
+	}
+	l.quota = quota
+}
+
+// Wait implements [Limiter]. It does not block until a quota has been
+// observed.
+func (l *QuotaLimiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	quota := l.quota
+	if quota.Limit <= 0 {
+		l.mu.Unlock()
+		return nil
+	}
+	reserve := l.Reserve
+	if reserve <= 0 {
+		reserve = quota.Limit / 10
+	}
+	if quota.Remaining > reserve {
+		l.mu.Unlock()
+		return nil
+	}
+	interval := time.Hour / time.Duration(quota.Limit)
+	now := time.Now()
+	at := l.next
+	if at.Before(now) {
+		at = now
+	}
+	l.next = at.Add(interval)
+	l.mu.Unlock()
+
+	if wait := at.Sub(now); wait > 0 {
+		return backoff.Sleep(ctx, wait)
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package AppStoreConnect
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestParseRateLimit(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		header string
+		want   RateLimit
+		ok     bool
+	}{
+		{"user-hour-lim:3600;user-hour-rem:3545;", RateLimit{Limit: 3600, Remaining: 3545, ObservedAt: now}, true},
+		{" user-hour-rem:0 ; user-hour-lim:500", RateLimit{Limit: 500, Remaining: 0, ObservedAt: now}, true},
+		{"user-hour-lim:3600;", RateLimit{}, false},
+		{"", RateLimit{}, false},
+		{"garbage", RateLimit{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseRateLimit(tt.header, now)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("parseRateLimit(%q) = %+v, %v; want %+v, %v", tt.header, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestRateLimit_SurfacedOnLogAndService(t *testing.T) {
+	logger := &captureLogger{}
+	svc, _ := newTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Rate-Limit", "user-hour-lim:3600;user-hour-rem:3599;")
+		w.Header().Set("X-Request-Id", "REQ-123")
+		_, _ = w.Write([]byte(`{"data":[]}`))
+	}))
+	svc.logger = logger
+
+	if _, ok := svc.RateLimit(); ok {
+		t.Fatal("quota reported before any response")
+	}
+	if _, err := svc.Apps().List(context.Background(), nil); err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	rl, ok := svc.RateLimit()
+	if !ok || rl.Limit != 3600 || rl.Remaining != 3599 || rl.ObservedAt.IsZero() {
+		t.Errorf("RateLimit() = %+v, %v", rl, ok)
+	}
+	recs := logger.snapshot()
+	if len(recs) != 1 || recs[0].RequestID != "REQ-123" || recs[0].RateLimit.Remaining != 3599 {
+		t.Errorf("records = %+v", recs)
+	}
+}
+
+func TestQuotaLimiter(t *testing.T) {
+	l := NewQuotaLimiter(10)
+	ctx := context.Background()
+
+	start := time.Now()
+	l.Observe(RateLimit{Limit: 3600, Remaining: 3000})
+	for i := 0; i < 5; i++ {
+		if err := l.Wait(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if time.Since(start) > 100*time.Millisecond {
+		t.Error("limiter paced requests with plenty of quota left")
+	}
+
+	// 36000 per hour paces at one request per 100ms.
+	l.Observe(RateLimit{Limit: 36000, Remaining: 5})
+	start = time.Now()
+	for i := 0; i < 3; i++ {
+		if err := l.Wait(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 180*time.Millisecond {
+		t.Errorf("3 paced requests took %v, want about 200ms", elapsed)
+	}
+
+	// An older quota arriving late does not replace a newer one.
+	now := time.Now()
+	l.Observe(RateLimit{Limit: 1, Remaining: 0, ObservedAt: now})
+	l.Observe(RateLimit{Limit: 3600, Remaining: 3000, ObservedAt: now.Add(-time.Second)})
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := l.Wait(cancelled); err == nil {
+		t.Error("Wait ignored a cancelled context")
+	}
+}
+
+func TestLimiter_ObservesAndWaitsForEachAttempt(t *testing.T) {
+	svc, _ := newTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Rate-Limit", "user-hour-lim:100;user-hour-rem:7;")
+		_, _ = w.Write([]byte(`{"data":[]}`))
+	}))
+	l := &recordingLimiter{}
+	svc.limiter = l
+	for i := 0; i < 2; i++ {
+		if _, err := svc.Apps().List(context.Background(), nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if l.waits != 2 || len(l.quotas) != 2 || l.quotas[1].Limit != 100 || l.quotas[1].Remaining != 7 {
+		t.Errorf("waits = %d, quotas = %+v", l.waits, l.quotas)
+	}
+}
+
+func TestQuotaLimiter_SharedAcrossServices(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 36000 per hour paces at one request per 100ms.
+		w.Header().Set("X-Rate-Limit", "user-hour-lim:36000;user-hour-rem:5;")
+		_, _ = w.Write([]byte(`{"data":[]}`))
+	})
+	limiter := NewQuotaLimiter(10)
+	first, _ := newTestService(t, handler)
+	second, _ := newTestService(t, handler)
+	first.limiter, second.limiter = limiter, limiter
+
+	if _, err := first.Apps().List(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	// second has seen no response of its own, yet is paced by the
+	// quota first observed.
+	start := time.Now()
+	for i := 0; i < 2; i++ {
+		if _, err := second.Apps().List(context.Background(), nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("2 requests on a fresh Service took %v, want about 100ms", elapsed)
+	}
+}
+
+// recordingLimiter records the quotas it observes and how often it is
+// waited on.
+type recordingLimiter struct {
+	quotas []RateLimit
+	waits  int
+}
+
+func (l *recordingLimiter) Observe(q RateLimit) { l.quotas = append(l.quotas, q) }
+
+func (l *recordingLimiter) Wait(context.Context) error {
+	l.waits++
+	return nil
+}
@@ -0,0 +1,111 @@
+package AppStoreConnect
+
+import (
+	"math/rand/v2"
+	"net/http"
+	"time"
+)
+
+// RetryPolicy controls how a [Service] retries requests that fail with
+// a transport error, HTTP 429, or a 5xx status.
+//
+// Delays grow exponentially from InitialBackoff with full jitter (a
+// random delay between zero and the exponential ceiling). When Apple
+// sends Retry-After, that delay is used instead. The Authorizer runs
+// again before every attempt, so a token that expired during the wait
+// is replaced.
+//
+// Only idempotent methods (GET, HEAD, OPTIONS, PUT, DELETE) are retried
+// unless RetryNonIdempotent is set: a POST that timed out may already
+// have created the resource.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first.
+	// Values below 2 disable retries.
+	MaxAttempts int
+	// InitialBackoff is the ceiling of the first delay. Defaults to
+	// one second when zero.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the exponential ceiling. Defaults to 30 seconds
+	// when zero. Retry-After is not capped.
+	MaxBackoff time.Duration
+	// MaxElapsedTime stops retrying once this much time has passed
+	// since the first attempt, or would have passed after the next
+	// delay. Zero means no limit.
+	MaxElapsedTime time.Duration
+	// RetryNonIdempotent also retries POST and PATCH requests.
+	RetryNonIdempotent bool
+}
+
+// DefaultRetryPolicy returns a policy suited to batch jobs: four
+// attempts, one to thirty second backoff, two minutes in total.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:    4,
+		InitialBackoff: time.Second,
+		MaxBackoff:     30 * time.Second,
+		MaxElapsedTime: 2 * time.Minute,
+	}
+}
+
+// retryStatus reports whether an HTTP status is worth retrying.
+func retryStatus(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// idempotent reports whether method may safely be repeated.
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// delay returns how long to wait before attempt number next (2 for the
+// first retry) and whether to retry at all. elapsed is the time since
+// the first attempt started; retryAfter is the server-requested delay,
+// or zero.
+func (p *RetryPolicy) delay(method string, next int, elapsed, retryAfter time.Duration) (time.Duration, bool) {
+	if p == nil || next > p.MaxAttempts {
+		return 0, false
+	}
+	if !p.RetryNonIdempotent && !idempotent(method) {
+		return 0, false
+	}
+	wait := retryAfter
+	if wait <= 0 {
+		wait = p.backoff(next - 1)
+	}
+	if p.MaxElapsedTime > 0 && elapsed+wait > p.MaxElapsedTime {
+		return 0, false
+	}
+	return wait, true
+}
+
+// backoff returns a jittered delay for the given retry (1-based).
+func (p *RetryPolicy) backoff(retry int) time.Duration {
+	initial := p.InitialBackoff
+	if initial <= 0 {
+		initial = time.Second
+	}
+	maxBackoff := p.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = 30 * time.Second
+	}
+	ceiling := initial
+	for i := 1; i < retry && ceiling < maxBackoff; i++ {
+		ceiling *= 2
+	}
+	if ceiling > maxBackoff {
+		ceiling = maxBackoff
+	}
+	return rand.N(ceiling) + 1
+}
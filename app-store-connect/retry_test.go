@@ -0,0 +1,161 @@
+package AppStoreConnect
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fastRetry retries quickly enough for unit tests.
+func fastRetry() *RetryPolicy {
+	return &RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond}
+}
+
+// newRetryService returns a service whose handler fails with status for
+// the first failures calls and then succeeds.
+func newRetryService(t *testing.T, policy *RetryPolicy, failures int32, status int, header http.Header) (*Service, *atomic.Int32, *captureLogger) {
+	t.Helper()
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= failures {
+			for k, v := range header {
+				w.Header()[k] = v
+			}
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(`{"errors":[{"status":"429","code":"RATE_LIMIT_EXCEEDED"}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":[]}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	var tokens atomic.Int32
+	logger := &captureLogger{}
+	svc := New(Config{
+		BaseURL: srv.URL,
+		Authorizer: AuthorizerFunc(func(req *http.Request) error {
+			tokens.Add(1)
+			return noopAuthorizer(req)
+		}),
+		Logger: logger,
+		Retry:  policy,
+	})
+	t.Cleanup(func() {
+		if tokens.Load() != calls.Load() {
+			t.Errorf("Authorizer ran %d times for %d attempts", tokens.Load(), calls.Load())
+		}
+	})
+	return svc, &calls, logger
+}
+
+func TestRetry_RecoversFromTransientStatus(t *testing.T) {
+	for _, status := range []int{http.StatusTooManyRequests, http.StatusServiceUnavailable} {
+		svc, calls, logger := newRetryService(t, fastRetry(), 2, status, nil)
+		if _, err := svc.do(context.Background(), http.MethodGet, "/v1/apps", nil, nil, nil); err != nil {
+			t.Fatalf("status %d: do: %v", status, err)
+		}
+		if calls.Load() != 3 {
+			t.Errorf("status %d: attempts = %d, want 3", status, calls.Load())
+		}
+		recs := logger.snapshot()
+		if len(recs) != 3 {
+			t.Fatalf("status %d: records = %d, want one per attempt", status, len(recs))
+		}
+		for i, r := range recs {
+			if r.Attempt != i+1 {
+				t.Errorf("record %d: Attempt = %d", i, r.Attempt)
+			}
+		}
+		if recs[0].StatusCode != status || recs[0].Err == nil || recs[2].Err != nil {
+			t.Errorf("records = %+v", recs)
+		}
+	}
+}
+
+func TestRetry_GivesUpAfterMaxAttempts(t *testing.T) {
+	svc, calls, _ := newRetryService(t, fastRetry(), 10, http.StatusBadGateway, nil)
+	_, err := svc.do(context.Background(), http.MethodGet, "/v1/apps", nil, nil, nil)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadGateway {
+		t.Fatalf("err = %v, want 502 APIError", err)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("attempts = %d, want 3", calls.Load())
+	}
+}
+
+func TestRetry_SkipsNonIdempotentAndClientErrors(t *testing.T) {
+	svc, calls, _ := newRetryService(t, fastRetry(), 1, http.StatusServiceUnavailable, nil)
+	if _, err := svc.do(context.Background(), http.MethodPost, "/v1/apps", nil, map[string]string{}, nil); err == nil {
+		t.Fatal("POST succeeded; it should not have been retried")
+	}
+	if calls.Load() != 1 {
+		t.Errorf("POST attempts = %d, want 1", calls.Load())
+	}
+
+	policy := fastRetry()
+	policy.RetryNonIdempotent = true
+	svc, calls, _ = newRetryService(t, policy, 1, http.StatusServiceUnavailable, nil)
+	if _, err := svc.do(context.Background(), http.MethodPost, "/v1/apps", nil, map[string]string{}, nil); err != nil {
+		t.Fatalf("POST with RetryNonIdempotent: %v", err)
+	}
+
+	svc, calls, _ = newRetryService(t, fastRetry(), 1, http.StatusNotFound, nil)
+	if _, err := svc.do(context.Background(), http.MethodGet, "/v1/apps", nil, nil, nil); err == nil {
+		t.Fatal("404 was retried")
+	}
+	if calls.Load() != 1 {
+		t.Errorf("404 attempts = %d, want 1", calls.Load())
+	}
+}
+
+func TestRetry_RespectsRetryAfterAndMaxElapsed(t *testing.T) {
+	policy := fastRetry()
+	policy.MaxElapsedTime = 500 * time.Millisecond
+	header := http.Header{"Retry-After": []string{"3"}}
+	svc, calls, _ := newRetryService(t, policy, 1, http.StatusTooManyRequests, header)
+
+	start := time.Now()
+	_, err := svc.do(context.Background(), http.MethodGet, "/v1/apps", nil, nil, nil)
+	if err == nil {
+		t.Fatal("retried past MaxElapsedTime")
+	}
+	if calls.Load() != 1 || time.Since(start) > time.Second {
+		t.Errorf("attempts = %d after %v; Retry-After beyond the budget must stop retries", calls.Load(), time.Since(start))
+	}
+
+	header = http.Header{"Retry-After": []string{"0"}}
+	svc, _, _ = newRetryService(t, fastRetry(), 1, http.StatusTooManyRequests, header)
+	if _, err := svc.do(context.Background(), http.MethodGet, "/v1/apps", nil, nil, nil); err != nil {
+		t.Errorf("Retry-After 0: %v", err)
+	}
+}
+
+func TestRetry_StopsOnContextCancel(t *testing.T) {
+	policy := &RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Hour, MaxBackoff: time.Hour}
+	svc, calls, _ := newRetryService(t, policy, 5, http.StatusServiceUnavailable, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := svc.do(ctx, http.MethodGet, "/v1/apps", nil, nil, nil); err == nil {
+		t.Fatal("expected error")
+	}
+	if calls.Load() != 1 {
+		t.Errorf("attempts = %d, want 1", calls.Load())
+	}
+}
+
+func TestRetryPolicy_BackoffBounds(t *testing.T) {
+	p := &RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	for retry := 1; retry <= 10; retry++ {
+		for i := 0; i < 50; i++ {
+			d := p.backoff(retry)
+			if d <= 0 || d > time.Second {
+				t.Fatalf("backoff(%d) = %v, out of (0, 1s]", retry, d)
+			}
+		}
+	}
+}
@@ -10,6 +10,8 @@ import (
 	"net/url"
 	"strings"
 	"time"
+
+	"github.com/godrealms/go-apple-sdk/internal/backoff"
 )
 
 // DefaultBaseURL is the production host for the App Store Connect API.
@@ -31,6 +33,14 @@ type Config struct {
 	// completed HTTP round trip. Leaving it nil silences the SDK.
 	// See [Logger] for the field semantics.
 	Logger Logger
+	// Retry, when non-nil, retries requests that fail with a transport
+	// error, 429 or 5xx. Nil makes exactly one attempt. See
+	// [DefaultRetryPolicy] for a ready-made policy.
+	Retry *RetryPolicy
+	// Limiter, when non-nil, is told every quota Apple reports and is
+	// consulted before every attempt, which it may delay. Share one
+	// across the Services using the same API key. See [QuotaLimiter].
+	Limiter Limiter
 }
 
 // Logger is the minimal structured-logging hook exposed by the SDK.
@@ -71,6 +81,16 @@ type LogRecord struct {
 	// Err is non-nil when the request failed — either a transport
 	// error or a non-2xx status parsed into an [APIError].
 	Err error
+	// Attempt is the 1-based attempt number. It is above 1 for
+	// retries made under [Config.Retry]; each attempt gets its own
+	// record.
+	Attempt int
+	// RequestID is the request ID Apple sent back, for support
+	// tickets. Empty on transport failure.
+	RequestID string
+	// RateLimit is the hourly quota reported with this response. Zero
+	// when the response carried no X-Rate-Limit header.
+	RateLimit RateLimit
 }
 
 // LoggerFunc is an adapter that lets a plain function satisfy
@@ -100,6 +120,9 @@ type Service struct {
 	authorizer Authorizer
 	userAgent  string
 	logger     Logger
+	retry      *RetryPolicy
+	limiter    Limiter
+	rateLimit  rateLimitState
 
 	apps            *AppsService
 	reports         *ReportsService
@@ -143,6 +166,8 @@ func New(cfg Config) *Service {
 		authorizer: cfg.Authorizer,
 		userAgent:  cfg.UserAgent,
 		logger:     cfg.Logger,
+		retry:      cfg.Retry,
+		limiter:    cfg.Limiter,
 	}
 	s.apps = &AppsService{svc: s}
 	s.reports = &ReportsService{svc: s}
@@ -261,10 +286,11 @@ func (s *Service) SubscriptionGroups() *SubscriptionGroupsService { return s.sub
 // BaseURL returns the service's base URL (without trailing slash).
 func (s *Service) BaseURL() string { return s.baseURL }
 
-// logRequest emits a [LogRecord] describing a completed round trip.
-// It is a no-op when no [Logger] is configured so the hot path costs
-// one nil check per request. The parameter is named reqURL rather
-// than url to avoid shadowing the imported net/url package.
+// logRequest emits a [LogRecord] for a request that failed before it
+// reached Apple. It is a no-op when no [Logger] is configured so the
+// hot path costs one nil check per request. The parameter is named
+// reqURL rather than url to avoid shadowing the imported net/url
+// package.
 func (s *Service) logRequest(method, reqURL string, statusCode int, start time.Time, err error) {
 	if s.logger == nil {
 		return
@@ -275,9 +301,40 @@ func (s *Service) logRequest(method, reqURL string, statusCode int, start time.T
 		StatusCode: statusCode,
 		Duration:   time.Since(start),
 		Err:        err,
+		Attempt:    1,
 	})
 }
 
+// logExchange emits a [LogRecord] for one attempt, including the
+// metadata Apple returned in the response headers.
+func (s *Service) logExchange(method, reqURL string, ex *exchange, err error) {
+	if s.logger == nil {
+		return
+	}
+	record := LogRecord{
+		Method:    method,
+		URL:       reqURL,
+		Duration:  time.Since(ex.start),
+		Err:       err,
+		Attempt:   ex.attempt,
+		RequestID: responseRequestID(ex.resp),
+		RateLimit: ex.rateLimit,
+	}
+	if ex.resp != nil {
+		record.StatusCode = ex.resp.StatusCode
+	}
+	s.logger.Log(record)
+}
+
+// exchange is the outcome of the last attempt made by [Service.send].
+type exchange struct {
+	resp      *http.Response
+	body      []byte
+	attempt   int
+	start     time.Time
+	rateLimit RateLimit
+}
+
 // do performs an HTTP request and decodes a JSON response into out.
 // If the server returns a non-2xx status, do returns an [*APIError] parsed
 // from the response body.
@@ -294,66 +351,117 @@ func (s *Service) do(ctx context.Context, method, path string, query *Query, bod
 		return nil, wrapped
 	}
 
-	var bodyReader io.Reader
+	var payload []byte
 	if body != nil {
-		buf, err := json.Marshal(body)
+		payload, err = json.Marshal(body)
 		if err != nil {
 			wrapped := &ClientError{Message: "marshal request body", Cause: err}
 			s.logRequest(method, reqURL, 0, start, wrapped)
 			return nil, wrapped
 		}
-		bodyReader = bytes.NewReader(buf)
 	}
 
+	ex, err := s.send(ctx, method, reqURL, payload, "application/json")
+	if err != nil {
+		return ex.resp, err
+	}
+	if out != nil && len(ex.body) > 0 {
+		if err := json.Unmarshal(ex.body, out); err != nil {
+			wrapped := &ClientError{Message: "decode response body", Cause: err}
+			s.logExchange(method, reqURL, ex, wrapped)
+			return ex.resp, wrapped
+		}
+	}
+	s.logExchange(method, reqURL, ex, nil)
+	return ex.resp, nil
+}
+
+// send runs the request, retrying under the service's [RetryPolicy],
+// and returns the last attempt. Every failed attempt is logged; a
+// successful one is left for the caller to log once it has processed
+// the body. payload, when non-nil, is sent as a JSON body.
+func (s *Service) send(ctx context.Context, method, reqURL string, payload []byte, accept string) (*exchange, error) {
+	first := time.Now()
+	for attempt := 1; ; attempt++ {
+		if s.limiter != nil {
+			if err := s.limiter.Wait(ctx); err != nil {
+				ex := &exchange{attempt: attempt, start: time.Now()}
+				wrapped := &ClientError{Message: "wait for rate limiter", Cause: err}
+				s.logExchange(method, reqURL, ex, wrapped)
+				return ex, wrapped
+			}
+		}
+		ex := &exchange{attempt: attempt, start: time.Now()}
+		var (
+			err       error
+			transient bool
+		)
+		ex.resp, ex.body, transient, err = s.attempt(ctx, method, reqURL, payload, accept)
+		if rl, ok := s.rateLimit.observe(ex.resp); ok {
+			ex.rateLimit = rl
+			if s.limiter != nil {
+				s.limiter.Observe(rl)
+			}
+		}
+		if err == nil {
+			if ex.resp.StatusCode >= 200 && ex.resp.StatusCode < 300 {
+				return ex, nil
+			}
+			err = parseErrorBody(ex.resp.StatusCode, ex.body)
+			transient = retryStatus(ex.resp.StatusCode)
+		}
+
+		var retryAfter time.Duration
+		if ex.resp != nil {
+			retryAfter = backoff.ParseRetryAfter(ex.resp.Header.Get("Retry-After"), time.Now())
+		}
+		s.logExchange(method, reqURL, ex, err)
+		if !transient || ctx.Err() != nil {
+			return ex, err
+		}
+		wait, ok := s.retry.delay(method, attempt+1, time.Since(first), retryAfter)
+		if !ok {
+			return ex, err
+		}
+		if sleepErr := backoff.Sleep(ctx, wait); sleepErr != nil {
+			return ex, err
+		}
+	}
+}
+
+// attempt makes a single round trip and reads the whole response body.
+// transient reports whether a returned error is worth retrying.
+func (s *Service) attempt(ctx context.Context, method, reqURL string, payload []byte, accept string) (resp *http.Response, body []byte, transient bool, err error) {
+	var bodyReader io.Reader
+	if payload != nil {
+		bodyReader = bytes.NewReader(payload)
+	}
 	req, err := http.NewRequestWithContext(ctx, method, reqURL, bodyReader)
 	if err != nil {
-		wrapped := &ClientError{Message: "build request", Cause: err}
-		s.logRequest(method, reqURL, 0, start, wrapped)
-		return nil, wrapped
+		return nil, nil, false, &ClientError{Message: "build request", Cause: err}
 	}
-	req.Header.Set("Accept", "application/json")
-	if body != nil {
+	req.Header.Set("Accept", accept)
+	if payload != nil {
 		req.Header.Set("Content-Type", "application/json")
 	}
 	if s.userAgent != "" {
 		req.Header.Set("User-Agent", s.userAgent)
 	}
 	if err := s.authorizer.Authorize(req); err != nil {
-		wrapped := &ClientError{Message: "authorize request", Cause: err}
-		s.logRequest(method, reqURL, 0, start, wrapped)
-		return nil, wrapped
+		return nil, nil, false, &ClientError{Message: "authorize request", Cause: err}
 	}
 
-	resp, err := s.httpClient.Do(req)
+	resp, err = s.httpClient.Do(req)
 	if err != nil {
-		wrapped := &ClientError{Message: "execute request", Cause: err}
-		s.logRequest(method, reqURL, 0, start, wrapped)
-		return nil, wrapped
+		return nil, nil, true, &ClientError{Message: "execute request", Cause: err}
 	}
 	defer resp.Body.Close()
 
-	respBody, err := io.ReadAll(resp.Body)
+	body, err = io.ReadAll(resp.Body)
 	if err != nil {
-		wrapped := &ClientError{Message: "read response body", Cause: err}
-		s.logRequest(method, reqURL, resp.StatusCode, start, wrapped)
-		return resp, wrapped
-	}
-
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		apiErr := parseErrorBody(resp.StatusCode, respBody)
-		s.logRequest(method, reqURL, resp.StatusCode, start, apiErr)
-		return resp, apiErr
+		return resp, nil, true, &ClientError{Message: "read response body", Cause: err}
 	}
-
-	if out != nil && len(respBody) > 0 {
-		if err := json.Unmarshal(respBody, out); err != nil {
-			wrapped := &ClientError{Message: "decode response body", Cause: err}
-			s.logRequest(method, reqURL, resp.StatusCode, start, wrapped)
-			return resp, wrapped
-		}
-	}
-	s.logRequest(method, reqURL, resp.StatusCode, start, nil)
-	return resp, nil
+	return resp, body, false, nil
 }
 
 // doRaw performs an HTTP request and returns the raw (optionally
@@ -380,44 +488,13 @@ func (s *Service) doRaw(ctx context.Context, method, path string, query *Query)
 		return nil, nil, wrapped
 	}
 
-	req, err := http.NewRequestWithContext(ctx, method, reqURL, nil)
-	if err != nil {
-		wrapped := &ClientError{Message: "build request", Cause: err}
-		s.logRequest(method, reqURL, 0, start, wrapped)
-		return nil, nil, wrapped
-	}
 	// Apple's report endpoints require this Accept header to serve
 	// the gzipped TSV payload.
-	req.Header.Set("Accept", "application/a-gzip, application/json")
-	if s.userAgent != "" {
-		req.Header.Set("User-Agent", s.userAgent)
-	}
-	if err := s.authorizer.Authorize(req); err != nil {
-		wrapped := &ClientError{Message: "authorize request", Cause: err}
-		s.logRequest(method, reqURL, 0, start, wrapped)
-		return nil, nil, wrapped
-	}
-
-	resp, err := s.httpClient.Do(req)
+	ex, err := s.send(ctx, method, reqURL, nil, "application/a-gzip, application/json")
 	if err != nil {
-		wrapped := &ClientError{Message: "execute request", Cause: err}
-		s.logRequest(method, reqURL, 0, start, wrapped)
-		return nil, nil, wrapped
-	}
-	defer resp.Body.Close()
-
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		wrapped := &ClientError{Message: "read response body", Cause: err}
-		s.logRequest(method, reqURL, resp.StatusCode, start, wrapped)
-		return resp, nil, wrapped
-	}
-
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		apiErr := parseErrorBody(resp.StatusCode, respBody)
-		s.logRequest(method, reqURL, resp.StatusCode, start, apiErr)
-		return resp, nil, apiErr
+		return ex.resp, nil, err
 	}
+	resp, respBody := ex.resp, ex.body
 
 	// Apple reports ship the gzip as the payload itself — not as
 	// transport encoding — so http.Client will not decode it. Detect
@@ -428,19 +505,19 @@ func (s *Service) doRaw(ctx context.Context, method, path string, query *Query)
 		zr, err := gzip.NewReader(bytes.NewReader(respBody))
 		if err != nil {
 			wrapped := &ClientError{Message: "open gzip stream", Cause: err}
-			s.logRequest(method, reqURL, resp.StatusCode, start, wrapped)
+			s.logExchange(method, reqURL, ex, wrapped)
 			return resp, nil, wrapped
 		}
 		defer zr.Close()
 		decoded, err := io.ReadAll(zr)
 		if err != nil {
 			wrapped := &ClientError{Message: "decompress gzip stream", Cause: err}
-			s.logRequest(method, reqURL, resp.StatusCode, start, wrapped)
+			s.logExchange(method, reqURL, ex, wrapped)
 			return resp, nil, wrapped
 		}
 		respBody = decoded
 	}
-	s.logRequest(method, reqURL, resp.StatusCode, start, nil)
+	s.logExchange(method, reqURL, ex, nil)
 	return resp, respBody, nil
 }
 
@@ -0,0 +1,53 @@
+package AppStoreServer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	Apple "github.com/godrealms/go-apple-sdk"
+	"github.com/godrealms/go-apple-sdk/types"
+)
+
+// UpdateAppAccountTokenRequest The request body that contains an app account token value.
+type UpdateAppAccountTokenRequest struct {
+	// The UUID that an app optionally generates to map a customer’s in-app purchase with its resulting App Store transaction.
+	AppAccountToken types.UUID `json:"appAccountToken"`
+}
+
+// SetAppAccountToken Sets the app account token value for a purchase the customer makes outside your app,
+// or updates its value in an existing transaction.
+//
+// The token is checked locally first. Besides [*APIError], failures
+// match these sentinels under errors.Is:
+//   - [ErrInvalidAppAccountToken]: appAccountToken is not a UUID.
+//   - [ErrFamilySharedTransaction]: the transaction is family-shared.
+//   - [ErrNotOriginalTransactionId]: the ID is not an original transaction ID.
+//   - [ErrTransactionNotFound]: Apple does not know the transaction.
+func (s *Service) SetAppAccountToken(ctx context.Context, originalTransactionId string, appAccountToken types.UUID) error {
+	if originalTransactionId == "" {
+		return errors.New("app store server: set app account token: originalTransactionId is required")
+	}
+	if !appAccountToken.IsValidUUID() {
+		return fmt.Errorf("%w: %q", ErrInvalidAppAccountToken, appAccountToken)
+	}
+	params := Apple.RequestParams{
+		Ctx:    ctx,
+		Method: "PUT",
+		Path:   "/inApps/v1/transactions/{originalTransactionId}/appAccountToken",
+		Body:   &UpdateAppAccountTokenRequest{AppAccountToken: appAccountToken},
+		Headers: map[string]string{
+			"Content-Type": "application/json",
+		},
+		PathParams: map[string]string{
+			"originalTransactionId": originalTransactionId,
+		},
+	}
+	return s.request(params)
+}
+
+// SetAppAccountToken calls [Service.SetAppAccountToken]
+// on a Service for client's environment.
+func SetAppAccountToken(ctx context.Context, client *Apple.Client, originalTransactionId string, appAccountToken types.UUID) error {
+	return NewService(client).SetAppAccountToken(ctx, originalTransactionId, appAccountToken)
+}
@@ -0,0 +1,68 @@
+package AppStoreServer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+)
+
+func TestService_SetAppAccountToken(t *testing.T) {
+	const token = "7389a31a-fb6d-4569-a2a6-db7d85d84813"
+	svc, _ := newTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.URL.Path != "/inApps/v1/transactions/1000/appAccountToken" {
+			t.Errorf("request = %s %s", r.Method, r.URL.Path)
+		}
+		var body UpdateAppAccountTokenRequest
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.AppAccountToken != token {
+			t.Errorf("body = %+v, err %v", body, err)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	if err := svc.SetAppAccountToken(context.Background(), "1000", token); err != nil {
+		t.Fatalf("SetAppAccountToken: %v", err)
+	}
+}
+
+func TestService_SetAppAccountToken_Errors(t *testing.T) {
+	var calls atomic.Int32
+	var code atomic.Int64
+	svc, _ := newTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		status := http.StatusBadRequest
+		if ErrorCode(code.Load()) == ErrorCodeTransactionIdNotFound {
+			status = http.StatusNotFound
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		fmt.Fprintf(w, `{"errorCode":%d,"errorMessage":"x"}`, code.Load())
+	}))
+	ctx := context.Background()
+
+	if err := svc.SetAppAccountToken(ctx, "1000", "not-a-uuid"); !errors.Is(err, ErrInvalidAppAccountToken) {
+		t.Errorf("local validation: err = %v", err)
+	}
+	if err := svc.SetAppAccountToken(ctx, "", "7389a31a-fb6d-4569-a2a6-db7d85d84813"); err == nil {
+		t.Error("empty originalTransactionId accepted")
+	}
+	if calls.Load() != 0 {
+		t.Fatalf("invalid input reached the server %d times", calls.Load())
+	}
+
+	for c, want := range map[ErrorCode]error{
+		ErrorCodeInvalidAppAccountTokenUUID:            ErrInvalidAppAccountToken,
+		ErrorCodeFamilyTransactionNotSupported:         ErrFamilySharedTransaction,
+		ErrorCodeTransactionIdNotOriginalTransactionId: ErrNotOriginalTransactionId,
+		ErrorCodeTransactionIdNotFound:                 ErrTransactionNotFound,
+	} {
+		code.Store(int64(c))
+		err := svc.SetAppAccountToken(ctx, "1000", "7389a31a-fb6d-4569-a2a6-db7d85d84813")
+		var apiErr *APIError
+		if !errors.Is(err, want) || !errors.As(err, &apiErr) || apiErr.ErrorCode != c {
+			t.Errorf("code %d: err = %v, want %v", c, err, want)
+		}
+	}
+}
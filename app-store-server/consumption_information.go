@@ -112,8 +112,7 @@ type ConsumptionRequest struct {
 // is set. The previous code passed `Result: &result` where result
 // was a *string — which would silently corrupt any future error
 // responses Apple started returning.
-func SendConsumptionInformation(ctx context.Context, client *Apple.Client, transactionId string, body *ConsumptionRequest) error {
-	client.SetService(Apple.AppStoreServerClient)
+func (s *Service) SendConsumptionInformation(ctx context.Context, transactionId string, body *ConsumptionRequest) error {
 	params := Apple.RequestParams{
 		Ctx:    ctx,
 		Method: "PUT",
@@ -127,8 +126,14 @@ func SendConsumptionInformation(ctx context.Context, client *Apple.Client, trans
 		Body: body,
 	}
 
-	if err := client.Request(params); err != nil {
+	if err := s.request(params); err != nil {
 		return err
 	}
 	return nil
 }
+
+// SendConsumptionInformation calls [Service.SendConsumptionInformation]
+// on a Service for client's environment.
+func SendConsumptionInformation(ctx context.Context, client *Apple.Client, transactionId string, body *ConsumptionRequest) error {
+	return NewService(client).SendConsumptionInformation(ctx, transactionId, body)
+}
@@ -0,0 +1,93 @@
+package AppStoreServer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	Apple "github.com/godrealms/go-apple-sdk"
+	"github.com/godrealms/go-apple-sdk/types"
+)
+
+// ConsumptionRequestV2 The request body for the version 2 Send Consumption Information endpoint.
+// It replaces the integer-coded fields of [ConsumptionRequest] with a smaller, string-typed set.
+type ConsumptionRequestV2 struct {
+	// (Required) A Boolean value that indicates whether the customer consented to provide consumption data.
+	// The App Store server rejects requests where this is not true.
+	CustomerConsented types.CustomerConsented `json:"customerConsented"`
+
+	// The percentage, in milliunits (0–100000), of the in-app purchase the customer consumed.
+	// Nil leaves the field out.
+	ConsumptionPercentage *types.ConsumptionPercentage `json:"consumptionPercentage,omitempty"`
+
+	// (Required) A value that indicates whether the app successfully delivered an in-app purchase that works properly.
+	DeliveryStatus types.DeliveryStatusV2 `json:"deliveryStatus"`
+
+	// Your preferred outcome for the refund request. Empty leaves the field out.
+	RefundPreference types.RefundPreferenceV2 `json:"refundPreference,omitempty"`
+
+	// (Required) A Boolean value that indicates whether you provided, prior to its purchase,
+	// a free sample or trial of the content, or information about its functionality.
+	SampleContentProvided types.SampleContentProvided `json:"sampleContentProvided"`
+}
+
+// Validate checks required fields and value ranges, so a request
+// Apple would reject with HTTP 400 fails before it is sent.
+func (r *ConsumptionRequestV2) Validate() error {
+	if r == nil {
+		return errors.New("app store server: consumption information: request is nil")
+	}
+	if !r.CustomerConsented {
+		return errors.New("app store server: consumption information: customerConsented must be true")
+	}
+	if p := r.ConsumptionPercentage; p != nil && (*p < 0 || *p > types.MaxConsumptionPercentage) {
+		return fmt.Errorf("app store server: consumption information: consumptionPercentage %d outside 0–%d", *p, types.MaxConsumptionPercentage)
+	}
+	switch r.DeliveryStatus {
+	case types.DELIVERY_STATUS_DELIVERED,
+		types.DELIVERY_STATUS_UNDELIVERED_QUALITY_ISSUE,
+		types.DELIVERY_STATUS_UNDELIVERED_WRONG_ITEM,
+		types.DELIVERY_STATUS_UNDELIVERED_SERVER_OUTAGE,
+		types.DELIVERY_STATUS_UNDELIVERED_OTHER:
+	case "":
+		return errors.New("app store server: consumption information: deliveryStatus is required")
+	default:
+		return fmt.Errorf("app store server: consumption information: unknown deliveryStatus %q", r.DeliveryStatus)
+	}
+	switch r.RefundPreference {
+	case "", types.REFUND_PREFERENCE_DECLINE, types.REFUND_PREFERENCE_GRANT_FULL, types.REFUND_PREFERENCE_GRANT_PRORATED:
+	default:
+		return fmt.Errorf("app store server: consumption information: unknown refundPreference %q", r.RefundPreference)
+	}
+	return nil
+}
+
+// SendConsumptionInformationV2
+// Send consumption information about an in-app purchase to the App Store
+// after your server receives a consumption request notification, using the version 2 request body.
+//
+// The body is validated first; Apple returns 202 Accepted with no body on success.
+func (s *Service) SendConsumptionInformationV2(ctx context.Context, transactionId string, body *ConsumptionRequestV2) error {
+	if err := body.Validate(); err != nil {
+		return err
+	}
+	params := Apple.RequestParams{
+		Ctx:    ctx,
+		Method: "PUT",
+		Path:   "/inApps/v2/transactions/consumption/{transactionId}",
+		Headers: map[string]string{
+			"Accept": "application/json",
+		},
+		PathParams: map[string]string{
+			"transactionId": transactionId,
+		},
+		Body: body,
+	}
+	return s.request(params)
+}
+
+// SendConsumptionInformationV2 calls [Service.SendConsumptionInformationV2]
+// on a Service for client's environment.
+func SendConsumptionInformationV2(ctx context.Context, client *Apple.Client, transactionId string, body *ConsumptionRequestV2) error {
+	return NewService(client).SendConsumptionInformationV2(ctx, transactionId, body)
+}
@@ -0,0 +1,77 @@
+package AppStoreServer
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/godrealms/go-apple-sdk/types"
+)
+
+func percentage(p types.ConsumptionPercentage) *types.ConsumptionPercentage { return &p }
+
+func TestConsumptionRequestV2_Validate(t *testing.T) {
+	valid := ConsumptionRequestV2{CustomerConsented: true, DeliveryStatus: types.DELIVERY_STATUS_DELIVERED}
+	tests := []struct {
+		name   string
+		mutate func(r *ConsumptionRequestV2)
+		ok     bool
+	}{
+		{"minimal", func(r *ConsumptionRequestV2) {}, true},
+		{"full", func(r *ConsumptionRequestV2) {
+			r.ConsumptionPercentage = percentage(types.MaxConsumptionPercentage)
+			r.RefundPreference = types.REFUND_PREFERENCE_GRANT_PRORATED
+		}, true},
+		{"zero percent", func(r *ConsumptionRequestV2) { r.ConsumptionPercentage = percentage(0) }, true},
+		{"no consent", func(r *ConsumptionRequestV2) { r.CustomerConsented = false }, false},
+		{"percent too high", func(r *ConsumptionRequestV2) { r.ConsumptionPercentage = percentage(100001) }, false},
+		{"negative percent", func(r *ConsumptionRequestV2) { r.ConsumptionPercentage = percentage(-1) }, false},
+		{"missing delivery", func(r *ConsumptionRequestV2) { r.DeliveryStatus = "" }, false},
+		{"unknown delivery", func(r *ConsumptionRequestV2) { r.DeliveryStatus = "LOST" }, false},
+		{"unknown preference", func(r *ConsumptionRequestV2) { r.RefundPreference = "MAYBE" }, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := valid
+			tt.mutate(&r)
+			if err := r.Validate(); (err == nil) != tt.ok {
+				t.Errorf("Validate() = %v, want ok=%v", err, tt.ok)
+			}
+		})
+	}
+}
+
+func TestService_SendConsumptionInformationV2(t *testing.T) {
+	var calls atomic.Int32
+	var body map[string]any
+	svc, _ := newTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if r.Method != http.MethodPut || r.URL.Path != "/inApps/v2/transactions/consumption/1000" {
+			t.Errorf("request = %s %s", r.Method, r.URL.Path)
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	ctx := context.Background()
+
+	err := svc.SendConsumptionInformationV2(ctx, "1000", &ConsumptionRequestV2{
+		CustomerConsented: true,
+		DeliveryStatus:    types.DELIVERY_STATUS_UNDELIVERED_SERVER_OUTAGE,
+	})
+	if err != nil {
+		t.Fatalf("SendConsumptionInformationV2: %v", err)
+	}
+	want := map[string]any{"customerConsented": true, "deliveryStatus": "UNDELIVERED_SERVER_OUTAGE", "sampleContentProvided": false}
+	if len(body) != len(want) || body["deliveryStatus"] != want["deliveryStatus"] || body["sampleContentProvided"] != false {
+		t.Errorf("body = %v, want %v", body, want)
+	}
+
+	if err := svc.SendConsumptionInformationV2(ctx, "1000", &ConsumptionRequestV2{}); err == nil {
+		t.Error("invalid request accepted")
+	}
+	if calls.Load() != 1 {
+		t.Errorf("server calls = %d, want 1", calls.Load())
+	}
+}
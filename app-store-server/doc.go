@@ -5,19 +5,25 @@
 // api.storekit.itunes.apple.com (production) and
 // api.storekit-sandbox.itunes.apple.com (sandbox): transaction
 // lookup, transaction history, refund history, subscription status,
-// consumption reporting, mass renewal extension, and triggering
-// test notifications. Each operation is a top-level function that
-// takes a *Apple.Client and a context.Context.
-//
-// Example:
+// consumption reporting, mass renewal extension, triggering test
+// notifications, retention messaging and External Purchase Server API
+// reports. Each operation is a method on *Service, which
+// is built once from a *Apple.Client and is safe to share between
+// goroutines:
 //
 //	client := Apple.NewClient(true, kid, iss, bid, privateKey)
-//	info, err := AppStoreServer.GetTransactionInfo(ctx, client, txID)
+//	svc := AppStoreServer.NewService(client)
+//	info, err := svc.GetTransactionInfo(ctx, txID)
 //	if err != nil {
 //	    return err
 //	}
 //	tx, err := info.SignedTransactionInfo.Decrypt()
 //
+// Every endpoint method also has a top-level function form taking the
+// *Apple.Client (AppStoreServer.GetTransactionInfo(ctx, client, txID)).
+// Those build a throwaway Service per call; they never modify the
+// client, but long-running code should keep a Service around.
+//
 // Decrypt() and friends now perform full RFC 5280 chain validation
 // against the embedded Apple Root CA G3 — see the jws/ package for
 // details and for the *jws.Verifier API used to override the trust
@@ -0,0 +1,222 @@
+package AppStoreServer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	Apple "github.com/godrealms/go-apple-sdk"
+	"github.com/godrealms/go-apple-sdk/internal/backoff"
+)
+
+// ErrorCode is the numeric errorCode Apple returns in the body of a
+// failed App Store Server API request.
+// See https://developer.apple.com/documentation/appstoreserverapi/error_codes
+type ErrorCode int64
+
+// Error codes documented by Apple. The "Retryable" variants tell the
+// caller that the same request may succeed if it is repeated later.
+const (
+	ErrorCodeGeneralBadRequest                           ErrorCode = 4000000
+	ErrorCodeInvalidAppIdentifier                        ErrorCode = 4000002
+	ErrorCodeInvalidRequestRevision                      ErrorCode = 4000005
+	ErrorCodeInvalidTransactionId                        ErrorCode = 4000006
+	ErrorCodeInvalidOriginalTransactionId                ErrorCode = 4000008
+	ErrorCodeInvalidExtendByDays                         ErrorCode = 4000009
+	ErrorCodeInvalidExtendReasonCode                     ErrorCode = 4000010
+	ErrorCodeInvalidRequestIdentifier                    ErrorCode = 4000011
+	ErrorCodeStartDateTooFarInPast                       ErrorCode = 4000012
+	ErrorCodeStartDateAfterEndDate                       ErrorCode = 4000013
+	ErrorCodeInvalidPaginationToken                      ErrorCode = 4000014
+	ErrorCodeInvalidStartDate                            ErrorCode = 4000015
+	ErrorCodeInvalidEndDate                              ErrorCode = 4000016
+	ErrorCodePaginationTokenExpired                      ErrorCode = 4000017
+	ErrorCodeInvalidNotificationType                     ErrorCode = 4000018
+	ErrorCodeMultipleFiltersSupplied                     ErrorCode = 4000019
+	ErrorCodeInvalidTestNotificationToken                ErrorCode = 4000020
+	ErrorCodeInvalidSort                                 ErrorCode = 4000021
+	ErrorCodeInvalidProductType                          ErrorCode = 4000022
+	ErrorCodeInvalidProductId                            ErrorCode = 4000023
+	ErrorCodeInvalidSubscriptionGroupIdentifier          ErrorCode = 4000024
+	ErrorCodeInvalidInAppOwnershipType                   ErrorCode = 4000026
+	ErrorCodeInvalidEmptyStorefrontCountryCodeList       ErrorCode = 4000027
+	ErrorCodeInvalidStorefrontCountryCode                ErrorCode = 4000028
+	ErrorCodeInvalidRevoked                              ErrorCode = 4000030
+	ErrorCodeInvalidStatus                               ErrorCode = 4000031
+	ErrorCodeInvalidAccountTenure                        ErrorCode = 4000032
+	ErrorCodeInvalidAppAccountToken                      ErrorCode = 4000033
+	ErrorCodeInvalidConsumptionStatus                    ErrorCode = 4000034
+	ErrorCodeInvalidCustomerConsented                    ErrorCode = 4000035
+	ErrorCodeInvalidDeliveryStatus                       ErrorCode = 4000036
+	ErrorCodeInvalidLifetimeDollarsPurchased             ErrorCode = 4000037
+	ErrorCodeInvalidLifetimeDollarsRefunded              ErrorCode = 4000038
+	ErrorCodeInvalidPlatform                             ErrorCode = 4000039
+	ErrorCodeInvalidPlayTime                             ErrorCode = 4000040
+	ErrorCodeInvalidSampleContentProvided                ErrorCode = 4000041
+	ErrorCodeInvalidUserStatus                           ErrorCode = 4000042
+	ErrorCodeInvalidTransactionTypeNotSupported          ErrorCode = 4000047
+	ErrorCodeAppTransactionIdNotSupported                ErrorCode = 4000048
+	ErrorCodeInvalidAppAccountTokenUUID                  ErrorCode = 4000183
+	ErrorCodeFamilyTransactionNotSupported               ErrorCode = 4000185
+	ErrorCodeTransactionIdNotOriginalTransactionId       ErrorCode = 4000187
+	ErrorCodeSubscriptionExtensionIneligible             ErrorCode = 4030004
+	ErrorCodeSubscriptionMaxExtension                    ErrorCode = 4030005
+	ErrorCodeFamilySharedSubscriptionExtensionIneligible ErrorCode = 4030007
+	ErrorCodeAccountNotFound                             ErrorCode = 4040001
+	ErrorCodeAccountNotFoundRetryable                    ErrorCode = 4040002
+	ErrorCodeAppNotFound                                 ErrorCode = 4040003
+	ErrorCodeAppNotFoundRetryable                        ErrorCode = 4040004
+	ErrorCodeOriginalTransactionIdNotFound               ErrorCode = 4040005
+	ErrorCodeOriginalTransactionIdNotFoundRetryable      ErrorCode = 4040006
+	ErrorCodeServerNotificationURLNotFound               ErrorCode = 4040007
+	ErrorCodeTestNotificationNotFound                    ErrorCode = 4040008
+	ErrorCodeStatusRequestNotFound                       ErrorCode = 4040009
+	ErrorCodeTransactionIdNotFound                       ErrorCode = 4040010
+	ErrorCodeAppTransactionDoesNotExist                  ErrorCode = 4040019
+	ErrorCodeRateLimitExceeded                           ErrorCode = 4290000
+	ErrorCodeGeneralInternal                             ErrorCode = 5000000
+	ErrorCodeGeneralInternalRetryable                    ErrorCode = 5000001
+)
+
+// Sentinel errors for the failures callers most often branch on.
+// An [*APIError] matches a sentinel under errors.Is when its
+// ErrorCode is one of the codes the sentinel stands for:
+//
+//	if errors.Is(err, AppStoreServer.ErrTransactionNotFound) { ... }
+var (
+	ErrTransactionNotFound         = errors.New("app store server: transaction not found")
+	ErrOriginalTransactionNotFound = errors.New("app store server: original transaction not found")
+	ErrInvalidTransactionId        = errors.New("app store server: invalid transaction id")
+	ErrAccountNotFound             = errors.New("app store server: account not found")
+	ErrAppNotFound                 = errors.New("app store server: app not found")
+	ErrRateLimitExceeded           = errors.New("app store server: rate limit exceeded")
+	ErrInternal                    = errors.New("app store server: internal error")
+	ErrInvalidAppAccountToken      = errors.New("app store server: invalid app account token")
+	ErrFamilySharedTransaction     = errors.New("app store server: family-shared transaction not supported")
+	ErrNotOriginalTransactionId    = errors.New("app store server: transaction id is not an original transaction id")
+	ErrAppTransactionNotFound      = errors.New("app store server: app transaction does not exist")
+)
+
+// sentinels maps each error code to the sentinel it matches.
+var sentinels = map[ErrorCode]error{
+	ErrorCodeTransactionIdNotFound:                  ErrTransactionNotFound,
+	ErrorCodeOriginalTransactionIdNotFound:          ErrOriginalTransactionNotFound,
+	ErrorCodeOriginalTransactionIdNotFoundRetryable: ErrOriginalTransactionNotFound,
+	ErrorCodeInvalidTransactionId:                   ErrInvalidTransactionId,
+	ErrorCodeAccountNotFound:                        ErrAccountNotFound,
+	ErrorCodeAccountNotFoundRetryable:               ErrAccountNotFound,
+	ErrorCodeAppNotFound:                            ErrAppNotFound,
+	ErrorCodeAppNotFoundRetryable:                   ErrAppNotFound,
+	ErrorCodeRateLimitExceeded:                      ErrRateLimitExceeded,
+	ErrorCodeGeneralInternal:                        ErrInternal,
+	ErrorCodeGeneralInternalRetryable:               ErrInternal,
+	ErrorCodeInvalidAppAccountToken:                 ErrInvalidAppAccountToken,
+	ErrorCodeInvalidAppAccountTokenUUID:             ErrInvalidAppAccountToken,
+	ErrorCodeFamilyTransactionNotSupported:          ErrFamilySharedTransaction,
+	ErrorCodeTransactionIdNotOriginalTransactionId:  ErrNotOriginalTransactionId,
+	ErrorCodeAppTransactionDoesNotExist:             ErrAppTransactionNotFound,
+}
+
+// APIError is returned when the App Store Server API responds with a
+// non-2xx status.
+//
+// Use errors.As to extract it, or errors.Is with one of the sentinel
+// errors above:
+//
+//	var apiErr *AppStoreServer.APIError
+//	if errors.As(err, &apiErr) && apiErr.IsRetryable() {
+//	    time.Sleep(apiErr.RetryAfter)
+//	}
+type APIError struct {
+	// StatusCode is the HTTP status code returned by Apple.
+	StatusCode int
+	// ErrorCode is Apple's numeric error code. 0 when the body did not
+	// carry one (e.g. a bare 401 from an invalid JWT).
+	ErrorCode ErrorCode
+	// ErrorMessage is Apple's human-readable description.
+	ErrorMessage string
+	// RetryAfter is the delay Apple asked for in the Retry-After
+	// header. 0 when the header was absent or unparsable.
+	RetryAfter time.Duration
+	// RawBody is the raw response body, for diagnostics.
+	RawBody []byte
+}
+
+// Error implements the error interface.
+func (e *APIError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "app store server: HTTP %d", e.StatusCode)
+	switch {
+	case e.ErrorCode != 0 || e.ErrorMessage != "":
+		b.WriteString(": ")
+		if e.ErrorCode != 0 {
+			fmt.Fprintf(&b, "[%d] ", e.ErrorCode)
+		}
+		b.WriteString(e.ErrorMessage)
+	case len(e.RawBody) > 0:
+		fmt.Fprintf(&b, ": %s", strings.TrimSpace(string(e.RawBody)))
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// Is reports whether target is the sentinel error for e's ErrorCode.
+func (e *APIError) Is(target error) bool {
+	sentinel, ok := sentinels[e.ErrorCode]
+	return ok && sentinel == target
+}
+
+// IsRetryable reports whether repeating the same request later may
+// succeed: rate limiting, server-side failures, and the error codes
+// Apple explicitly marks as retryable.
+func (e *APIError) IsRetryable() bool {
+	switch e.ErrorCode {
+	case ErrorCodeRateLimitExceeded,
+		ErrorCodeGeneralInternalRetryable,
+		ErrorCodeAccountNotFoundRetryable,
+		ErrorCodeAppNotFoundRetryable,
+		ErrorCodeOriginalTransactionIdNotFoundRetryable:
+		return true
+	case ErrorCodeGeneralInternal:
+		// Apple documents this one as "don't retry".
+		return false
+	}
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+// parseAPIError decodes Apple's {"errorCode": n, "errorMessage": "…"}
+// body. If decoding fails, the APIError carries only StatusCode and
+// RawBody.
+func parseAPIError(httpErr *Apple.HTTPError) *APIError {
+	apiErr := &APIError{
+		StatusCode: httpErr.StatusCode,
+		RetryAfter: backoff.ParseRetryAfter(httpErr.Header.Get("Retry-After"), time.Now()),
+		RawBody:    httpErr.Body,
+	}
+	var body struct {
+		ErrorCode    ErrorCode `json:"errorCode"`
+		ErrorMessage string    `json:"errorMessage"`
+	}
+	if len(httpErr.Body) > 0 && json.Unmarshal(httpErr.Body, &body) == nil {
+		apiErr.ErrorCode = body.ErrorCode
+		apiErr.ErrorMessage = body.ErrorMessage
+	}
+	return apiErr
+}
+
+// request runs params on the service's client and converts Apple's
+// error responses into [*APIError]. Transport and local errors are
+// returned unchanged.
+func (s *Service) request(params Apple.RequestParams) error {
+	err := s.client.Request(params)
+	if err == nil {
+		return nil
+	}
+	var httpErr *Apple.HTTPError
+	if errors.As(err, &httpErr) {
+		return parseAPIError(httpErr)
+	}
+	return err
+}
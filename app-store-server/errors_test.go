@@ -0,0 +1,89 @@
+package AppStoreServer
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAPIError_Formatting(t *testing.T) {
+	e := &APIError{StatusCode: 404, ErrorCode: ErrorCodeTransactionIdNotFound, ErrorMessage: "Transaction id not found."}
+	msg := e.Error()
+	if !strings.Contains(msg, "HTTP 404") || !strings.Contains(msg, "[4040010]") || !strings.Contains(msg, "Transaction id not found.") {
+		t.Errorf("error = %q", msg)
+	}
+
+	raw := &APIError{StatusCode: 502, RawBody: []byte("bad gateway\n")}
+	if got := raw.Error(); got != "app store server: HTTP 502: bad gateway" {
+		t.Errorf("raw error = %q", got)
+	}
+}
+
+func TestAPIError_IsSentinel(t *testing.T) {
+	var err error = &APIError{StatusCode: 404, ErrorCode: ErrorCodeTransactionIdNotFound}
+	if !errors.Is(err, ErrTransactionNotFound) {
+		t.Error("expected errors.Is(err, ErrTransactionNotFound)")
+	}
+	if errors.Is(err, ErrRateLimitExceeded) {
+		t.Error("unexpected match against ErrRateLimitExceeded")
+	}
+	retryable := &APIError{StatusCode: 404, ErrorCode: ErrorCodeOriginalTransactionIdNotFoundRetryable}
+	if !errors.Is(retryable, ErrOriginalTransactionNotFound) {
+		t.Error("retryable variant should match the same sentinel")
+	}
+}
+
+func TestAPIError_IsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  APIError
+		want bool
+	}{
+		{"rate limit", APIError{StatusCode: 429, ErrorCode: ErrorCodeRateLimitExceeded}, true},
+		{"bare 429", APIError{StatusCode: 429}, true},
+		{"bare 503", APIError{StatusCode: 503}, true},
+		{"internal retryable", APIError{StatusCode: 500, ErrorCode: ErrorCodeGeneralInternalRetryable}, true},
+		{"internal", APIError{StatusCode: 500, ErrorCode: ErrorCodeGeneralInternal}, false},
+		{"account retryable", APIError{StatusCode: 404, ErrorCode: ErrorCodeAccountNotFoundRetryable}, true},
+		{"not found", APIError{StatusCode: 404, ErrorCode: ErrorCodeTransactionIdNotFound}, false},
+		{"bad request", APIError{StatusCode: 400, ErrorCode: ErrorCodeInvalidTransactionId}, false},
+		{"unauthorized", APIError{StatusCode: 401}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.err.IsRetryable(); got != tt.want {
+				t.Errorf("IsRetryable = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestService_ErrorResponse(t *testing.T) {
+	svc, _ := newTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Retry-After", "7")
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"errorCode":4290000,"errorMessage":"Rate limit exceeded."}`))
+	}))
+
+	_, err := svc.GetTransactionInfo(context.Background(), "1")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("err = %T %v, want *APIError", err, err)
+	}
+	if apiErr.StatusCode != http.StatusTooManyRequests || apiErr.ErrorCode != ErrorCodeRateLimitExceeded {
+		t.Errorf("apiErr = %+v", apiErr)
+	}
+	if apiErr.ErrorMessage != "Rate limit exceeded." {
+		t.Errorf("message = %q", apiErr.ErrorMessage)
+	}
+	if apiErr.RetryAfter != 7*time.Second {
+		t.Errorf("RetryAfter = %v", apiErr.RetryAfter)
+	}
+	if !errors.Is(err, ErrRateLimitExceeded) || !apiErr.IsRetryable() {
+		t.Error("expected rate-limit sentinel and retryable")
+	}
+}
@@ -0,0 +1,296 @@
+package AppStoreServer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	Apple "github.com/godrealms/go-apple-sdk"
+	"github.com/godrealms/go-apple-sdk/types"
+)
+
+// ErrInvalidExternalPurchaseReport is matched under errors.Is by every
+// problem [ExternalPurchaseReportValidator] finds.
+var ErrInvalidExternalPurchaseReport = errors.New("app store server: invalid external purchase report")
+
+// ExternalPurchaseEventType The kind of event an external purchase line item reports.
+type ExternalPurchaseEventType string
+
+const (
+	ExternalPurchaseEventTypePurchase ExternalPurchaseEventType = "PURCHASE" // The customer paid.
+	ExternalPurchaseEventTypeRefund   ExternalPurchaseEventType = "REFUND"   // You refunded an earlier purchase, fully or partly.
+)
+
+// ExternalPurchaseProductType The kind of product an external purchase line item sells.
+type ExternalPurchaseProductType string
+
+const (
+	ExternalPurchaseProductTypeOneTimeBuy   ExternalPurchaseProductType = "ONE_TIME_BUY"
+	ExternalPurchaseProductTypeSubscription ExternalPurchaseProductType = "SUBSCRIPTION"
+)
+
+// ExternalPurchaseReportStatus The processing state of a submitted report.
+type ExternalPurchaseReportStatus string
+
+const (
+	ExternalPurchaseReportStatusPending  ExternalPurchaseReportStatus = "PENDING"  // Apple has not processed the report yet.
+	ExternalPurchaseReportStatusAccepted ExternalPurchaseReportStatus = "ACCEPTED" // Apple recorded the report.
+	ExternalPurchaseReportStatusRejected ExternalPurchaseReportStatus = "REJECTED" // Apple rejected the report; see Errors.
+)
+
+// ExternalPurchaseLineItem A purchase or refund made with an external purchase token.
+type ExternalPurchaseLineItem struct {
+	// (Required) Your identifier for the line item, unique across your reports.
+	LineItemId string `json:"lineItemId"`
+	// (Required) Whether the line item is a purchase or a refund.
+	EventType ExternalPurchaseEventType `json:"eventType"`
+	// (Required) The UNIX time, in milliseconds, of the purchase or refund.
+	EventDate types.Timestamp `json:"eventDate"`
+	// (Required) The kind of product sold.
+	ProductType ExternalPurchaseProductType `json:"productType"`
+	// (Required) The customer's storefront, as an ISO 3166-1 alpha-3 country code.
+	Storefront string `json:"storefront"`
+	// (Required) The currency of the amounts, as an ISO 4217 code.
+	Currency string `json:"currency"`
+	// (Required) The number of units; at least 1.
+	Quantity int `json:"quantity"`
+	// (Required) The amount charged or refunded including tax, in milliunits of Currency.
+	TotalAmount int64 `json:"totalAmount"`
+	// The tax included in TotalAmount, in milliunits of Currency.
+	TaxAmount int64 `json:"taxAmount"`
+	// The lineItemId of the purchase a refund applies to. Required for refunds only.
+	RefundedLineItemId string `json:"refundedLineItemId,omitempty"`
+}
+
+// ExternalPurchaseReport The request body for reporting the transactions made with one external purchase token.
+type ExternalPurchaseReport struct {
+	// (Required) A UUID you generate for the report; reuse it when retrying.
+	RequestIdentifier types.UUID `json:"requestIdentifier"`
+	// (Required) The externalPurchaseId of the token the report is for.
+	ExternalPurchaseId types.ExternalPurchaseId `json:"externalPurchaseId"`
+	// The purchases and refunds made with the token.
+	LineItems []ExternalPurchaseLineItem `json:"lineItems,omitempty"`
+	// Set when the token resulted in no purchase, instead of LineItems.
+	NoLineItems bool `json:"noLineItems,omitempty"`
+}
+
+// NewExternalPurchaseReport returns an empty report for externalPurchaseId
+// with a random requestIdentifier.
+func NewExternalPurchaseReport(externalPurchaseId types.ExternalPurchaseId) *ExternalPurchaseReport {
+	return &ExternalPurchaseReport{
+		RequestIdentifier:  types.UUID(types.NewRequestIdentifier()),
+		ExternalPurchaseId: externalPurchaseId,
+	}
+}
+
+// Validate checks r with a zero [ExternalPurchaseReportValidator].
+func (r *ExternalPurchaseReport) Validate() error {
+	return ExternalPurchaseReportValidator{}.Validate(r)
+}
+
+// ExternalPurchaseReportValidator checks a report against Apple's rules
+// before it is sent, so a rejected report does not cost a round trip.
+type ExternalPurchaseReportValidator struct {
+	// TokenCreationDate, when non-zero, rejects events dated before the
+	// token was created. Take it from the externalPurchaseToken of the
+	// EXTERNAL_PURCHASE_TOKEN notification.
+	TokenCreationDate types.TokenCreationDate
+	// Now returns the current time for the future-date check. Defaults
+	// to time.Now.
+	Now func() time.Time
+}
+
+// Validate returns nil when r is valid. Otherwise it returns every
+// problem found, joined with errors.Join; each matches
+// [ErrInvalidExternalPurchaseReport] under errors.Is.
+func (v ExternalPurchaseReportValidator) Validate(r *ExternalPurchaseReport) error {
+	if r == nil {
+		return fmt.Errorf("%w: report is nil", ErrInvalidExternalPurchaseReport)
+	}
+	now := time.Now
+	if v.Now != nil {
+		now = v.Now
+	}
+	nowMillis := now().UnixMilli()
+
+	var errs []error
+	fail := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%w: "+format, append([]any{ErrInvalidExternalPurchaseReport}, args...)...))
+	}
+	if !r.RequestIdentifier.IsValidUUID() {
+		fail("requestIdentifier %q is not a UUID", r.RequestIdentifier)
+	}
+	if r.ExternalPurchaseId == "" {
+		fail("externalPurchaseId is required")
+	}
+	switch {
+	case r.NoLineItems && len(r.LineItems) > 0:
+		fail("noLineItems cannot be combined with lineItems")
+	case !r.NoLineItems && len(r.LineItems) == 0:
+		fail("lineItems is empty; set noLineItems to report a token without purchases")
+	}
+
+	byID := make(map[string]ExternalPurchaseLineItem, len(r.LineItems))
+	for _, item := range r.LineItems {
+		if item.LineItemId == "" {
+			continue
+		}
+		if _, dup := byID[item.LineItemId]; dup {
+			fail("duplicate lineItemId %q", item.LineItemId)
+			continue
+		}
+		byID[item.LineItemId] = item
+	}
+	// refunded sums the refunds of each in-report purchase, so partial
+	// refunds cannot add up to more than was paid.
+	refunded := make(map[string]int64)
+	for i, item := range r.LineItems {
+		field := fmt.Sprintf("lineItems[%d]", i)
+		if item.LineItemId == "" {
+			fail("%s: lineItemId is required", field)
+		}
+		switch {
+		case item.EventDate <= 0:
+			fail("%s: eventDate is required", field)
+		case int64(item.EventDate) > nowMillis:
+			fail("%s: eventDate is in the future", field)
+		case v.TokenCreationDate > 0 && int64(item.EventDate) < v.TokenCreationDate.Int64():
+			fail("%s: eventDate is before the token was created", field)
+		}
+		switch item.ProductType {
+		case ExternalPurchaseProductTypeOneTimeBuy, ExternalPurchaseProductTypeSubscription:
+		default:
+			fail("%s: unknown productType %q", field, item.ProductType)
+		}
+		if !isUpperAlpha(item.Storefront, 3) {
+			fail("%s: storefront %q is not an ISO 3166-1 alpha-3 code", field, item.Storefront)
+		}
+		if !isUpperAlpha(item.Currency, 3) {
+			fail("%s: currency %q is not an ISO 4217 code", field, item.Currency)
+		}
+		if item.Quantity < 1 {
+			fail("%s: quantity must be at least 1", field)
+		}
+		switch {
+		case item.TotalAmount < 0 || item.TaxAmount < 0:
+			fail("%s: amounts must not be negative", field)
+		case item.TaxAmount > item.TotalAmount:
+			fail("%s: taxAmount exceeds totalAmount", field)
+		}
+
+		switch item.EventType {
+		case ExternalPurchaseEventTypePurchase:
+			if item.RefundedLineItemId != "" {
+				fail("%s: refundedLineItemId is only allowed on refunds", field)
+			}
+		case ExternalPurchaseEventTypeRefund:
+			if item.RefundedLineItemId == "" || item.RefundedLineItemId == item.LineItemId {
+				fail("%s: refund must reference the purchase it refunds in refundedLineItemId", field)
+				break
+			}
+			// The purchase may have been reported earlier; it can
+			// only be cross-checked when it is in this report.
+			if purchase, ok := byID[item.RefundedLineItemId]; ok {
+				refunded[item.RefundedLineItemId] += item.TotalAmount
+				switch {
+				case purchase.EventType != ExternalPurchaseEventTypePurchase:
+					fail("%s: refundedLineItemId %q is not a purchase", field, item.RefundedLineItemId)
+				case purchase.Currency != item.Currency:
+					fail("%s: refund currency differs from the purchase", field)
+				case refunded[item.RefundedLineItemId] > purchase.TotalAmount:
+					fail("%s: refunds of %q exceed the purchase amount", field, item.RefundedLineItemId)
+				case item.EventDate < purchase.EventDate:
+					fail("%s: refund is dated before the purchase", field)
+				}
+			}
+		default:
+			fail("%s: unknown eventType %q", field, item.EventType)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func isUpperAlpha(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+	for _, c := range s {
+		if c < 'A' || c > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// ExternalPurchaseReportError A problem Apple found in a submitted report.
+type ExternalPurchaseReportError struct {
+	// The line item the problem is in; empty for report-level problems.
+	LineItemId   string    `json:"lineItemId,omitempty"`
+	ErrorCode    ErrorCode `json:"errorCode"`
+	ErrorMessage string    `json:"errorMessage"`
+}
+
+// ExternalPurchaseReportStatusResponse A response that contains the processing state of a report.
+type ExternalPurchaseReportStatusResponse struct {
+	RequestIdentifier types.UUID                    `json:"requestIdentifier"`
+	Status            ExternalPurchaseReportStatus  `json:"status"`
+	Errors            []ExternalPurchaseReportError `json:"errors,omitempty"`
+}
+
+// SendExternalPurchaseReport Report the purchases and refunds made with an external purchase token.
+//
+// The report is checked with [ExternalPurchaseReport.Validate] first;
+// use an [ExternalPurchaseReportValidator] beforehand for the
+// token-creation-date check. Resending a report with the same
+// requestIdentifier is safe.
+func (s *Service) SendExternalPurchaseReport(ctx context.Context, report *ExternalPurchaseReport) error {
+	if err := report.Validate(); err != nil {
+		return err
+	}
+	return s.request(Apple.RequestParams{
+		Ctx:    ctx,
+		Method: "PUT",
+		Path:   "/externalPurchase/v1/reports",
+		Body:   report,
+		Headers: map[string]string{
+			"Content-Type": "application/json",
+		},
+	})
+}
+
+// GetExternalPurchaseReportStatus Get the processing state of a report you sent.
+func (s *Service) GetExternalPurchaseReportStatus(ctx context.Context, requestIdentifier types.UUID) (*ExternalPurchaseReportStatusResponse, error) {
+	if !requestIdentifier.IsValidUUID() {
+		return nil, fmt.Errorf("app store server: external purchase report: requestIdentifier %q is not a UUID", requestIdentifier)
+	}
+	var result = new(ExternalPurchaseReportStatusResponse)
+	params := Apple.RequestParams{
+		Ctx:    ctx,
+		Method: "GET",
+		Path:   "/externalPurchase/v1/reports/{requestIdentifier}",
+		Result: result,
+		Headers: map[string]string{
+			"Accept": "application/json",
+		},
+		PathParams: map[string]string{
+			"requestIdentifier": string(requestIdentifier),
+		},
+	}
+	if err := s.request(params); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// SendExternalPurchaseReport calls [Service.SendExternalPurchaseReport]
+// on a Service for client's environment.
+func SendExternalPurchaseReport(ctx context.Context, client *Apple.Client, report *ExternalPurchaseReport) error {
+	return NewService(client).SendExternalPurchaseReport(ctx, report)
+}
+
+// GetExternalPurchaseReportStatus calls [Service.GetExternalPurchaseReportStatus]
+// on a Service for client's environment.
+func GetExternalPurchaseReportStatus(ctx context.Context, client *Apple.Client, requestIdentifier types.UUID) (*ExternalPurchaseReportStatusResponse, error) {
+	return NewService(client).GetExternalPurchaseReportStatus(ctx, requestIdentifier)
+}
@@ -0,0 +1,124 @@
+package AppStoreServer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/godrealms/go-apple-sdk/types"
+)
+
+const testReportID types.UUID = "a1b2c3d4-0000-4000-8000-000000000003"
+
+func validExternalPurchaseReport() *ExternalPurchaseReport {
+	return &ExternalPurchaseReport{
+		RequestIdentifier:  testReportID,
+		ExternalPurchaseId: "ext-1",
+		LineItems: []ExternalPurchaseLineItem{
+			{
+				LineItemId: "p1", EventType: ExternalPurchaseEventTypePurchase, EventDate: 1_700_000_000_000,
+				ProductType: ExternalPurchaseProductTypeOneTimeBuy, Storefront: "NLD", Currency: "EUR",
+				Quantity: 1, TotalAmount: 9990, TaxAmount: 1734,
+			},
+			{
+				LineItemId: "r1", EventType: ExternalPurchaseEventTypeRefund, EventDate: 1_700_000_100_000,
+				ProductType: ExternalPurchaseProductTypeOneTimeBuy, Storefront: "NLD", Currency: "EUR",
+				Quantity: 1, TotalAmount: 4990, TaxAmount: 866, RefundedLineItemId: "p1",
+			},
+		},
+	}
+}
+
+func TestExternalPurchaseReportValidator(t *testing.T) {
+	now := func() time.Time { return time.UnixMilli(1_800_000_000_000) }
+	if err := (ExternalPurchaseReportValidator{Now: now}).Validate(validExternalPurchaseReport()); err != nil {
+		t.Fatalf("valid report: %v", err)
+	}
+	if err := (&ExternalPurchaseReport{RequestIdentifier: testReportID, ExternalPurchaseId: "ext-1", NoLineItems: true}).Validate(); err != nil {
+		t.Fatalf("no-line-item report: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		v       ExternalPurchaseReportValidator
+		mutate  func(r *ExternalPurchaseReport)
+		wantErr string
+	}{
+		{"bad request id", ExternalPurchaseReportValidator{}, func(r *ExternalPurchaseReport) { r.RequestIdentifier = "x" }, "not a UUID"},
+		{"no items", ExternalPurchaseReportValidator{}, func(r *ExternalPurchaseReport) { r.LineItems = nil }, "set noLineItems"},
+		{"items and noLineItems", ExternalPurchaseReportValidator{}, func(r *ExternalPurchaseReport) { r.NoLineItems = true }, "cannot be combined"},
+		{"duplicate id", ExternalPurchaseReportValidator{}, func(r *ExternalPurchaseReport) { r.LineItems[1].LineItemId = "p1" }, "duplicate lineItemId"},
+		{"future event", ExternalPurchaseReportValidator{Now: func() time.Time { return time.UnixMilli(1_700_000_050_000) }}, func(*ExternalPurchaseReport) {}, "in the future"},
+		{"before token", ExternalPurchaseReportValidator{TokenCreationDate: 1_700_000_050_000}, func(*ExternalPurchaseReport) {}, "before the token"},
+		{"lowercase storefront", ExternalPurchaseReportValidator{}, func(r *ExternalPurchaseReport) { r.LineItems[0].Storefront = "nld" }, "storefront"},
+		{"tax above total", ExternalPurchaseReportValidator{}, func(r *ExternalPurchaseReport) { r.LineItems[0].TaxAmount = 10000 }, "taxAmount exceeds"},
+		{"zero quantity", ExternalPurchaseReportValidator{}, func(r *ExternalPurchaseReport) { r.LineItems[0].Quantity = 0 }, "quantity"},
+		{"refund without target", ExternalPurchaseReportValidator{}, func(r *ExternalPurchaseReport) { r.LineItems[1].RefundedLineItemId = "" }, "must reference"},
+		{"refund above purchase", ExternalPurchaseReportValidator{}, func(r *ExternalPurchaseReport) { r.LineItems[1].TotalAmount = 20000 }, "exceed the purchase"},
+		{"partial refunds above purchase", ExternalPurchaseReportValidator{}, func(r *ExternalPurchaseReport) {
+			second := r.LineItems[1]
+			second.LineItemId, second.TotalAmount, second.TaxAmount = "r2", 5010, 870
+			r.LineItems = append(r.LineItems, second)
+		}, `refunds of "p1" exceed the purchase`},
+		{"refund currency", ExternalPurchaseReportValidator{}, func(r *ExternalPurchaseReport) { r.LineItems[1].Currency = "USD" }, "currency differs"},
+		{"purchase with target", ExternalPurchaseReportValidator{}, func(r *ExternalPurchaseReport) { r.LineItems[0].RefundedLineItemId = "x" }, "only allowed on refunds"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := validExternalPurchaseReport()
+			tt.mutate(r)
+			err := tt.v.Validate(r)
+			if !errors.Is(err, ErrInvalidExternalPurchaseReport) || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	// Every problem is reported, not just the first.
+	r := validExternalPurchaseReport()
+	r.ExternalPurchaseId = ""
+	r.LineItems[0].Currency = "eur"
+	if err := r.Validate(); err == nil || strings.Count(err.Error(), "\n") < 1 {
+		t.Errorf("err = %v, want two problems", err)
+	}
+}
+
+func TestService_ExternalPurchaseReports(t *testing.T) {
+	var sent ExternalPurchaseReport
+	svc, _ := newTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPut && r.URL.Path == "/externalPurchase/v1/reports":
+			if err := json.NewDecoder(r.Body).Decode(&sent); err != nil {
+				t.Errorf("decode body: %v", err)
+			}
+		case r.Method == http.MethodGet && r.URL.Path == "/externalPurchase/v1/reports/"+string(testReportID):
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"requestIdentifier":"` + string(testReportID) + `","status":"REJECTED","errors":[{"lineItemId":"r1","errorCode":4000000,"errorMessage":"bad"}]}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	ctx := context.Background()
+
+	if err := svc.SendExternalPurchaseReport(ctx, validExternalPurchaseReport()); err != nil {
+		t.Fatalf("SendExternalPurchaseReport: %v", err)
+	}
+	if sent.ExternalPurchaseId != "ext-1" || len(sent.LineItems) != 2 || sent.LineItems[1].RefundedLineItemId != "p1" {
+		t.Errorf("sent = %+v", sent)
+	}
+	status, err := svc.GetExternalPurchaseReportStatus(ctx, testReportID)
+	if err != nil || status.Status != ExternalPurchaseReportStatusRejected || len(status.Errors) != 1 || status.Errors[0].LineItemId != "r1" {
+		t.Errorf("GetExternalPurchaseReportStatus = %+v, %v", status, err)
+	}
+
+	if err := svc.SendExternalPurchaseReport(ctx, &ExternalPurchaseReport{}); !errors.Is(err, ErrInvalidExternalPurchaseReport) {
+		t.Errorf("invalid report: err = %v", err)
+	}
+	if _, err := svc.GetExternalPurchaseReportStatus(ctx, "x"); err == nil {
+		t.Error("invalid requestIdentifier accepted")
+	}
+}
@@ -0,0 +1,133 @@
+package AppStoreServer
+
+import (
+	"context"
+	"errors"
+
+	Apple "github.com/godrealms/go-apple-sdk"
+	"github.com/godrealms/go-apple-sdk/types"
+)
+
+// FallbackService looks transactions up in production first and, when
+// Apple answers TransactionIdNotFound (4040010), or
+// AppTransactionDoesNotExist (4040019) for app transactions, again in
+// sandbox. This is the flow Apple recommends for servers that see both
+// real purchases and TestFlight or App Review ones, which live in
+// sandbox.
+//
+// Every method also returns the environment that produced the result
+// (or, on failure, the last error).
+//
+// FallbackService is safe for concurrent use by multiple goroutines.
+type FallbackService struct {
+	production *Service
+	sandbox    *Service
+}
+
+// NewFallbackService returns a FallbackService using client's
+// credentials for both environments. The client's own Sandbox setting
+// is ignored.
+func NewFallbackService(client *Apple.Client) *FallbackService {
+	return NewFallback(
+		New(client, Config{Environment: types.EnvironmentProduction}),
+		New(client, Config{Environment: types.EnvironmentSandbox}),
+	)
+}
+
+// NewFallback combines two existing services. production is asked
+// first; sandbox only when production does not know the transaction.
+func NewFallback(production, sandbox *Service) *FallbackService {
+	if production == nil || sandbox == nil {
+		panic("AppStoreServer.NewFallback: both services are required")
+	}
+	return &FallbackService{production: production, sandbox: sandbox}
+}
+
+// Production returns the service used for the first attempt.
+func (f *FallbackService) Production() *Service { return f.production }
+
+// Sandbox returns the service used for the fallback attempt.
+func (f *FallbackService) Sandbox() *Service { return f.sandbox }
+
+// withFallback runs call against production, then against sandbox if
+// production reported the transaction as not found: with
+// ErrTransactionNotFound or one of notFound. missing, when non-nil,
+// flags successful responses that also mean "not here".
+func withFallback[T any](f *FallbackService, call func(*Service) (T, error), missing func(T) bool, notFound ...error) (T, types.Environment, error) {
+	result, err := call(f.production)
+	if err == nil && (missing == nil || !missing(result)) {
+		return result, f.production.Environment(), nil
+	}
+	if err != nil && !isNotFound(err, notFound) {
+		return result, f.production.Environment(), err
+	}
+	result, err = call(f.sandbox)
+	return result, f.sandbox.Environment(), err
+}
+
+// isNotFound reports whether err is ErrTransactionNotFound or one of
+// extra.
+func isNotFound(err error, extra []error) bool {
+	if errors.Is(err, ErrTransactionNotFound) {
+		return true
+	}
+	for _, target := range extra {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetTransactionInfo is [Service.GetTransactionInfo] with sandbox
+// fallback.
+func (f *FallbackService) GetTransactionInfo(ctx context.Context, transactionId string) (*TransactionInfoResponse, types.Environment, error) {
+	return withFallback(f, func(s *Service) (*TransactionInfoResponse, error) {
+		return s.GetTransactionInfo(ctx, transactionId)
+	}, nil)
+}
+
+// GetAppTransactionInfo is [Service.GetAppTransactionInfo] with
+// sandbox fallback. Apple reports a missing app transaction as
+// AppTransactionDoesNotExist (4040019), which triggers the fallback
+// as well.
+func (f *FallbackService) GetAppTransactionInfo(ctx context.Context, transactionId string) (*AppTransactionInfoResponse, types.Environment, error) {
+	return withFallback(f, func(s *Service) (*AppTransactionInfoResponse, error) {
+		return s.GetAppTransactionInfo(ctx, transactionId)
+	}, nil, ErrAppTransactionNotFound)
+}
+
+// GetTransactionHistory is [Service.GetTransactionHistory] with
+// sandbox fallback.
+func (f *FallbackService) GetTransactionHistory(ctx context.Context, transactionId string, req TransactionHistoryRequest) (*HistoryResponse, types.Environment, error) {
+	return withFallback(f, func(s *Service) (*HistoryResponse, error) {
+		return s.GetTransactionHistory(ctx, transactionId, req)
+	}, nil)
+}
+
+// GetAllSubscriptionStatuses is [Service.GetAllSubscriptionStatuses]
+// with sandbox fallback.
+func (f *FallbackService) GetAllSubscriptionStatuses(ctx context.Context, transactionId string) (*StatusResponse, types.Environment, error) {
+	return withFallback(f, func(s *Service) (*StatusResponse, error) {
+		return s.GetAllSubscriptionStatuses(ctx, transactionId)
+	}, nil)
+}
+
+// GetRefundHistory is [Service.GetRefundHistory] with sandbox fallback.
+func (f *FallbackService) GetRefundHistory(ctx context.Context, transactionId string, revision types.Revision) (*RefundHistoryResponse, types.Environment, error) {
+	return withFallback(f, func(s *Service) (*RefundHistoryResponse, error) {
+		return s.GetRefundHistory(ctx, transactionId, revision)
+	}, nil)
+}
+
+// LookUpOrderID is [Service.LookUpOrderID] with sandbox fallback.
+// Apple reports an unknown order ID with status
+// [types.OrderLookupStatusInvalid] rather than an error, so that status
+// triggers the fallback as well.
+func (f *FallbackService) LookUpOrderID(ctx context.Context, orderId string) (*OrderLookupResponse, types.Environment, error) {
+	return withFallback(f, func(s *Service) (*OrderLookupResponse, error) {
+		return s.LookUpOrderID(ctx, orderId)
+	}, func(r *OrderLookupResponse) bool {
+		return r.Status == types.OrderLookupStatusInvalid
+	})
+}
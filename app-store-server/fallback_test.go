@@ -0,0 +1,132 @@
+package AppStoreServer
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/godrealms/go-apple-sdk/types"
+)
+
+// newFallbackTestService wires a FallbackService to two test servers
+// and counts the requests each one receives.
+func newFallbackTestService(t *testing.T, production, sandbox http.HandlerFunc) (*FallbackService, *int, *int) {
+	t.Helper()
+	var prodCalls, sandboxCalls int
+	prod := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		prodCalls++
+		production(w, r)
+	}))
+	t.Cleanup(prod.Close)
+	sb := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sandboxCalls++
+		sandbox(w, r)
+	}))
+	t.Cleanup(sb.Close)
+
+	client := newTestClient(t, false)
+	return NewFallback(
+		New(client, Config{Environment: types.EnvironmentProduction, BaseURL: prod.URL}),
+		New(client, Config{Environment: types.EnvironmentSandbox, BaseURL: sb.URL}),
+	), &prodCalls, &sandboxCalls
+}
+
+func respond(status int, body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}
+}
+
+const transactionNotFound = `{"errorCode":4040010,"errorMessage":"Transaction id not found."}`
+
+func TestFallback_FallsBackOnTransactionNotFound(t *testing.T) {
+	f, prodCalls, sandboxCalls := newFallbackTestService(t,
+		respond(http.StatusNotFound, transactionNotFound),
+		respond(http.StatusOK, `{"signedTransactionInfo":"sandbox.jws.sig"}`))
+
+	info, env, err := f.GetTransactionInfo(context.Background(), "2000000000000001")
+	if err != nil {
+		t.Fatalf("GetTransactionInfo: %v", err)
+	}
+	if env != types.EnvironmentSandbox || info.SignedTransactionInfo != "sandbox.jws.sig" {
+		t.Errorf("env = %s, info = %+v", env, info)
+	}
+	if *prodCalls != 1 || *sandboxCalls != 1 {
+		t.Errorf("calls = production %d, sandbox %d", *prodCalls, *sandboxCalls)
+	}
+}
+
+func TestFallback_FallsBackOnAppTransactionNotFound(t *testing.T) {
+	f, prodCalls, sandboxCalls := newFallbackTestService(t,
+		respond(http.StatusNotFound, `{"errorCode":4040019,"errorMessage":"App transaction does not exist."}`),
+		respond(http.StatusOK, `{"signedAppTransactionInfo":"sandbox.jws.sig"}`))
+
+	info, env, err := f.GetAppTransactionInfo(context.Background(), "2000000000000001")
+	if err != nil {
+		t.Fatalf("GetAppTransactionInfo: %v", err)
+	}
+	if env != types.EnvironmentSandbox || info.SignedAppTransactionInfo != "sandbox.jws.sig" {
+		t.Errorf("env = %s, info = %+v", env, info)
+	}
+	if *prodCalls != 1 || *sandboxCalls != 1 {
+		t.Errorf("calls = production %d, sandbox %d", *prodCalls, *sandboxCalls)
+	}
+}
+
+func TestFallback_ProductionAnswers(t *testing.T) {
+	f, _, sandboxCalls := newFallbackTestService(t,
+		respond(http.StatusOK, `{"signedTransactions":[],"hasMore":false}`),
+		respond(http.StatusOK, `{}`))
+
+	_, env, err := f.GetTransactionHistory(context.Background(), "1", TransactionHistoryRequest{})
+	if err != nil || env != types.EnvironmentProduction {
+		t.Errorf("env = %s, err = %v", env, err)
+	}
+	if *sandboxCalls != 0 {
+		t.Errorf("sandbox called %d times", *sandboxCalls)
+	}
+}
+
+func TestFallback_OtherErrorsDoNotFallBack(t *testing.T) {
+	f, _, sandboxCalls := newFallbackTestService(t,
+		respond(http.StatusUnauthorized, ``),
+		respond(http.StatusOK, `{}`))
+
+	_, env, err := f.GetAllSubscriptionStatuses(context.Background(), "1")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized || env != types.EnvironmentProduction {
+		t.Errorf("env = %s, err = %v", env, err)
+	}
+	if *sandboxCalls != 0 {
+		t.Errorf("sandbox called %d times", *sandboxCalls)
+	}
+}
+
+func TestFallback_NotFoundAnywhere(t *testing.T) {
+	f, _, _ := newFallbackTestService(t,
+		respond(http.StatusNotFound, transactionNotFound),
+		respond(http.StatusNotFound, transactionNotFound))
+
+	_, env, err := f.GetRefundHistory(context.Background(), "1", "")
+	if !errors.Is(err, ErrTransactionNotFound) || env != types.EnvironmentSandbox {
+		t.Errorf("env = %s, err = %v", env, err)
+	}
+}
+
+func TestFallback_LookUpOrderIDInvalidStatus(t *testing.T) {
+	f, _, sandboxCalls := newFallbackTestService(t,
+		respond(http.StatusOK, `{"status":1}`),
+		respond(http.StatusOK, `{"status":0,"signedTransactions":["a.b.c"]}`))
+
+	resp, env, err := f.LookUpOrderID(context.Background(), "MQ5Z3X1CY")
+	if err != nil {
+		t.Fatalf("LookUpOrderID: %v", err)
+	}
+	if env != types.EnvironmentSandbox || resp.Status != types.OrderLookupStatusValid || *sandboxCalls != 1 {
+		t.Errorf("env = %s, resp = %+v", env, resp)
+	}
+}
@@ -0,0 +1,66 @@
+package AppStoreServer
+
+import "context"
+
+// Iterator walks every item of a paginated App Store Server API
+// endpoint, fetching the next page only when the current one is used
+// up.
+//
+// Typical usage:
+//
+//	it := svc.NotificationHistory(req)
+//	for it.Next(ctx) {
+//	    handle(it.Value())
+//	}
+//	if err := it.Err(); err != nil {
+//	    return err
+//	}
+//
+// Iterator is not safe for concurrent use.
+type Iterator[T any] struct {
+	// fetch returns the next page and whether another one follows.
+	fetch func(ctx context.Context) (items []T, more bool, err error)
+
+	buf  []T
+	cur  T
+	more bool
+	err  error
+}
+
+func newIterator[T any](fetch func(ctx context.Context) ([]T, bool, error)) *Iterator[T] {
+	return &Iterator[T]{fetch: fetch, more: true}
+}
+
+// Next advances to the next item, fetching a page if needed. It
+// returns false when the items are exhausted or an error occurred;
+// call [Iterator.Err] to tell the two apart.
+func (it *Iterator[T]) Next(ctx context.Context) bool {
+	for len(it.buf) == 0 {
+		if it.err != nil || !it.more {
+			return false
+		}
+		items, more, err := it.fetch(ctx)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		it.buf, it.more = items, more
+	}
+	it.cur, it.buf = it.buf[0], it.buf[1:]
+	return true
+}
+
+// Value returns the current item. Only valid after Next returned true.
+func (it *Iterator[T]) Value() T { return it.cur }
+
+// Err returns the error that stopped iteration, if any.
+func (it *Iterator[T]) Err() error { return it.err }
+
+// All consumes the iterator and returns every remaining item.
+func (it *Iterator[T]) All(ctx context.Context) ([]T, error) {
+	var out []T
+	for it.Next(ctx) {
+		out = append(out, it.cur)
+	}
+	return out, it.err
+}
@@ -0,0 +1,229 @@
+package AppStoreServer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/godrealms/go-apple-sdk/types"
+)
+
+// RequestIdentifierStore persists the requestIdentifier generated for a
+// mass renewal-date extension under a caller-chosen key, so a retried
+// job (after a crash or timeout) resends the same identifier and Apple
+// treats it as the same request instead of extending dates twice.
+type RequestIdentifierStore interface {
+	// Load returns the identifier saved under key; ok is false if none.
+	Load(ctx context.Context, key string) (id types.RequestIdentifier, ok bool, err error)
+	// Save records id under key.
+	Save(ctx context.Context, key string, id types.RequestIdentifier) error
+}
+
+// MemoryRequestIdentifierStore is a [RequestIdentifierStore] that lives
+// as long as the process. The zero value is ready to use.
+type MemoryRequestIdentifierStore struct {
+	mu  sync.Mutex
+	ids map[string]types.RequestIdentifier
+}
+
+// Load implements [RequestIdentifierStore].
+func (m *MemoryRequestIdentifierStore) Load(_ context.Context, key string) (types.RequestIdentifier, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	id, ok := m.ids[key]
+	return id, ok, nil
+}
+
+// Save implements [RequestIdentifierStore].
+func (m *MemoryRequestIdentifierStore) Save(_ context.Context, key string, id types.RequestIdentifier) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.ids == nil {
+		m.ids = make(map[string]types.RequestIdentifier)
+	}
+	m.ids[key] = id
+	return nil
+}
+
+// FileRequestIdentifierStore is a [RequestIdentifierStore] backed by a
+// JSON object in a single file, which survives process restarts. Writes
+// go through a temporary file and a rename.
+type FileRequestIdentifierStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileRequestIdentifierStore returns a store that keeps its
+// identifiers in path. The file is created on the first Save.
+func NewFileRequestIdentifierStore(path string) *FileRequestIdentifierStore {
+	return &FileRequestIdentifierStore{path: path}
+}
+
+func (f *FileRequestIdentifierStore) read() (map[string]types.RequestIdentifier, error) {
+	ids := make(map[string]types.RequestIdentifier)
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return ids, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &ids); err != nil {
+		return nil, fmt.Errorf("app store server: request identifier store %s: %w", f.path, err)
+	}
+	return ids, nil
+}
+
+// Load implements [RequestIdentifierStore].
+func (f *FileRequestIdentifierStore) Load(_ context.Context, key string) (types.RequestIdentifier, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	ids, err := f.read()
+	if err != nil {
+		return "", false, err
+	}
+	id, ok := ids[key]
+	return id, ok, nil
+}
+
+// Save implements [RequestIdentifierStore].
+func (f *FileRequestIdentifierStore) Save(_ context.Context, key string, id types.RequestIdentifier) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	ids, err := f.read()
+	if err != nil {
+		return err
+	}
+	ids[key] = id
+	data, err := json.MarshalIndent(ids, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), f.path)
+}
+
+// StartMassExtension calls [Service.ExtendSubscriptionRenewalDatesForAllActiveSubscribers]
+// with an idempotent requestIdentifier and returns it.
+//
+// If body.RequestIdentifier is empty, the identifier saved in store
+// under key is reused; failing that, a new UUID is generated and saved
+// before the request is sent. store may be nil, in which case a fresh
+// identifier is used. body is not modified.
+func (s *Service) StartMassExtension(ctx context.Context, store RequestIdentifierStore, key string, body *MassExtendRenewalDateRequest) (types.RequestIdentifier, error) {
+	if body == nil {
+		return "", errors.New("app store server: mass extension: request is nil")
+	}
+	req := *body
+	if req.RequestIdentifier == "" {
+		id, err := requestIdentifierFor(ctx, store, key)
+		if err != nil {
+			return "", err
+		}
+		req.RequestIdentifier = id
+	}
+	if _, err := s.ExtendSubscriptionRenewalDatesForAllActiveSubscribers(ctx, &req); err != nil {
+		return req.RequestIdentifier, err
+	}
+	return req.RequestIdentifier, nil
+}
+
+func requestIdentifierFor(ctx context.Context, store RequestIdentifierStore, key string) (types.RequestIdentifier, error) {
+	if store == nil {
+		return types.NewRequestIdentifier(), nil
+	}
+	id, ok, err := store.Load(ctx, key)
+	if err != nil {
+		return "", fmt.Errorf("app store server: mass extension: load request identifier: %w", err)
+	}
+	if ok && id != "" {
+		return id, nil
+	}
+	id = types.NewRequestIdentifier()
+	if err := store.Save(ctx, key, id); err != nil {
+		return "", fmt.Errorf("app store server: mass extension: save request identifier: %w", err)
+	}
+	return id, nil
+}
+
+// WithSummaryNotifications lets WaitForMassExtension finish as soon as
+// the RENEWAL_EXTENSION / SUMMARY notification for the request arrives
+// on ch, typically forwarded from your notification handler. Summaries
+// for other requests are ignored.
+func WithSummaryNotifications(ch <-chan types.Summary) WaitOption {
+	return func(o *waitOptions) {
+		o.summaries = ch
+	}
+}
+
+// WaitForMassExtension polls [Service.GetStatusOfSubscriptionRenewalDateExtensions]
+// until Apple reports the mass extension complete, and returns the
+// final status with its succeeded and failed counts.
+//
+// The delay between polls doubles from the initial interval up to the
+// cap; retryable API errors (rate limiting, 5xx) are waited out, using
+// Retry-After when Apple sends it, while other errors are returned.
+// The wait ends early when ctx is done. With [WithSummaryNotifications],
+// a matching summary notification also completes the wait; the status
+// is then built from the notification and CompleteDate is zero.
+func (s *Service) WaitForMassExtension(ctx context.Context, productId string, requestIdentifier types.RequestIdentifier, opts ...WaitOption) (*MassExtendRenewalDateStatusResponse, error) {
+	o := newWaitOptions(opts)
+	var status *MassExtendRenewalDateStatusResponse
+	err := pollUntil(ctx, o, func(ctx context.Context) (done bool, err error) {
+		status, err = s.GetStatusOfSubscriptionRenewalDateExtensions(ctx, productId, string(requestIdentifier))
+		return err == nil && bool(status.Complete), err
+	}, func(ctx context.Context, d time.Duration) bool {
+		var done bool
+		status, done = waitForSummary(ctx, d, o.summaries, productId, requestIdentifier)
+		return done
+	})
+	if err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// waitForSummary sleeps for d, returning early with a completed status
+// if a summary for requestIdentifier arrives on summaries.
+func waitForSummary(ctx context.Context, d time.Duration, summaries <-chan types.Summary, productId string, requestIdentifier types.RequestIdentifier) (*MassExtendRenewalDateStatusResponse, bool) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, false
+		case <-timer.C:
+			return nil, false
+		case summary, ok := <-summaries:
+			if !ok {
+				summaries = nil
+				continue
+			}
+			if summary.RequestIdentifier != requestIdentifier || string(summary.ProductId) != productId {
+				continue
+			}
+			return &MassExtendRenewalDateStatusResponse{
+				RequestIdentifier: summary.RequestIdentifier,
+				Complete:          true,
+				FailedCount:       summary.FailedCount,
+				SucceededCount:    summary.SucceededCount,
+			}, true
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package AppStoreServer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/godrealms/go-apple-sdk/types"
+)
+
+func TestService_StartMassExtension_ReusesStoredIdentifier(t *testing.T) {
+	var mu sync.Mutex
+	var sent []types.RequestIdentifier
+	svc, _ := newTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body MassExtendRenewalDateRequest
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		sent = append(sent, body.RequestIdentifier)
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(MassExtendRenewalDateResponse{RequestIdentifier: body.RequestIdentifier})
+	}))
+	ctx := context.Background()
+	store := &MemoryRequestIdentifierStore{}
+	body := &MassExtendRenewalDateRequest{ExtendByDays: 3, ExtendReasonCode: 3, ProductId: "monthly"}
+
+	first, err := svc.StartMassExtension(ctx, store, "outage-42", body)
+	if err != nil {
+		t.Fatalf("StartMassExtension: %v", err)
+	}
+	if !types.UUID(first).IsValidUUID() {
+		t.Errorf("generated identifier %q is not a UUID", first)
+	}
+	retry, err := svc.StartMassExtension(ctx, store, "outage-42", body)
+	if err != nil || retry != first {
+		t.Errorf("retry identifier = %q (err %v), want %q", retry, err, first)
+	}
+	other, _ := svc.StartMassExtension(ctx, store, "outage-43", body)
+	if other == first {
+		t.Error("a different key reused the identifier")
+	}
+	if body.RequestIdentifier != "" {
+		t.Error("body was modified")
+	}
+	if len(sent) != 3 || sent[0] != first || sent[1] != first || sent[2] != other {
+		t.Errorf("sent = %q", sent)
+	}
+}
+
+func TestFileRequestIdentifierStore(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "ids.json")
+	if _, ok, err := NewFileRequestIdentifierStore(path).Load(ctx, "k"); ok || err != nil {
+		t.Fatalf("empty store: ok=%v err=%v", ok, err)
+	}
+	if err := NewFileRequestIdentifierStore(path).Save(ctx, "k", "id-1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewFileRequestIdentifierStore(path).Save(ctx, "j", "id-2"); err != nil {
+		t.Fatal(err)
+	}
+	id, ok, err := NewFileRequestIdentifierStore(path).Load(ctx, "k")
+	if err != nil || !ok || id != "id-1" {
+		t.Errorf("Load = %q, %v, %v", id, ok, err)
+	}
+}
+
+// newMassStatusService answers status polls from statuses in order,
+// repeating the last one.
+func newMassStatusService(t *testing.T, statuses ...func(w http.ResponseWriter)) (*Service, *atomic.Int32) {
+	t.Helper()
+	var calls atomic.Int32
+	svc, _ := newTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/inApps/v1/subscriptions/extend/mass/monthly/req-1" {
+			t.Errorf("request = %s %s", r.Method, r.URL.Path)
+		}
+		i := int(calls.Add(1)) - 1
+		if i >= len(statuses) {
+			i = len(statuses) - 1
+		}
+		w.Header().Set("Content-Type", "application/json")
+		statuses[i](w)
+	}))
+	return svc, &calls
+}
+
+func massStatus(body string) func(w http.ResponseWriter) {
+	return func(w http.ResponseWriter) { _, _ = w.Write([]byte(body)) }
+}
+
+func TestService_WaitForMassExtension_Polls(t *testing.T) {
+	svc, calls := newMassStatusService(t,
+		massStatus(`{"requestIdentifier":"req-1","complete":false}`),
+		func(w http.ResponseWriter) {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"errorCode":4290000}`))
+		},
+		massStatus(`{"requestIdentifier":"req-1","complete":true,"completeDate":1700000000000,"succeededCount":40,"failedCount":2}`),
+	)
+	status, err := svc.WaitForMassExtension(context.Background(), "monthly", "req-1",
+		WithPollInterval(time.Millisecond, 4*time.Millisecond))
+	if err != nil {
+		t.Fatalf("WaitForMassExtension: %v", err)
+	}
+	if status.SucceededCount != 40 || status.FailedCount != 2 || status.CompleteDate == 0 {
+		t.Errorf("status = %+v", status)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("polls = %d, want 3", calls.Load())
+	}
+}
+
+func TestService_WaitForMassExtension_StopsOnError(t *testing.T) {
+	svc, calls := newMassStatusService(t, func(w http.ResponseWriter) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"errorCode":4040009}`))
+	})
+	_, err := svc.WaitForMassExtension(context.Background(), "monthly", "req-1", WithPollInterval(time.Millisecond, time.Millisecond))
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.ErrorCode != ErrorCodeStatusRequestNotFound || calls.Load() != 1 {
+		t.Errorf("err = %v after %d polls", err, calls.Load())
+	}
+}
+
+func TestService_WaitForMassExtension_SummaryNotification(t *testing.T) {
+	svc, _ := newMassStatusService(t, massStatus(`{"requestIdentifier":"req-1","complete":false}`))
+	summaries := make(chan types.Summary, 2)
+	summaries <- types.Summary{RequestIdentifier: "other", ProductId: "monthly", SucceededCount: 1}
+	summaries <- types.Summary{RequestIdentifier: "req-1", ProductId: "monthly", SucceededCount: 7, FailedCount: 1}
+
+	status, err := svc.WaitForMassExtension(context.Background(), "monthly", "req-1",
+		WithPollInterval(time.Hour, time.Hour), WithSummaryNotifications(summaries))
+	if err != nil {
+		t.Fatalf("WaitForMassExtension: %v", err)
+	}
+	if !status.Complete || status.SucceededCount != 7 || status.FailedCount != 1 {
+		t.Errorf("status = %+v", status)
+	}
+}
+
+func TestService_WaitForMassExtension_HonorsContext(t *testing.T) {
+	svc, _ := newMassStatusService(t, massStatus(`{"requestIdentifier":"req-1","complete":false}`))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := svc.WaitForMassExtension(ctx, "monthly", "req-1", WithPollInterval(time.Hour, time.Hour))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want context.DeadlineExceeded", err)
+	}
+}
@@ -2,31 +2,135 @@ package AppStoreServer
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	Apple "github.com/godrealms/go-apple-sdk"
+	AppStoreNotifications "github.com/godrealms/go-apple-sdk/app-store-server-notifications"
+	"github.com/godrealms/go-apple-sdk/types"
 )
 
-type NotificationHistoryResponse struct{}
+// NotificationHistoryRequest The request body for notification history.
+// StartDate and EndDate are required; the other fields filter the results.
+type NotificationHistoryRequest struct {
+	// The start date of the timespan for the requested App Store Server Notification history records.
+	// The startDate needs to precede the endDate. Choose a startDate that’s within the past 180 days from the current date.
+	StartDate types.Timestamp `json:"startDate"`
+	// The end date of the timespan for the requested App Store Server Notification history records.
+	// Choose an endDate that’s later than the startDate.
+	EndDate types.Timestamp `json:"endDate"`
+	// A notification type. Provide this field to limit the notification history records to those with this one notification type.
+	// Include either the transactionId or the notificationType in your query, but not both.
+	NotificationType types.NotificationType `json:"notificationType,omitempty"`
+	// A notification subtype. Provide this field to limit the notification history records to those with this one notification subtype.
+	// If you specify a notificationSubtype, you need to also specify its related notificationType.
+	NotificationSubtype types.Subtype `json:"notificationSubtype,omitempty"`
+	// The transaction identifier, which may be an original transaction identifier, of any transaction belonging to the customer.
+	// Provide this field to limit the notification history request to this one customer.
+	TransactionId types.TransactionId `json:"transactionId,omitempty"`
+	// A Boolean value you set to true to request only the notifications that haven’t reached your server successfully.
+	OnlyFailures types.OnlyFailures `json:"onlyFailures,omitempty"`
+}
+
+// Validate checks the constraints Apple documents for the request, so
+// they fail locally instead of as HTTP 400.
+func (r NotificationHistoryRequest) Validate() error {
+	switch {
+	case r.StartDate <= 0 || r.EndDate <= 0:
+		return errors.New("app store server: notification history: startDate and endDate are required")
+	case r.EndDate <= r.StartDate:
+		return errors.New("app store server: notification history: endDate must be later than startDate")
+	case r.TransactionId != "" && r.NotificationType != "":
+		return errors.New("app store server: notification history: transactionId and notificationType cannot be combined")
+	case r.NotificationSubtype != "" && r.NotificationType == "":
+		return errors.New("app store server: notification history: notificationSubtype requires notificationType")
+	}
+	return nil
+}
+
+// NotificationHistoryResponse A response that contains the App Store Server Notifications history for your app.
+type NotificationHistoryResponse struct {
+	// An array of App Store server notification history records.
+	NotificationHistory []NotificationHistoryResponseItem `json:"notificationHistory"`
+	// A Boolean value indicating whether the App Store has more transaction data.
+	HasMore types.HasMore `json:"hasMore"`
+	// A pagination token that you return to the endpoint on a subsequent call to receive the next set of results.
+	PaginationToken types.PaginationToken `json:"paginationToken"`
+}
+
+// NotificationHistoryResponseItem The App Store server notification history record,
+// including the signed notification payload and the results of the server’s send attempts.
+type NotificationHistoryResponseItem struct {
+	// The cryptographically signed payload, in JSON Web Signature (JWS) format, containing the original response body of a version 2 notification.
+	SignedPayload AppStoreNotifications.SignedPayload `json:"signedPayload"`
+	// An array of information the App Store server records for its attempts to send a notification to your server.
+	// The maximum number of entries in the array is six.
+	SendAttempts []SendAttemptItem `json:"sendAttempts"`
+}
+
+// HistoricalNotification is a notification history record whose
+// signed payload has been verified and decoded.
+type HistoricalNotification struct {
+	// Payload is the decoded notification.
+	Payload *AppStoreNotifications.ResponseBodyV2DecodedPayload
+	// SendAttempts lists Apple's attempts to deliver it.
+	SendAttempts []SendAttemptItem
+}
 
 // GetNotificationHistory Get a list of notifications that the App Store server attempted to send to your server.
 // paginationToken: A pagination token that you return to the endpoint on a subsequent call to receive the next set of results.
-func GetNotificationHistory(ctx context.Context, client *Apple.Client, paginationToken string) (*NotificationHistoryResponse, error) {
+// Leave it empty for the first page.
+func (s *Service) GetNotificationHistory(ctx context.Context, req NotificationHistoryRequest, paginationToken types.PaginationToken) (*NotificationHistoryResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
 	var result = new(NotificationHistoryResponse)
-	client.SetService(Apple.AppStoreServerClient)
 	params := Apple.RequestParams{
 		Ctx:    ctx,
 		Method: "POST",
 		Path:   "/inApps/v1/notifications/history",
 		Result: result,
-		Body: map[string]string{
-			"paginationToken": paginationToken,
-		},
+		Body:   req,
 		Headers: map[string]string{
-			"Accept": "application/json",
+			"Accept":       "application/json",
+			"Content-Type": "application/json",
 		},
 	}
-	if err := client.Request(params); err != nil {
+	if paginationToken != "" {
+		params.QueryParams = map[string]any{"paginationToken": string(paginationToken)}
+	}
+	if err := s.request(params); err != nil {
 		return nil, err
 	}
 	return result, nil
 }
+
+// NotificationHistory returns an iterator over every notification
+// matching req, across all pages. Each signed payload is verified with
+// the service's verifier before it is yielded; a payload that fails
+// verification stops the iteration with a *jws.VerificationError.
+func (s *Service) NotificationHistory(req NotificationHistoryRequest) *Iterator[*HistoricalNotification] {
+	var token types.PaginationToken
+	return newIterator(func(ctx context.Context) ([]*HistoricalNotification, bool, error) {
+		page, err := s.GetNotificationHistory(ctx, req, token)
+		if err != nil {
+			return nil, false, err
+		}
+		items := make([]*HistoricalNotification, 0, len(page.NotificationHistory))
+		for i, item := range page.NotificationHistory {
+			payload, err := item.SignedPayload.DecodedPayloadWith(s.jwsVerifier())
+			if err != nil {
+				return nil, false, fmt.Errorf("app store server: notification history item %d: %w", i, err)
+			}
+			items = append(items, &HistoricalNotification{Payload: payload, SendAttempts: item.SendAttempts})
+		}
+		token = page.PaginationToken
+		return items, bool(page.HasMore) && token != "", nil
+	})
+}
+
+// GetNotificationHistory calls [Service.GetNotificationHistory]
+// on a Service for client's environment.
+func GetNotificationHistory(ctx context.Context, client *Apple.Client, req NotificationHistoryRequest, paginationToken types.PaginationToken) (*NotificationHistoryResponse, error) {
+	return NewService(client).GetNotificationHistory(ctx, req, paginationToken)
+}
@@ -0,0 +1,95 @@
+package AppStoreServer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	AppStoreNotifications "github.com/godrealms/go-apple-sdk/app-store-server-notifications"
+	"github.com/godrealms/go-apple-sdk/internal/testchain"
+	"github.com/godrealms/go-apple-sdk/jws"
+	"github.com/godrealms/go-apple-sdk/types"
+)
+
+func TestNotificationHistoryRequest_Validate(t *testing.T) {
+	valid := NotificationHistoryRequest{StartDate: 1, EndDate: 2}
+	tests := map[string]struct {
+		req NotificationHistoryRequest
+		ok  bool
+	}{
+		"valid":            {valid, true},
+		"missing dates":    {NotificationHistoryRequest{}, false},
+		"end before start": {NotificationHistoryRequest{StartDate: 2, EndDate: 1}, false},
+		"two filters": {NotificationHistoryRequest{StartDate: 1, EndDate: 2, TransactionId: "1",
+			NotificationType: types.NOTIFICATION_TYPE_REFUND}, false},
+		"subtype alone": {NotificationHistoryRequest{StartDate: 1, EndDate: 2, NotificationSubtype: types.SUBTYPE_UPGRADE}, false},
+	}
+	for name, tt := range tests {
+		if err := tt.req.Validate(); (err == nil) != tt.ok {
+			t.Errorf("%s: Validate() = %v", name, err)
+		}
+	}
+}
+
+func TestService_NotificationHistory_AllPages(t *testing.T) {
+	var tokens []string
+	var bodies []NotificationHistoryRequest
+	var chain *testchain.Chain // set once the server exists
+	svc, tc := newVerifyingTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/inApps/v1/notifications/history" {
+			t.Errorf("request = %s %s", r.Method, r.URL.Path)
+		}
+		var body NotificationHistoryRequest
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		bodies = append(bodies, body)
+		token := r.URL.Query().Get("paginationToken")
+		tokens = append(tokens, token)
+
+		w.Header().Set("Content-Type", "application/json")
+		page := NotificationHistoryResponse{HasMore: token == "", PaginationToken: "page-2"}
+		uuid := map[string]string{"": "n-1", "page-2": "n-2"}[token]
+		page.NotificationHistory = []NotificationHistoryResponseItem{{
+			SignedPayload: AppStoreNotifications.SignedPayload(chain.SignJWS(t,
+				AppStoreNotifications.ResponseBodyV2DecodedPayload{NotificationUUID: types.UUID(uuid)})),
+			SendAttempts: []SendAttemptItem{{AttemptDate: 1, SendAttemptResult: types.SendAttemptResultTimedOut}},
+		}}
+		_ = json.NewEncoder(w).Encode(page)
+	}))
+	chain = tc
+
+	req := NotificationHistoryRequest{StartDate: 1000, EndDate: 2000, OnlyFailures: true}
+	all, err := svc.NotificationHistory(req).All(context.Background())
+	if err != nil {
+		t.Fatalf("NotificationHistory: %v", err)
+	}
+	if len(all) != 2 || all[0].Payload.NotificationUUID != "n-1" || all[1].Payload.NotificationUUID != "n-2" {
+		t.Fatalf("notifications = %+v", all)
+	}
+	if all[0].SendAttempts[0].SendAttemptResult != types.SendAttemptResultTimedOut {
+		t.Errorf("send attempts = %+v", all[0].SendAttempts)
+	}
+	if fmt.Sprint(tokens) != "[ page-2]" {
+		t.Errorf("pagination tokens = %q", tokens)
+	}
+	if bodies[1] != req {
+		t.Errorf("second page body = %+v, want the original filters", bodies[1])
+	}
+}
+
+func TestService_NotificationHistory_RejectsUnverifiedPayload(t *testing.T) {
+	svc, _ := newVerifyingTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"notificationHistory":[{"signedPayload":"eyJhbGciOiJFUzI1NiJ9.e30.c2ln"}],"hasMore":false}`))
+	}))
+	it := svc.NotificationHistory(NotificationHistoryRequest{StartDate: 1, EndDate: 2})
+	if it.Next(context.Background()) {
+		t.Fatal("yielded an unverified payload")
+	}
+	var ve *jws.VerificationError
+	if !errors.As(it.Err(), &ve) {
+		t.Errorf("err = %v, want *jws.VerificationError", it.Err())
+	}
+}
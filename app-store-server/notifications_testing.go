@@ -2,8 +2,12 @@ package AppStoreServer
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 
 	Apple "github.com/godrealms/go-apple-sdk"
+	AppStoreNotifications "github.com/godrealms/go-apple-sdk/app-store-server-notifications"
 	"github.com/godrealms/go-apple-sdk/types"
 )
 
@@ -11,11 +15,13 @@ type SendTestNotificationResponse struct {
 	TestNotificationToken string `json:"testNotificationToken"`
 }
 
+// SendAttemptItem The success or error information and the date the App Store server records
+// when it attempts to send a server notification to your server.
 type SendAttemptItem struct {
 	// The date the App Store server attempts to send the notification.
 	AttemptDate types.Timestamp `json:"attemptDate"`
 	// The success or error information the App Store server records when it attempts to send an App Store server notification to your server.
-	SendAttemptResult string `json:"sendAttemptResult"`
+	SendAttemptResult types.SendAttemptResult `json:"sendAttemptResult"`
 }
 
 type CheckTestNotificationResponse struct {
@@ -28,9 +34,8 @@ type CheckTestNotificationResponse struct {
 }
 
 // RequestTestNotification Ask App Store AppStoreServerAPI Notifications to send a test notification to your server.
-func RequestTestNotification(ctx context.Context, client *Apple.Client) (*SendTestNotificationResponse, error) {
+func (s *Service) RequestTestNotification(ctx context.Context) (*SendTestNotificationResponse, error) {
 	var result = new(SendTestNotificationResponse)
-	client.SetService(Apple.AppStoreServerClient)
 	params := Apple.RequestParams{
 		Ctx:    ctx,
 		Method: "POST",
@@ -40,17 +45,22 @@ func RequestTestNotification(ctx context.Context, client *Apple.Client) (*SendTe
 			"Accept": "application/json",
 		},
 	}
-	if err := client.Request(params); err != nil {
+	if err := s.request(params); err != nil {
 		return nil, err
 	}
 	return result, nil
 
 }
 
+// RequestTestNotification calls [Service.RequestTestNotification]
+// on a Service for client's environment.
+func RequestTestNotification(ctx context.Context, client *Apple.Client) (*SendTestNotificationResponse, error) {
+	return NewService(client).RequestTestNotification(ctx)
+}
+
 // GetTestNotificationStatus Check the status of the test App Store server notification sent to your server.
-func GetTestNotificationStatus(ctx context.Context, client *Apple.Client, testNotificationToken string) (*CheckTestNotificationResponse, error) {
+func (s *Service) GetTestNotificationStatus(ctx context.Context, testNotificationToken string) (*CheckTestNotificationResponse, error) {
 	var result = new(CheckTestNotificationResponse)
-	client.SetService(Apple.AppStoreServerClient)
 	params := Apple.RequestParams{
 		Ctx:    ctx,
 		Method: "GET",
@@ -64,8 +74,86 @@ func GetTestNotificationStatus(ctx context.Context, client *Apple.Client, testNo
 		},
 	}
 
-	if err := client.Request(params); err != nil {
+	if err := s.request(params); err != nil {
 		return nil, err
 	}
 	return result, nil
 }
+
+// GetTestNotificationStatus calls [Service.GetTestNotificationStatus]
+// on a Service for client's environment.
+func GetTestNotificationStatus(ctx context.Context, client *Apple.Client, testNotificationToken string) (*CheckTestNotificationResponse, error) {
+	return NewService(client).GetTestNotificationStatus(ctx, testNotificationToken)
+}
+
+// TestNotificationResult is the outcome of [Service.RoundTripTestNotification].
+type TestNotificationResult struct {
+	// TestNotificationToken identifies the TEST notification.
+	TestNotificationToken string
+	// Payload is the verified and decoded notification Apple sent.
+	Payload *AppStoreNotifications.ResponseBodyV2DecodedPayload
+	// SendAttempts lists Apple's attempts to deliver it to your server.
+	SendAttempts []SendAttemptItem
+}
+
+// Delivered reports whether Apple's latest send attempt succeeded.
+func (r *TestNotificationResult) Delivered() bool {
+	n := len(r.SendAttempts)
+	return n > 0 && r.SendAttempts[n-1].SendAttemptResult == types.SendAttemptResultSuccess
+}
+
+// DefaultTestNotificationTimeout bounds the polling of
+// [Service.RoundTripTestNotification] unless [WithTimeout] says
+// otherwise.
+const DefaultTestNotificationTimeout = 5 * time.Minute
+
+// RoundTripTestNotification requests a TEST notification, polls
+// [Service.GetTestNotificationStatus] until Apple records a send
+// attempt, and returns the verified payload with the attempts. Use it
+// after a deploy to check that your notification URL is reachable:
+//
+//	res, err := svc.RoundTripTestNotification(ctx,
+//	    AppStoreServer.WithPollInterval(2*time.Second, 10*time.Second),
+//	    AppStoreServer.WithTimeout(2*time.Minute))
+//	if err == nil && !res.Delivered() { ... }
+//
+// Polling gives up after [DefaultTestNotificationTimeout], or the
+// [WithTimeout] value, or when ctx is done, whichever comes first. A
+// TestNotificationNotFound (4040008) answer, which Apple returns until
+// it has indexed the new token, is polled through. The signed payload
+// is verified with the service's verifier and must be a TEST
+// notification.
+func (s *Service) RoundTripTestNotification(ctx context.Context, opts ...WaitOption) (*TestNotificationResult, error) {
+	o := newWaitOptions(opts)
+	if o.timeout == 0 {
+		o.timeout = DefaultTestNotificationTimeout
+	}
+	sent, err := s.RequestTestNotification(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var status *CheckTestNotificationResponse
+	err = pollUntil(ctx, o, func(ctx context.Context) (done bool, err error) {
+		status, err = s.GetTestNotificationStatus(ctx, sent.TestNotificationToken)
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.ErrorCode == ErrorCodeTestNotificationNotFound {
+			return false, nil
+		}
+		return err == nil && len(status.SendAttempts) > 0, err
+	}, sleepCtx)
+	if err != nil {
+		return nil, fmt.Errorf("app store server: test notification %s: %w", sent.TestNotificationToken, err)
+	}
+	payload, err := AppStoreNotifications.SignedPayload(status.SignedPayload).DecodedPayloadWith(s.jwsVerifier())
+	if err != nil {
+		return nil, fmt.Errorf("app store server: test notification %s: %w", sent.TestNotificationToken, err)
+	}
+	if payload.NotificationType != types.NOTIFICATION_TYPE_TEST {
+		return nil, fmt.Errorf("app store server: test notification %s: unexpected notificationType %q", sent.TestNotificationToken, payload.NotificationType)
+	}
+	return &TestNotificationResult{
+		TestNotificationToken: sent.TestNotificationToken,
+		Payload:               payload,
+		SendAttempts:          status.SendAttempts,
+	}, nil
+}
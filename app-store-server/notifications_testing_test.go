@@ -0,0 +1,130 @@
+package AppStoreServer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	AppStoreNotifications "github.com/godrealms/go-apple-sdk/app-store-server-notifications"
+	"github.com/godrealms/go-apple-sdk/internal/testchain"
+	"github.com/godrealms/go-apple-sdk/types"
+)
+
+// testNotificationServer hands out token "tok" and reports attempts
+// once the status endpoint has been polled pending times.
+func testNotificationServer(t *testing.T, chain **testchain.Chain, pending int32, notificationType types.NotificationType, result types.SendAttemptResult) http.Handler {
+	var polls atomic.Int32
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/inApps/v1/notifications/test":
+			_, _ = w.Write([]byte(`{"testNotificationToken":"tok"}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/inApps/v1/notifications/test/tok":
+			resp := CheckTestNotificationResponse{
+				SignedPayload: (*chain).SignJWS(t, AppStoreNotifications.ResponseBodyV2DecodedPayload{
+					NotificationType: notificationType,
+					NotificationUUID: "n-1",
+				}),
+			}
+			if polls.Add(1) > pending {
+				resp.SendAttempts = []SendAttemptItem{{AttemptDate: 1, SendAttemptResult: result}}
+			}
+			_ = json.NewEncoder(w).Encode(resp)
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+}
+
+func TestService_RoundTripTestNotification(t *testing.T) {
+	var chain *testchain.Chain
+	svc, tc := newVerifyingTestService(t, testNotificationServer(t, &chain, 2, types.NOTIFICATION_TYPE_TEST, types.SendAttemptResultSuccess))
+	chain = tc
+
+	res, err := svc.RoundTripTestNotification(context.Background(), WithPollInterval(time.Millisecond, time.Millisecond))
+	if err != nil {
+		t.Fatalf("RoundTripTestNotification: %v", err)
+	}
+	if res.TestNotificationToken != "tok" || res.Payload.NotificationUUID != "n-1" || !res.Delivered() {
+		t.Errorf("result = %+v", res)
+	}
+}
+
+func TestService_RoundTripTestNotification_ReportsFailedDelivery(t *testing.T) {
+	var chain *testchain.Chain
+	svc, tc := newVerifyingTestService(t, testNotificationServer(t, &chain, 0, types.NOTIFICATION_TYPE_TEST, types.SendAttemptResultTLSIssue))
+	chain = tc
+
+	res, err := svc.RoundTripTestNotification(context.Background(), WithPollInterval(time.Millisecond, time.Millisecond))
+	if err != nil {
+		t.Fatalf("RoundTripTestNotification: %v", err)
+	}
+	if res.Delivered() || res.SendAttempts[0].SendAttemptResult != types.SendAttemptResultTLSIssue {
+		t.Errorf("attempts = %+v", res.SendAttempts)
+	}
+}
+
+func TestService_RoundTripTestNotification_Errors(t *testing.T) {
+	var chain *testchain.Chain
+	svc, tc := newVerifyingTestService(t, testNotificationServer(t, &chain, 0, types.NOTIFICATION_TYPE_REFUND, types.SendAttemptResultSuccess))
+	chain = tc
+	if _, err := svc.RoundTripTestNotification(context.Background(), WithPollInterval(time.Millisecond, time.Millisecond)); err == nil {
+		t.Error("non-TEST payload accepted")
+	}
+
+	never := int32(1 << 30)
+	svc, tc = newVerifyingTestService(t, testNotificationServer(t, &chain, never, types.NOTIFICATION_TYPE_TEST, types.SendAttemptResultSuccess))
+	chain = tc
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	if _, err := svc.RoundTripTestNotification(ctx, WithPollInterval(time.Millisecond, 5*time.Millisecond)); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestService_RoundTripTestNotification_PollsThroughNotFound(t *testing.T) {
+	var chain *testchain.Chain
+	inner := testNotificationServer(t, &chain, 0, types.NOTIFICATION_TYPE_TEST, types.SendAttemptResultSuccess)
+	var misses atomic.Int32
+	svc, tc := newVerifyingTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Apple has not indexed the token for the first two polls.
+		if r.Method == http.MethodGet && misses.Add(1) <= 2 {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errorCode":4040008,"errorMessage":"Test notification not found."}`))
+			return
+		}
+		inner.ServeHTTP(w, r)
+	}))
+	chain = tc
+
+	res, err := svc.RoundTripTestNotification(context.Background(), WithPollInterval(time.Millisecond, time.Millisecond))
+	if err != nil {
+		t.Fatalf("RoundTripTestNotification: %v", err)
+	}
+	if !res.Delivered() || misses.Load() != 3 {
+		t.Errorf("result = %+v after %d polls", res, misses.Load())
+	}
+}
+
+func TestService_RoundTripTestNotification_DefaultTimeout(t *testing.T) {
+	var chain *testchain.Chain
+	never := int32(1 << 30)
+	svc, tc := newVerifyingTestService(t, testNotificationServer(t, &chain, never, types.NOTIFICATION_TYPE_TEST, types.SendAttemptResultSuccess))
+	chain = tc
+
+	// No deadline on ctx: WithTimeout alone ends the wait.
+	_, err := svc.RoundTripTestNotification(context.Background(),
+		WithPollInterval(time.Millisecond, 5*time.Millisecond), WithTimeout(30*time.Millisecond))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want context.DeadlineExceeded", err)
+	}
+	if o := newWaitOptions(nil); o.timeout != 0 {
+		t.Errorf("default wait timeout = %v, want none outside RoundTripTestNotification", o.timeout)
+	}
+}
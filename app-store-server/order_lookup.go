@@ -15,8 +15,7 @@ type OrderLookupResponse struct {
 }
 
 // LookUpOrderID Get a customer’s in-app purchases from a receipt using the order ID.
-func LookUpOrderID(ctx context.Context, client *Apple.Client, orderId string) (*OrderLookupResponse, error) {
-	client.SetService(Apple.AppStoreServerClient)
+func (s *Service) LookUpOrderID(ctx context.Context, orderId string) (*OrderLookupResponse, error) {
 	var result = new(OrderLookupResponse)
 	params := Apple.RequestParams{
 		Ctx:    ctx,
@@ -30,8 +29,14 @@ func LookUpOrderID(ctx context.Context, client *Apple.Client, orderId string) (*
 			"orderId": orderId,
 		},
 	}
-	if err := client.Request(params); err != nil {
+	if err := s.request(params); err != nil {
 		return nil, err
 	}
 	return result, nil
 }
+
+// LookUpOrderID calls [Service.LookUpOrderID]
+// on a Service for client's environment.
+func LookUpOrderID(ctx context.Context, client *Apple.Client, orderId string) (*OrderLookupResponse, error) {
+	return NewService(client).LookUpOrderID(ctx, orderId)
+}
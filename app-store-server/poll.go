@@ -0,0 +1,96 @@
+package AppStoreServer
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/godrealms/go-apple-sdk/internal/backoff"
+	"github.com/godrealms/go-apple-sdk/types"
+)
+
+// WaitOption configures the helpers that poll Apple until an
+// asynchronous operation finishes, such as [Service.WaitForMassExtension].
+type WaitOption func(*waitOptions)
+
+type waitOptions struct {
+	initial, max time.Duration
+	timeout      time.Duration
+	summaries    <-chan types.Summary
+}
+
+func newWaitOptions(opts []WaitOption) waitOptions {
+	o := waitOptions{initial: 10 * time.Second, max: 5 * time.Minute}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.initial <= 0 {
+		o.initial = time.Second
+	}
+	if o.max < o.initial {
+		o.max = o.initial
+	}
+	return o
+}
+
+// WithPollInterval sets the first delay between status checks and the
+// cap it doubles up to. The defaults are 10 seconds and 5 minutes.
+func WithPollInterval(initial, max time.Duration) WaitOption {
+	return func(o *waitOptions) {
+		o.initial, o.max = initial, max
+	}
+}
+
+// WithTimeout bounds how long a helper keeps polling, on top of any
+// deadline on ctx. Zero keeps the helper's default: none for
+// [Service.WaitForMassExtension], [DefaultTestNotificationTimeout] for
+// [Service.RoundTripTestNotification].
+func WithTimeout(d time.Duration) WaitOption {
+	return func(o *waitOptions) { o.timeout = d }
+}
+
+// pollUntil calls check until it reports done, waiting between calls
+// with a delay that doubles up to o.max. A retryable [*APIError] from
+// check is waited out (honoring Retry-After); any other error is
+// returned. wait sleeps for the given delay and may report done early.
+// A positive o.timeout bounds the whole loop.
+func pollUntil(ctx context.Context, o waitOptions, check func(context.Context) (bool, error), wait func(context.Context, time.Duration) bool) error {
+	if o.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, o.timeout)
+		defer cancel()
+	}
+	interval := o.initial
+	for {
+		delay := interval
+		done, err := check(ctx)
+		switch {
+		case err == nil && done:
+			return nil
+		case err != nil:
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) || !apiErr.IsRetryable() {
+				return err
+			}
+			if apiErr.RetryAfter > delay {
+				delay = apiErr.RetryAfter
+			}
+		}
+
+		if wait(ctx, delay) {
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if interval *= 2; interval > o.max {
+			interval = o.max
+		}
+	}
+}
+
+// sleepCtx is the wait function for pollers with nothing else to watch.
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+	_ = backoff.Sleep(ctx, d)
+	return false
+}
@@ -23,8 +23,9 @@ type RefundHistoryResponse struct {
 }
 
 // GetRefundHistory Get a paginated list of all of a customer’s refunded in-app purchases for your app.
-func GetRefundHistory(ctx context.Context, client *Apple.Client, transactionId string) (*RefundHistoryResponse, error) {
-	client.SetService(Apple.AppStoreServerClient)
+// revision: The token from the previous RefundHistoryResponse; leave it empty for the first page.
+// Use [Service.RefundHistory] to walk every page.
+func (s *Service) GetRefundHistory(ctx context.Context, transactionId string, revision types.Revision) (*RefundHistoryResponse, error) {
 	var result = new(RefundHistoryResponse)
 	params := Apple.RequestParams{
 		Ctx:    ctx,
@@ -38,8 +39,39 @@ func GetRefundHistory(ctx context.Context, client *Apple.Client, transactionId s
 			"transactionId": transactionId,
 		},
 	}
-	if err := client.Request(params); err != nil {
+	if revision != "" {
+		params.QueryParams = map[string]any{"revision": string(revision)}
+	}
+	if err := s.request(params); err != nil {
 		return nil, err
 	}
 	return result, nil
 }
+
+// RefundHistory returns an iterator over every refunded transaction
+// of the customer, following revision tokens until Apple reports no
+// more. Apple returns at most 20 per page, so a single
+// [Service.GetRefundHistory] call can truncate the record.
+func (s *Service) RefundHistory(transactionId string) *Iterator[types.JWSTransaction] {
+	var revision types.Revision
+	return newIterator(func(ctx context.Context) ([]types.JWSTransaction, bool, error) {
+		page, err := s.GetRefundHistory(ctx, transactionId, revision)
+		if err != nil {
+			return nil, false, err
+		}
+		revision = page.Revision
+		return page.SignedTransactions, bool(page.HasMore) && revision != "", nil
+	})
+}
+
+// DecodedRefundHistory is [Service.RefundHistory] with each transaction
+// verified and decoded by the service's verifier.
+func (s *Service) DecodedRefundHistory(transactionId string) *Iterator[*types.JWSTransactionDecodedPayload] {
+	return s.decodeTransactions(s.RefundHistory(transactionId))
+}
+
+// GetRefundHistory calls [Service.GetRefundHistory]
+// on a Service for client's environment.
+func GetRefundHistory(ctx context.Context, client *Apple.Client, transactionId string, revision types.Revision) (*RefundHistoryResponse, error) {
+	return NewService(client).GetRefundHistory(ctx, transactionId, revision)
+}
@@ -0,0 +1,53 @@
+package AppStoreServer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/godrealms/go-apple-sdk/internal/testchain"
+	"github.com/godrealms/go-apple-sdk/types"
+)
+
+func TestService_DecodedRefundHistory_FollowsRevisions(t *testing.T) {
+	var chain *testchain.Chain
+	var revisions []string
+	svc, tc := newVerifyingTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/inApps/v2/refund/lookup/orig-1" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		rev := r.URL.Query().Get("revision")
+		revisions = append(revisions, rev)
+
+		// 20 refunds on the first page, one on the second.
+		count, page := 20, RefundHistoryResponse{HasMore: true, Revision: "next"}
+		if rev == "next" {
+			count, page = 1, RefundHistoryResponse{Revision: "end"}
+		}
+		for i := 0; i < count; i++ {
+			id := types.TransactionId(fmt.Sprintf("%s-%d", rev, i))
+			page.SignedTransactions = append(page.SignedTransactions,
+				types.JWSTransaction(chain.SignJWS(t, types.JWSTransactionDecodedPayload{TransactionId: id})))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(page)
+	}))
+	chain = tc
+
+	all, err := svc.DecodedRefundHistory("orig-1").All(context.Background())
+	if err != nil {
+		t.Fatalf("DecodedRefundHistory: %v", err)
+	}
+	if len(all) != 21 {
+		t.Fatalf("got %d refunds, want 21", len(all))
+	}
+	if all[0].TransactionId != "-0" || all[20].TransactionId != "next-0" {
+		t.Errorf("order = %q ... %q", all[0].TransactionId, all[20].TransactionId)
+	}
+	if !reflect.DeepEqual(revisions, []string{"", "next"}) {
+		t.Errorf("revisions requested = %q", revisions)
+	}
+}
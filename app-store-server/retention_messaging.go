@@ -0,0 +1,306 @@
+package AppStoreServer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"unicode/utf8"
+
+	Apple "github.com/godrealms/go-apple-sdk"
+	"github.com/godrealms/go-apple-sdk/types"
+)
+
+// Limits Apple documents for retention message content.
+const (
+	MaxRetentionMessageHeaderLength = 66
+	MaxRetentionMessageBodyLength   = 144
+	MaxRetentionImageAltTextLength  = 150
+	retentionImageContentType       = "image/png"
+)
+
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// RetentionContentState The review state of an uploaded retention message or image.
+type RetentionContentState string
+
+const (
+	RetentionContentStatePending  RetentionContentState = "PENDING"  // Apple is reviewing the content.
+	RetentionContentStateApproved RetentionContentState = "APPROVED" // The content may be shown to customers.
+	RetentionContentStateRejected RetentionContentState = "REJECTED" // Apple rejected the content.
+)
+
+// UploadMessageImage The image a retention message displays.
+type UploadMessageImage struct {
+	// The identifier of an image you uploaded with Upload Image.
+	ImageIdentifier types.UUID `json:"imageIdentifier"`
+	// The alternative text for the image, for accessibility.
+	AltText string `json:"altText"`
+}
+
+// UploadMessageRequestBody The request body for uploading a retention message.
+type UploadMessageRequestBody struct {
+	// (Required) The header text of the retention message.
+	Header string `json:"header"`
+	// (Required) The body text of the retention message.
+	Body string `json:"body"`
+	// An optional image to show with the message.
+	Image *UploadMessageImage `json:"image,omitempty"`
+}
+
+// Validate checks the required fields and Apple's length limits.
+func (r *UploadMessageRequestBody) Validate() error {
+	switch {
+	case r == nil:
+		return errors.New("app store server: retention message: request is nil")
+	case r.Header == "" || r.Body == "":
+		return errors.New("app store server: retention message: header and body are required")
+	case utf8.RuneCountInString(r.Header) > MaxRetentionMessageHeaderLength:
+		return fmt.Errorf("app store server: retention message: header longer than %d characters", MaxRetentionMessageHeaderLength)
+	case utf8.RuneCountInString(r.Body) > MaxRetentionMessageBodyLength:
+		return fmt.Errorf("app store server: retention message: body longer than %d characters", MaxRetentionMessageBodyLength)
+	}
+	if img := r.Image; img != nil {
+		if !img.ImageIdentifier.IsValidUUID() {
+			return fmt.Errorf("app store server: retention message: imageIdentifier %q is not a UUID", img.ImageIdentifier)
+		}
+		if img.AltText == "" || utf8.RuneCountInString(img.AltText) > MaxRetentionImageAltTextLength {
+			return fmt.Errorf("app store server: retention message: altText must be 1–%d characters", MaxRetentionImageAltTextLength)
+		}
+	}
+	return nil
+}
+
+// GetMessageListResponseItem A retention message identifier and its review state.
+type GetMessageListResponseItem struct {
+	MessageIdentifier types.UUID            `json:"messageIdentifier"`
+	MessageState      RetentionContentState `json:"messageState"`
+}
+
+// GetMessageListResponse A response that contains the retention messages you uploaded.
+type GetMessageListResponse struct {
+	MessageIdentifiers []GetMessageListResponseItem `json:"messageIdentifiers"`
+}
+
+// GetImageListResponseItem A retention image identifier and its review state.
+type GetImageListResponseItem struct {
+	ImageIdentifier types.UUID            `json:"imageIdentifier"`
+	ImageState      RetentionContentState `json:"imageState"`
+}
+
+// GetImageListResponse A response that contains the retention images you uploaded.
+type GetImageListResponse struct {
+	ImageIdentifiers []GetImageListResponseItem `json:"imageIdentifiers"`
+}
+
+// DefaultConfigurationRequest The request body for configuring the default retention message
+// for a product and locale.
+type DefaultConfigurationRequest struct {
+	// The identifier of an approved message to show by default.
+	MessageIdentifier types.UUID `json:"messageIdentifier"`
+}
+
+func requireUUID(what string, id types.UUID) error {
+	if !id.IsValidUUID() {
+		return fmt.Errorf("app store server: retention messaging: %s %q is not a UUID", what, id)
+	}
+	return nil
+}
+
+// UploadImage Upload a PNG image for use in retention messages. imageIdentifier is a UUID you choose.
+func (s *Service) UploadImage(ctx context.Context, imageIdentifier types.UUID, png []byte) error {
+	if err := requireUUID("imageIdentifier", imageIdentifier); err != nil {
+		return err
+	}
+	if !bytes.HasPrefix(png, pngSignature) {
+		return errors.New("app store server: retention messaging: image is not a PNG")
+	}
+	return s.request(Apple.RequestParams{
+		Ctx:    ctx,
+		Method: "PUT",
+		Path:   "/inApps/v1/messaging/image/{imageIdentifier}",
+		Body:   png,
+		Headers: map[string]string{
+			"Content-Type": retentionImageContentType,
+		},
+		PathParams: map[string]string{
+			"imageIdentifier": string(imageIdentifier),
+		},
+	})
+}
+
+// DeleteImage Delete a previously uploaded image.
+func (s *Service) DeleteImage(ctx context.Context, imageIdentifier types.UUID) error {
+	if err := requireUUID("imageIdentifier", imageIdentifier); err != nil {
+		return err
+	}
+	return s.request(Apple.RequestParams{
+		Ctx:    ctx,
+		Method: "DELETE",
+		Path:   "/inApps/v1/messaging/image/{imageIdentifier}",
+		PathParams: map[string]string{
+			"imageIdentifier": string(imageIdentifier),
+		},
+	})
+}
+
+// GetImageList Get the identifier and review state of all uploaded images.
+func (s *Service) GetImageList(ctx context.Context) (*GetImageListResponse, error) {
+	var result = new(GetImageListResponse)
+	params := Apple.RequestParams{
+		Ctx:    ctx,
+		Method: "GET",
+		Path:   "/inApps/v1/messaging/image/list",
+		Result: result,
+		Headers: map[string]string{
+			"Accept": "application/json",
+		},
+	}
+	if err := s.request(params); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// UploadMessage Upload a retention message. messageIdentifier is a UUID you choose.
+func (s *Service) UploadMessage(ctx context.Context, messageIdentifier types.UUID, body *UploadMessageRequestBody) error {
+	if err := requireUUID("messageIdentifier", messageIdentifier); err != nil {
+		return err
+	}
+	if err := body.Validate(); err != nil {
+		return err
+	}
+	return s.request(Apple.RequestParams{
+		Ctx:    ctx,
+		Method: "PUT",
+		Path:   "/inApps/v1/messaging/message/{messageIdentifier}",
+		Body:   body,
+		Headers: map[string]string{
+			"Content-Type": "application/json",
+		},
+		PathParams: map[string]string{
+			"messageIdentifier": string(messageIdentifier),
+		},
+	})
+}
+
+// DeleteMessage Delete a previously uploaded message.
+func (s *Service) DeleteMessage(ctx context.Context, messageIdentifier types.UUID) error {
+	if err := requireUUID("messageIdentifier", messageIdentifier); err != nil {
+		return err
+	}
+	return s.request(Apple.RequestParams{
+		Ctx:    ctx,
+		Method: "DELETE",
+		Path:   "/inApps/v1/messaging/message/{messageIdentifier}",
+		PathParams: map[string]string{
+			"messageIdentifier": string(messageIdentifier),
+		},
+	})
+}
+
+// GetMessageList Get the identifier and review state of all uploaded messages.
+func (s *Service) GetMessageList(ctx context.Context) (*GetMessageListResponse, error) {
+	var result = new(GetMessageListResponse)
+	params := Apple.RequestParams{
+		Ctx:    ctx,
+		Method: "GET",
+		Path:   "/inApps/v1/messaging/message/list",
+		Result: result,
+		Headers: map[string]string{
+			"Accept": "application/json",
+		},
+	}
+	if err := s.request(params); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ConfigureDefaultMessage Set the message shown by default, when your real-time endpoint does not
+// choose one, for a subscription product and locale (for example "en-US").
+func (s *Service) ConfigureDefaultMessage(ctx context.Context, productId types.ProductId, locale string, messageIdentifier types.UUID) error {
+	if err := requireUUID("messageIdentifier", messageIdentifier); err != nil {
+		return err
+	}
+	if productId == "" || locale == "" {
+		return errors.New("app store server: retention messaging: productId and locale are required")
+	}
+	return s.request(Apple.RequestParams{
+		Ctx:    ctx,
+		Method: "PUT",
+		Path:   "/inApps/v1/messaging/default/{productId}/{locale}",
+		Body:   &DefaultConfigurationRequest{MessageIdentifier: messageIdentifier},
+		Headers: map[string]string{
+			"Content-Type": "application/json",
+		},
+		PathParams: map[string]string{
+			"productId": string(productId),
+			"locale":    locale,
+		},
+	})
+}
+
+// DeleteDefaultMessage Remove the default message configuration for a product and locale.
+func (s *Service) DeleteDefaultMessage(ctx context.Context, productId types.ProductId, locale string) error {
+	if productId == "" || locale == "" {
+		return errors.New("app store server: retention messaging: productId and locale are required")
+	}
+	return s.request(Apple.RequestParams{
+		Ctx:    ctx,
+		Method: "DELETE",
+		Path:   "/inApps/v1/messaging/default/{productId}/{locale}",
+		PathParams: map[string]string{
+			"productId": string(productId),
+			"locale":    locale,
+		},
+	})
+}
+
+// UploadImage calls [Service.UploadImage]
+// on a Service for client's environment.
+func UploadImage(ctx context.Context, client *Apple.Client, imageIdentifier types.UUID, png []byte) error {
+	return NewService(client).UploadImage(ctx, imageIdentifier, png)
+}
+
+// DeleteImage calls [Service.DeleteImage]
+// on a Service for client's environment.
+func DeleteImage(ctx context.Context, client *Apple.Client, imageIdentifier types.UUID) error {
+	return NewService(client).DeleteImage(ctx, imageIdentifier)
+}
+
+// GetImageList calls [Service.GetImageList]
+// on a Service for client's environment.
+func GetImageList(ctx context.Context, client *Apple.Client) (*GetImageListResponse, error) {
+	return NewService(client).GetImageList(ctx)
+}
+
+// UploadMessage calls [Service.UploadMessage]
+// on a Service for client's environment.
+func UploadMessage(ctx context.Context, client *Apple.Client, messageIdentifier types.UUID, body *UploadMessageRequestBody) error {
+	return NewService(client).UploadMessage(ctx, messageIdentifier, body)
+}
+
+// DeleteMessage calls [Service.DeleteMessage]
+// on a Service for client's environment.
+func DeleteMessage(ctx context.Context, client *Apple.Client, messageIdentifier types.UUID) error {
+	return NewService(client).DeleteMessage(ctx, messageIdentifier)
+}
+
+// GetMessageList calls [Service.GetMessageList]
+// on a Service for client's environment.
+func GetMessageList(ctx context.Context, client *Apple.Client) (*GetMessageListResponse, error) {
+	return NewService(client).GetMessageList(ctx)
+}
+
+// ConfigureDefaultMessage calls [Service.ConfigureDefaultMessage]
+// on a Service for client's environment.
+func ConfigureDefaultMessage(ctx context.Context, client *Apple.Client, productId types.ProductId, locale string, messageIdentifier types.UUID) error {
+	return NewService(client).ConfigureDefaultMessage(ctx, productId, locale, messageIdentifier)
+}
+
+// DeleteDefaultMessage calls [Service.DeleteDefaultMessage]
+// on a Service for client's environment.
+func DeleteDefaultMessage(ctx context.Context, client *Apple.Client, productId types.ProductId, locale string) error {
+	return NewService(client).DeleteDefaultMessage(ctx, productId, locale)
+}
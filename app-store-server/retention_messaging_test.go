@@ -0,0 +1,176 @@
+package AppStoreServer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/godrealms/go-apple-sdk/internal/testchain"
+	"github.com/godrealms/go-apple-sdk/jws"
+	"github.com/godrealms/go-apple-sdk/types"
+)
+
+const (
+	testImageID   types.UUID = "a1b2c3d4-0000-4000-8000-000000000001"
+	testMessageID types.UUID = "a1b2c3d4-0000-4000-8000-000000000002"
+)
+
+type recordedRequest struct {
+	Method, Path, ContentType string
+	Body                      []byte
+}
+
+func TestService_RetentionMessagingEndpoints(t *testing.T) {
+	var mu sync.Mutex
+	var got []recordedRequest
+	svc, _ := newTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		got = append(got, recordedRequest{r.Method, r.URL.Path, r.Header.Get("Content-Type"), body})
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/inApps/v1/messaging/image/list":
+			_, _ = w.Write([]byte(`{"imageIdentifiers":[{"imageIdentifier":"` + string(testImageID) + `","imageState":"APPROVED"}]}`))
+		case "/inApps/v1/messaging/message/list":
+			_, _ = w.Write([]byte(`{"messageIdentifiers":[{"messageIdentifier":"` + string(testMessageID) + `","messageState":"PENDING"}]}`))
+		}
+	}))
+	ctx := context.Background()
+	png := append([]byte("\x89PNG\r\n\x1a\n"), 0, 1, 2)
+
+	if err := svc.UploadImage(ctx, testImageID, png); err != nil {
+		t.Fatalf("UploadImage: %v", err)
+	}
+	msg := &UploadMessageRequestBody{Header: "Wait!", Body: "Stay for 50% off.", Image: &UploadMessageImage{ImageIdentifier: testImageID, AltText: "A gift"}}
+	if err := svc.UploadMessage(ctx, testMessageID, msg); err != nil {
+		t.Fatalf("UploadMessage: %v", err)
+	}
+	images, err := svc.GetImageList(ctx)
+	if err != nil || len(images.ImageIdentifiers) != 1 || images.ImageIdentifiers[0].ImageState != RetentionContentStateApproved {
+		t.Errorf("GetImageList = %+v, %v", images, err)
+	}
+	messages, err := svc.GetMessageList(ctx)
+	if err != nil || len(messages.MessageIdentifiers) != 1 || messages.MessageIdentifiers[0].MessageState != RetentionContentStatePending {
+		t.Errorf("GetMessageList = %+v, %v", messages, err)
+	}
+	if err := svc.ConfigureDefaultMessage(ctx, "monthly", "en-US", testMessageID); err != nil {
+		t.Fatalf("ConfigureDefaultMessage: %v", err)
+	}
+	for _, err := range []error{
+		svc.DeleteDefaultMessage(ctx, "monthly", "en-US"),
+		svc.DeleteMessage(ctx, testMessageID),
+		svc.DeleteImage(ctx, testImageID),
+	} {
+		if err != nil {
+			t.Fatalf("delete: %v", err)
+		}
+	}
+
+	want := []struct{ method, path string }{
+		{"PUT", "/inApps/v1/messaging/image/" + string(testImageID)},
+		{"PUT", "/inApps/v1/messaging/message/" + string(testMessageID)},
+		{"GET", "/inApps/v1/messaging/image/list"},
+		{"GET", "/inApps/v1/messaging/message/list"},
+		{"PUT", "/inApps/v1/messaging/default/monthly/en-US"},
+		{"DELETE", "/inApps/v1/messaging/default/monthly/en-US"},
+		{"DELETE", "/inApps/v1/messaging/message/" + string(testMessageID)},
+		{"DELETE", "/inApps/v1/messaging/image/" + string(testImageID)},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d requests, want %d", len(got), len(want))
+	}
+	for i, w := range want {
+		if got[i].Method != w.method || got[i].Path != w.path {
+			t.Errorf("request %d = %s %s, want %s %s", i, got[i].Method, got[i].Path, w.method, w.path)
+		}
+	}
+	if got[0].ContentType != "image/png" || !bytes.Equal(got[0].Body, png) {
+		t.Errorf("image upload = %q %q", got[0].ContentType, got[0].Body)
+	}
+	var sent UploadMessageRequestBody
+	if err := json.Unmarshal(got[1].Body, &sent); err != nil || sent.Image == nil || sent.Image.AltText != "A gift" {
+		t.Errorf("message body = %s", got[1].Body)
+	}
+	if !strings.Contains(string(got[4].Body), string(testMessageID)) {
+		t.Errorf("default configuration body = %s", got[4].Body)
+	}
+}
+
+func TestService_RetentionMessagingValidation(t *testing.T) {
+	svc, _ := newTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("invalid input reached the server: %s %s", r.Method, r.URL.Path)
+	}))
+	ctx := context.Background()
+	cases := map[string]error{
+		"non-UUID image":  svc.UploadImage(ctx, "img", []byte("\x89PNG\r\n\x1a\n")),
+		"non-PNG image":   svc.UploadImage(ctx, testImageID, []byte("GIF89a")),
+		"empty message":   svc.UploadMessage(ctx, testMessageID, &UploadMessageRequestBody{}),
+		"long header":     svc.UploadMessage(ctx, testMessageID, &UploadMessageRequestBody{Header: strings.Repeat("é", 67), Body: "b"}),
+		"image no alt":    svc.UploadMessage(ctx, testMessageID, &UploadMessageRequestBody{Header: "h", Body: "b", Image: &UploadMessageImage{ImageIdentifier: testImageID}}),
+		"default no lang": svc.ConfigureDefaultMessage(ctx, "monthly", "", testMessageID),
+	}
+	for name, err := range cases {
+		if err == nil {
+			t.Errorf("%s: accepted", name)
+		}
+	}
+	if err := (&UploadMessageRequestBody{Header: strings.Repeat("é", 66), Body: "b"}).Validate(); err != nil {
+		t.Errorf("66-character header rejected: %v", err)
+	}
+}
+
+func TestRealtimeHandler(t *testing.T) {
+	tc := testchain.New(t)
+	v := jws.NewVerifier(jws.WithRootCAs(tc.RootPool), jws.WithRequiredOIDs(jws.OIDAppleReceiptSigning))
+	var seen *RealtimeRequestBody
+	h := NewRealtimeHandler(v, MessageSelectorFunc(func(ctx context.Context, req *RealtimeRequestBody) (*RealtimeResponseBody, error) {
+		seen = req
+		if req.ProductId == "none" {
+			return nil, nil
+		}
+		return &RealtimeResponseBody{Message: &RealtimeResponseMessage{MessageIdentifier: testMessageID}}, nil
+	}))
+	post := func(body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/retention", strings.NewReader(body)))
+		return rec
+	}
+	signed := func(chain *testchain.Chain, productId types.ProductId) string {
+		payload := chain.SignJWS(t, RealtimeRequestBody{OriginalTransactionId: "1000", ProductId: productId, UserLocale: "en-US"})
+		return `{"signedPayload":"` + payload + `"}`
+	}
+
+	rec := post(signed(tc, "monthly"))
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("status = %d, body %s", rec.Code, rec.Body)
+	}
+	var resp RealtimeResponseBody
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || resp.Message == nil || resp.Message.MessageIdentifier != testMessageID {
+		t.Errorf("response = %s", rec.Body)
+	}
+	if seen == nil || seen.OriginalTransactionId != "1000" || seen.UserLocale != "en-US" {
+		t.Errorf("selector saw %+v", seen)
+	}
+
+	if rec := post(signed(tc, "none")); rec.Code != http.StatusOK || strings.TrimSpace(rec.Body.String()) != "{}" {
+		t.Errorf("empty selection = %d %s", rec.Code, rec.Body)
+	}
+	if rec := post(signed(testchain.New(t), "monthly")); rec.Code != http.StatusUnauthorized {
+		t.Errorf("foreign chain status = %d", rec.Code)
+	}
+	if rec := post(`{"signedPayload":""}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("malformed status = %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/retention", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET status = %d", rec.Code)
+	}
+}
@@ -0,0 +1,132 @@
+package AppStoreServer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/godrealms/go-apple-sdk/jws"
+	"github.com/godrealms/go-apple-sdk/types"
+)
+
+// maxRealtimeRequestSize bounds the body the real-time handler reads;
+// Apple's signed request is a few kilobytes.
+const maxRealtimeRequestSize = 64 << 10
+
+// RealtimeRequestBody The decoded payload Apple sends to your real-time endpoint when a subscriber
+// opens the cancellation flow.
+type RealtimeRequestBody struct {
+	// The original transaction identifier of the customer’s subscription.
+	OriginalTransactionId types.OriginalTransactionId `json:"originalTransactionId"`
+	// The unique identifier of the app in the App Store.
+	AppAppleId types.AppAppleId `json:"appAppleId"`
+	// The product identifier of the subscription the customer is cancelling.
+	ProductId types.ProductId `json:"productId"`
+	// The locale of the customer’s device, for example "en-US".
+	UserLocale string `json:"userLocale"`
+	// A UUID Apple assigns to this request.
+	RequestIdentifier types.UUID `json:"requestIdentifier"`
+	// The server environment, either sandbox or production.
+	Environment types.Environment `json:"environment"`
+	// The UNIX time, in milliseconds, that Apple signed the request.
+	SignedDate types.Timestamp `json:"signedDate"`
+}
+
+// RealtimeResponseMessage Selects a retention message by identifier.
+type RealtimeResponseMessage struct {
+	MessageIdentifier types.UUID `json:"messageIdentifier"`
+}
+
+// RealtimeResponseAlternateProduct Suggests switching to another product, with a message.
+type RealtimeResponseAlternateProduct struct {
+	MessageIdentifier types.UUID      `json:"messageIdentifier"`
+	ProductId         types.ProductId `json:"productId"`
+}
+
+// RealtimeResponseBody The response your real-time endpoint returns. Set at most one field;
+// an empty response lets Apple show the default message, if configured.
+type RealtimeResponseBody struct {
+	Message          *RealtimeResponseMessage          `json:"message,omitempty"`
+	AlternateProduct *RealtimeResponseAlternateProduct `json:"alternateProduct,omitempty"`
+}
+
+// MessageSelector chooses the retention message for a verified
+// real-time request. Returning (nil, nil) sends an empty response.
+type MessageSelector interface {
+	SelectMessage(ctx context.Context, req *RealtimeRequestBody) (*RealtimeResponseBody, error)
+}
+
+// MessageSelectorFunc adapts a function to [MessageSelector].
+type MessageSelectorFunc func(ctx context.Context, req *RealtimeRequestBody) (*RealtimeResponseBody, error)
+
+// SelectMessage implements [MessageSelector].
+func (f MessageSelectorFunc) SelectMessage(ctx context.Context, req *RealtimeRequestBody) (*RealtimeResponseBody, error) {
+	return f(ctx, req)
+}
+
+// RealtimeHandler is an http.Handler for the Retention Messaging
+// real-time endpoint. It accepts only POST, verifies the signedPayload
+// in Apple's request body, asks the selector for a message and writes
+// the JSON response.
+//
+// Requests that are malformed get 400, requests whose signature or
+// certificate chain fails verification get 401, and selector errors
+// get 500; Apple then falls back to the default message.
+type RealtimeHandler struct {
+	verifier *jws.Verifier
+	selector MessageSelector
+}
+
+// NewRealtimeHandler returns a handler that verifies requests with v
+// (jws.DefaultVerifier() when nil) and answers them with selector.
+func NewRealtimeHandler(v *jws.Verifier, selector MessageSelector) *RealtimeHandler {
+	if v == nil {
+		v = jws.DefaultVerifier()
+	}
+	return &RealtimeHandler{verifier: v, selector: selector}
+}
+
+// RealtimeHandler returns a [RealtimeHandler] that verifies requests
+// with the service's verifier.
+func (s *Service) RealtimeHandler(selector MessageSelector) *RealtimeHandler {
+	return NewRealtimeHandler(s.jwsVerifier(), selector)
+}
+
+// ServeHTTP implements http.Handler.
+func (h *RealtimeHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var envelope struct {
+		SignedPayload string `json:"signedPayload"`
+	}
+	data, err := io.ReadAll(io.LimitReader(r.Body, maxRealtimeRequestSize+1))
+	if err != nil || len(data) > maxRealtimeRequestSize || json.Unmarshal(data, &envelope) != nil || envelope.SignedPayload == "" {
+		http.Error(w, "malformed request", http.StatusBadRequest)
+		return
+	}
+	req, err := jws.VerifyAndDecode[RealtimeRequestBody](h.verifier, envelope.SignedPayload)
+	if err != nil {
+		status := http.StatusBadRequest
+		var ve *jws.VerificationError
+		if errors.As(err, &ve) && ve.Reason != jws.ReasonStructure {
+			status = http.StatusUnauthorized
+		}
+		http.Error(w, "invalid signed payload", status)
+		return
+	}
+	resp, err := h.selector.SelectMessage(r.Context(), req)
+	if err != nil {
+		http.Error(w, "message selection failed", http.StatusInternalServerError)
+		return
+	}
+	if resp == nil {
+		resp = &RealtimeResponseBody{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,104 @@
+package AppStoreServer
+
+import (
+	"strings"
+
+	Apple "github.com/godrealms/go-apple-sdk"
+	"github.com/godrealms/go-apple-sdk/jws"
+	"github.com/godrealms/go-apple-sdk/types"
+)
+
+// Hosts for the App Store Server API. Production and sandbox are
+// separate deployments; a transaction only exists in the environment
+// it was made in.
+const (
+	ProductionBaseURL = "https://api.storekit.itunes.apple.com"
+	SandboxBaseURL    = "https://api.storekit-sandbox.itunes.apple.com"
+)
+
+// Config configures a [Service].
+type Config struct {
+	// Environment selects the API host. Defaults to the environment
+	// of the *Apple.Client passed to [New] (sandbox when the client
+	// was created with Sandbox=true, production otherwise).
+	Environment types.Environment
+	// BaseURL, when non-empty, overrides the host derived from
+	// Environment. Mostly useful for pointing the SDK at an
+	// httptest.Server.
+	BaseURL string
+	// Verifier checks the signed JWS values that iterators decode.
+	// Defaults to jws.DefaultVerifier().
+	Verifier *jws.Verifier
+}
+
+// Service is the entry point into App Store Server API endpoints.
+// Create one with [New] or [NewService] and reuse it.
+//
+// A Service owns an HTTP client that is bound to a single host and
+// signs every request with the App Store Server API JWT. It never
+// modifies the *Apple.Client it was created from, so one root client
+// can back several Services (and Client.AppStoreConnect()) at the
+// same time.
+//
+// Service is safe for concurrent use by multiple goroutines.
+type Service struct {
+	client      *Apple.Client
+	environment types.Environment
+	verifier    *jws.Verifier
+}
+
+// New constructs a [Service] from the credentials and transport
+// settings of client. client must be non-nil.
+func New(client *Apple.Client, cfg Config) *Service {
+	if client == nil {
+		panic("AppStoreServer.New: client is required")
+	}
+	environment := cfg.Environment
+	if environment == "" {
+		environment = types.EnvironmentProduction
+		if client.IsSandbox() {
+			environment = types.EnvironmentSandbox
+		}
+	}
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		baseURL = ProductionBaseURL
+		if environment == types.EnvironmentSandbox {
+			baseURL = SandboxBaseURL
+		}
+	}
+	return &Service{
+		client:      client.ForService(Apple.AppStoreServerClient, strings.TrimRight(baseURL, "/")),
+		environment: environment,
+		verifier:    cfg.Verifier,
+	}
+}
+
+// NewService is shorthand for New(client, Config{}): a Service for the
+// client's own environment.
+func NewService(client *Apple.Client) *Service {
+	return New(client, Config{})
+}
+
+// Environment returns the environment the service sends requests to.
+func (s *Service) Environment() types.Environment { return s.environment }
+
+// BaseURL returns the service's base URL (without trailing slash).
+func (s *Service) BaseURL() string { return s.client.BaseURL() }
+
+// jwsVerifier returns the verifier for signed payloads.
+func (s *Service) jwsVerifier() *jws.Verifier {
+	if s.verifier != nil {
+		return s.verifier
+	}
+	return jws.DefaultVerifier()
+}
+
+// Do sends params to the service's host with its credentials and maps
+// Apple's error responses to [*APIError], like the endpoint methods
+// do. It is the building block for API families served from the same
+// host, such as the Advanced Commerce API, and for endpoints this
+// package does not wrap yet.
+func (s *Service) Do(params Apple.RequestParams) error {
+	return s.request(params)
+}
@@ -0,0 +1,111 @@
+package AppStoreServer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/godrealms/go-apple-sdk/types"
+)
+
+func TestNew_EnvironmentDefaultsFromClient(t *testing.T) {
+	sandbox := NewService(newTestClient(t, true))
+	if sandbox.Environment() != types.EnvironmentSandbox || sandbox.BaseURL() != SandboxBaseURL {
+		t.Errorf("sandbox service = %s %s", sandbox.Environment(), sandbox.BaseURL())
+	}
+	production := NewService(newTestClient(t, false))
+	if production.Environment() != types.EnvironmentProduction || production.BaseURL() != ProductionBaseURL {
+		t.Errorf("production service = %s %s", production.Environment(), production.BaseURL())
+	}
+	override := New(newTestClient(t, false), Config{Environment: types.EnvironmentSandbox})
+	if override.BaseURL() != SandboxBaseURL {
+		t.Errorf("override base URL = %s", override.BaseURL())
+	}
+}
+
+func TestNew_DoesNotMutateClient(t *testing.T) {
+	client := newTestClient(t, true)
+	_ = New(client, Config{BaseURL: "https://example.invalid/"})
+	if client.BaseURL() != "" {
+		t.Errorf("root client base URL = %q, want unchanged", client.BaseURL())
+	}
+}
+
+func TestService_GetTransactionInfo(t *testing.T) {
+	var auth, path string
+	svc, _ := newTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		path = r.URL.Path
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"signedTransactionInfo":"a.b.c"}`))
+	}))
+
+	info, err := svc.GetTransactionInfo(context.Background(), "2000000000000001")
+	if err != nil {
+		t.Fatalf("GetTransactionInfo: %v", err)
+	}
+	if path != "/inApps/v1/transactions/2000000000000001" {
+		t.Errorf("path = %q", path)
+	}
+	if !strings.HasPrefix(auth, "Bearer ") {
+		t.Errorf("auth = %q", auth)
+	}
+	if info.SignedTransactionInfo != "a.b.c" {
+		t.Errorf("signedTransactionInfo = %q", info.SignedTransactionInfo)
+	}
+}
+
+// TestService_SharedClientConcurrent drives two Services built from
+// one root client at different hosts in parallel. Before Service
+// existed, each call rewrote the shared client's base URL, so requests
+// could land on the wrong host. Run under -race to catch regressions.
+func TestService_SharedClientConcurrent(t *testing.T) {
+	newServer := func(name string, hits *int, mu *sync.Mutex) *httptest.Server {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			*hits++
+			mu.Unlock()
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"signedTransactionInfo":"` + name + `"}`))
+		}))
+		t.Cleanup(srv.Close)
+		return srv
+	}
+	var mu sync.Mutex
+	var hitsA, hitsB int
+	srvA := newServer("a", &hitsA, &mu)
+	srvB := newServer("b", &hitsB, &mu)
+
+	client := newTestClient(t, true)
+	svcA := New(client, Config{BaseURL: srvA.URL})
+	svcB := New(client, Config{BaseURL: srvB.URL})
+
+	const N = 8
+	var wg sync.WaitGroup
+	wg.Add(2 * N)
+	for i := 0; i < N; i++ {
+		for _, tc := range []struct {
+			svc  *Service
+			want types.JWSTransaction
+		}{{svcA, "a"}, {svcB, "b"}} {
+			go func() {
+				defer wg.Done()
+				info, err := tc.svc.GetTransactionInfo(context.Background(), "1")
+				if err != nil {
+					t.Errorf("GetTransactionInfo: %v", err)
+					return
+				}
+				if info.SignedTransactionInfo != tc.want {
+					t.Errorf("answered by %q, want %q", info.SignedTransactionInfo, tc.want)
+				}
+			}()
+		}
+	}
+	wg.Wait()
+	if hitsA != N || hitsB != N {
+		t.Errorf("hits = %d/%d, want %d each", hitsA, hitsB, N)
+	}
+}
@@ -82,8 +82,7 @@ type MassExtendRenewalDateStatusResponse struct {
 }
 
 // ExtendSubscriptionRenewalDate Extends the renewal date of a customer’s active subscription using the original transaction identifier.
-func ExtendSubscriptionRenewalDate(ctx context.Context, client *Apple.Client, originalTransactionId string, body *ExtendRenewalDateRequest) (*ExtendRenewalDateResponse, error) {
-	client.SetService(Apple.AppStoreServerClient)
+func (s *Service) ExtendSubscriptionRenewalDate(ctx context.Context, originalTransactionId string, body *ExtendRenewalDateRequest) (*ExtendRenewalDateResponse, error) {
 	var result = new(ExtendRenewalDateResponse)
 	params := Apple.RequestParams{
 		Ctx:    ctx,
@@ -98,15 +97,20 @@ func ExtendSubscriptionRenewalDate(ctx context.Context, client *Apple.Client, or
 			"originalTransactionId": originalTransactionId,
 		},
 	}
-	if err := client.Request(params); err != nil {
+	if err := s.request(params); err != nil {
 		return nil, err
 	}
 	return result, nil
 }
 
+// ExtendSubscriptionRenewalDate calls [Service.ExtendSubscriptionRenewalDate]
+// on a Service for client's environment.
+func ExtendSubscriptionRenewalDate(ctx context.Context, client *Apple.Client, originalTransactionId string, body *ExtendRenewalDateRequest) (*ExtendRenewalDateResponse, error) {
+	return NewService(client).ExtendSubscriptionRenewalDate(ctx, originalTransactionId, body)
+}
+
 // ExtendSubscriptionRenewalDatesForAllActiveSubscribers Uses a subscription’s product identifier to extend the renewal date for all of its eligible active subscribers.
-func ExtendSubscriptionRenewalDatesForAllActiveSubscribers(ctx context.Context, client *Apple.Client, body *MassExtendRenewalDateRequest) (*MassExtendRenewalDateResponse, error) {
-	client.SetService(Apple.AppStoreServerClient)
+func (s *Service) ExtendSubscriptionRenewalDatesForAllActiveSubscribers(ctx context.Context, body *MassExtendRenewalDateRequest) (*MassExtendRenewalDateResponse, error) {
 	var result = new(MassExtendRenewalDateResponse)
 	params := Apple.RequestParams{
 		Ctx:    ctx,
@@ -118,19 +122,24 @@ func ExtendSubscriptionRenewalDatesForAllActiveSubscribers(ctx context.Context,
 			"Content-Type": "application/json",
 		},
 	}
-	if err := client.Request(params); err != nil {
+	if err := s.request(params); err != nil {
 		return nil, err
 	}
 	return result, nil
 }
 
+// ExtendSubscriptionRenewalDatesForAllActiveSubscribers calls [Service.ExtendSubscriptionRenewalDatesForAllActiveSubscribers]
+// on a Service for client's environment.
+func ExtendSubscriptionRenewalDatesForAllActiveSubscribers(ctx context.Context, client *Apple.Client, body *MassExtendRenewalDateRequest) (*MassExtendRenewalDateResponse, error) {
+	return NewService(client).ExtendSubscriptionRenewalDatesForAllActiveSubscribers(ctx, body)
+}
+
 // GetStatusOfSubscriptionRenewalDateExtensions Checks whether a renewal date extension request completed, and provides the final count of successful or failed extensions.
 //
 // Per Apple's documentation this endpoint is GET, not PUT — the
 // previous PUT was a transcription error and would have produced
 // a 405 Method Not Allowed from Apple if anyone had ever called it.
-func GetStatusOfSubscriptionRenewalDateExtensions(ctx context.Context, client *Apple.Client, productId string, requestIdentifier string) (*MassExtendRenewalDateStatusResponse, error) {
-	client.SetService(Apple.AppStoreServerClient)
+func (s *Service) GetStatusOfSubscriptionRenewalDateExtensions(ctx context.Context, productId string, requestIdentifier string) (*MassExtendRenewalDateStatusResponse, error) {
 	var result = new(MassExtendRenewalDateStatusResponse)
 	params := Apple.RequestParams{
 		Ctx:    ctx,
@@ -145,8 +154,14 @@ func GetStatusOfSubscriptionRenewalDateExtensions(ctx context.Context, client *A
 			"requestIdentifier": requestIdentifier,
 		},
 	}
-	if err := client.Request(params); err != nil {
+	if err := s.request(params); err != nil {
 		return nil, err
 	}
 	return result, nil
 }
+
+// GetStatusOfSubscriptionRenewalDateExtensions calls [Service.GetStatusOfSubscriptionRenewalDateExtensions]
+// on a Service for client's environment.
+func GetStatusOfSubscriptionRenewalDateExtensions(ctx context.Context, client *Apple.Client, productId string, requestIdentifier string) (*MassExtendRenewalDateStatusResponse, error) {
+	return NewService(client).GetStatusOfSubscriptionRenewalDateExtensions(ctx, productId, requestIdentifier)
+}
@@ -47,8 +47,7 @@ type StatusResponse struct {
 
 // GetAllSubscriptionStatuses
 // Get the statuses for all of a customer’s auto-renewable subscriptions in your app.
-func GetAllSubscriptionStatuses(ctx context.Context, client *Apple.Client, transactionId string) (*StatusResponse, error) {
-	client.SetService(Apple.AppStoreServerClient)
+func (s *Service) GetAllSubscriptionStatuses(ctx context.Context, transactionId string) (*StatusResponse, error) {
 	var result = new(StatusResponse)
 	params := Apple.RequestParams{
 		Ctx:    ctx,
@@ -62,8 +61,14 @@ func GetAllSubscriptionStatuses(ctx context.Context, client *Apple.Client, trans
 			"transactionId": transactionId,
 		},
 	}
-	if err := client.Request(params); err != nil {
+	if err := s.request(params); err != nil {
 		return nil, err
 	}
 	return result, nil
 }
+
+// GetAllSubscriptionStatuses calls [Service.GetAllSubscriptionStatuses]
+// on a Service for client's environment.
+func GetAllSubscriptionStatuses(ctx context.Context, client *Apple.Client, transactionId string) (*StatusResponse, error) {
+	return NewService(client).GetAllSubscriptionStatuses(ctx, transactionId)
+}
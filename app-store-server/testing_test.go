@@ -0,0 +1,64 @@
+package AppStoreServer
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	Apple "github.com/godrealms/go-apple-sdk"
+	"github.com/godrealms/go-apple-sdk/internal/testchain"
+	"github.com/godrealms/go-apple-sdk/jws"
+)
+
+// newTestKeyPEM returns a freshly generated P-256 key in PKCS#8 PEM
+// form, which is what App Store Connect hands out as a .p8 file.
+func newTestKeyPEM(t *testing.T) string {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+}
+
+// newTestClient returns a root client with throwaway credentials.
+func newTestClient(t *testing.T, sandbox bool) *Apple.Client {
+	t.Helper()
+	return Apple.NewClient(sandbox, "KID123", "issuer-id", "com.example.app", newTestKeyPEM(t))
+}
+
+// newTestService spins up an httptest.Server with the provided
+// handler and returns a Service pointed at it.
+func newTestService(t *testing.T, handler http.Handler) (*Service, *httptest.Server) {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	svc := New(newTestClient(t, true), Config{BaseURL: srv.URL})
+	return svc, srv
+}
+
+// newVerifyingTestService is newTestService with a verifier that trusts
+// a fresh test certificate chain; sign payloads with chain.SignJWS.
+func newVerifyingTestService(t *testing.T, handler http.Handler) (*Service, *testchain.Chain) {
+	t.Helper()
+	chain := testchain.New(t)
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	svc := New(newTestClient(t, true), Config{
+		BaseURL: srv.URL,
+		Verifier: jws.NewVerifier(
+			jws.WithRootCAs(chain.RootPool),
+			jws.WithRequiredOIDs(jws.OIDAppleReceiptSigning),
+		),
+	})
+	return svc, chain
+}
@@ -2,11 +2,97 @@ package AppStoreServer
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strconv"
 
 	Apple "github.com/godrealms/go-apple-sdk"
 	"github.com/godrealms/go-apple-sdk/types"
 )
 
+// TransactionHistoryRequest The query parameters for Get Transaction History.
+// Every field is optional; repeated fields are sent as repeated query keys.
+type TransactionHistoryRequest struct {
+	// A token you provide to get the next set of up to 20 transactions.
+	// Use the revision token from the previous HistoryResponse; leave it empty for the first page.
+	Revision types.Revision
+	// An optional start date of the timespan for the transaction history records you’re requesting.
+	// The results include a transaction if its purchaseDate is equal to or greater than the startDate.
+	StartDate types.Timestamp
+	// An optional end date of the timespan for the transaction history records you’re requesting.
+	// The results include a transaction if its purchaseDate is less than the endDate.
+	EndDate types.Timestamp
+	// An optional filter that indicates the product identifiers to include in the transaction history.
+	ProductIds []types.ProductId
+	// An optional filter that indicates the product types to include in the transaction history.
+	ProductTypes []types.ProductType
+	// An optional sort order for the transaction history records, by their recently modified date.
+	// Apple defaults to SORT_ASCENDING.
+	Sort types.Sort
+	// An optional filter that indicates the subscription group identifiers to include in the transaction history.
+	SubscriptionGroupIdentifiers []types.SubscriptionGroupIdentifier
+	// An optional filter that limits the transaction history by the in-app ownership type.
+	InAppOwnershipType types.InAppOwnershipType
+	// An optional filter: true returns only revoked transactions, false only nonrevoked ones.
+	// Nil leaves the parameter out.
+	Revoked *bool
+}
+
+// Validate checks the request locally, so a malformed query fails
+// before it costs a round trip.
+func (r TransactionHistoryRequest) Validate() error {
+	switch {
+	case r.StartDate < 0 || r.EndDate < 0:
+		return errors.New("app store server: transaction history: negative startDate or endDate")
+	case r.StartDate > 0 && r.EndDate > 0 && r.EndDate <= r.StartDate:
+		return errors.New("app store server: transaction history: endDate must be later than startDate")
+	case r.Sort != "" && r.Sort != types.SORT_ASCENDING && r.Sort != types.SORT_DESCENDING:
+		return fmt.Errorf("app store server: transaction history: unknown sort %q", r.Sort)
+	}
+	return nil
+}
+
+// queryParams encodes the request in the form Apple.RequestParams expects.
+func (r TransactionHistoryRequest) queryParams() map[string]any {
+	q := make(map[string]any)
+	if r.Revision != "" {
+		q["revision"] = string(r.Revision)
+	}
+	if r.StartDate > 0 {
+		q["startDate"] = strconv.FormatInt(int64(r.StartDate), 10)
+	}
+	if r.EndDate > 0 {
+		q["endDate"] = strconv.FormatInt(int64(r.EndDate), 10)
+	}
+	if len(r.ProductIds) > 0 {
+		q["productId"] = stringSlice(r.ProductIds)
+	}
+	if len(r.ProductTypes) > 0 {
+		q["productType"] = stringSlice(r.ProductTypes)
+	}
+	if r.Sort != "" {
+		q["sort"] = string(r.Sort)
+	}
+	if len(r.SubscriptionGroupIdentifiers) > 0 {
+		q["subscriptionGroupIdentifier"] = stringSlice(r.SubscriptionGroupIdentifiers)
+	}
+	if r.InAppOwnershipType != "" {
+		q["inAppOwnershipType"] = string(r.InAppOwnershipType)
+	}
+	if r.Revoked != nil {
+		q["revoked"] = *r.Revoked
+	}
+	return q
+}
+
+func stringSlice[S ~string](in []S) []string {
+	out := make([]string, len(in))
+	for i, v := range in {
+		out[i] = string(v)
+	}
+	return out
+}
+
 type HistoryResponse struct {
 	// The app’s identifier in the App Store.
 	AppAppleId types.AppAppleId `json:"appAppleId"`
@@ -24,9 +110,12 @@ type HistoryResponse struct {
 }
 
 // GetTransactionHistory Get a customer’s in-app purchase transaction history for your app.
-func GetTransactionHistory(ctx context.Context, client *Apple.Client, transactionId string, queryParams ...map[string]any) (*HistoryResponse, error) {
+// It returns one page; use [Service.TransactionHistory] to walk all of them.
+func (s *Service) GetTransactionHistory(ctx context.Context, transactionId string, req TransactionHistoryRequest) (*HistoryResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
 	var result = new(HistoryResponse)
-	client.SetService(Apple.AppStoreServerClient)
 	params := Apple.RequestParams{
 		Ctx:    ctx,
 		Method: "GET",
@@ -38,14 +127,55 @@ func GetTransactionHistory(ctx context.Context, client *Apple.Client, transactio
 		PathParams: map[string]string{
 			"transactionId": transactionId,
 		},
+		QueryParams: req.queryParams(),
 	}
-
-	if len(queryParams) > 0 && queryParams[0] != nil {
-		params.QueryParams = queryParams[0]
-	}
-
-	if err := client.Request(params); err != nil {
+	if err := s.request(params); err != nil {
 		return nil, err
 	}
 	return result, nil
 }
+
+// TransactionHistory returns an iterator over the customer's signed
+// transactions matching req, following revision tokens until Apple
+// reports no more. req.Revision, if set, is where iteration starts.
+func (s *Service) TransactionHistory(transactionId string, req TransactionHistoryRequest) *Iterator[types.JWSTransaction] {
+	return newIterator(func(ctx context.Context) ([]types.JWSTransaction, bool, error) {
+		page, err := s.GetTransactionHistory(ctx, transactionId, req)
+		if err != nil {
+			return nil, false, err
+		}
+		req.Revision = page.Revision
+		return page.SignedTransactions, bool(page.HasMore) && page.Revision != "", nil
+	})
+}
+
+// DecodedTransactionHistory is [Service.TransactionHistory] with each
+// transaction verified and decoded by the service's verifier. A
+// transaction that fails verification stops the iteration with a
+// *jws.VerificationError.
+func (s *Service) DecodedTransactionHistory(transactionId string, req TransactionHistoryRequest) *Iterator[*types.JWSTransactionDecodedPayload] {
+	return s.decodeTransactions(s.TransactionHistory(transactionId, req))
+}
+
+// decodeTransactions verifies and decodes the transactions of signed
+// one page at a time.
+func (s *Service) decodeTransactions(signed *Iterator[types.JWSTransaction]) *Iterator[*types.JWSTransactionDecodedPayload] {
+	n := 0
+	return newIterator(func(ctx context.Context) ([]*types.JWSTransactionDecodedPayload, bool, error) {
+		if !signed.Next(ctx) {
+			return nil, false, signed.Err()
+		}
+		decoded, err := signed.Value().DecryptWith(s.jwsVerifier())
+		if err != nil {
+			return nil, false, fmt.Errorf("app store server: transaction %d: %w", n, err)
+		}
+		n++
+		return []*types.JWSTransactionDecodedPayload{decoded}, true, nil
+	})
+}
+
+// GetTransactionHistory calls [Service.GetTransactionHistory]
+// on a Service for client's environment.
+func GetTransactionHistory(ctx context.Context, client *Apple.Client, transactionId string, req TransactionHistoryRequest) (*HistoryResponse, error) {
+	return NewService(client).GetTransactionHistory(ctx, transactionId, req)
+}
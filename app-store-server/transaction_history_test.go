@@ -0,0 +1,120 @@
+package AppStoreServer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/godrealms/go-apple-sdk/internal/testchain"
+	"github.com/godrealms/go-apple-sdk/jws"
+	"github.com/godrealms/go-apple-sdk/types"
+)
+
+func TestTransactionHistoryRequest_Query(t *testing.T) {
+	var got url.Values
+	svc, _ := newTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.URL.Query()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"signedTransactions":[],"hasMore":false}`))
+	}))
+
+	revoked := false
+	req := TransactionHistoryRequest{
+		Revision:                     "rev",
+		StartDate:                    1000,
+		EndDate:                      2000,
+		ProductIds:                   []types.ProductId{"a", "b"},
+		ProductTypes:                 []types.ProductType{types.PRODUCT_TYPE_CONSUMABLE},
+		Sort:                         types.SORT_DESCENDING,
+		SubscriptionGroupIdentifiers: []types.SubscriptionGroupIdentifier{"g1", "g2"},
+		InAppOwnershipType:           types.IN_APP_OWNERSHIP_TYPE_PURCHASED,
+		Revoked:                      &revoked,
+	}
+	if _, err := svc.GetTransactionHistory(context.Background(), "1", req); err != nil {
+		t.Fatalf("GetTransactionHistory: %v", err)
+	}
+	want := url.Values{
+		"revision":                    {"rev"},
+		"startDate":                   {"1000"},
+		"endDate":                     {"2000"},
+		"productId":                   {"a", "b"},
+		"productType":                 {"CONSUMABLE"},
+		"sort":                        {"DESCENDING"},
+		"subscriptionGroupIdentifier": {"g1", "g2"},
+		"inAppOwnershipType":          {"PURCHASED"},
+		"revoked":                     {"false"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("query = %v\nwant    %v", got, want)
+	}
+
+	for _, bad := range []TransactionHistoryRequest{
+		{StartDate: 2, EndDate: 1},
+		{Sort: "SIDEWAYS"},
+	} {
+		if _, err := svc.GetTransactionHistory(context.Background(), "1", bad); err == nil {
+			t.Errorf("%+v accepted", bad)
+		}
+	}
+}
+
+// historyPages serves pages keyed by the revision query parameter.
+func historyPages(t *testing.T, chain **testchain.Chain, revisions *[]string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rev := r.URL.Query().Get("revision")
+		*revisions = append(*revisions, rev)
+		sign := func(id types.TransactionId) types.JWSTransaction {
+			return types.JWSTransaction((*chain).SignJWS(t, types.JWSTransactionDecodedPayload{TransactionId: id}))
+		}
+		page := HistoryResponse{HasMore: true, Revision: "r2"}
+		switch rev {
+		case "":
+			page.SignedTransactions = []types.JWSTransaction{sign("t1"), sign("t2")}
+		case "r2":
+			page = HistoryResponse{Revision: "r3", SignedTransactions: []types.JWSTransaction{sign("t3")}}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(page)
+	})
+}
+
+func TestService_DecodedTransactionHistory(t *testing.T) {
+	var chain *testchain.Chain
+	var revisions []string
+	svc, tc := newVerifyingTestService(t, historyPages(t, &chain, &revisions))
+	chain = tc
+
+	all, err := svc.DecodedTransactionHistory("1", TransactionHistoryRequest{Sort: types.SORT_ASCENDING}).All(context.Background())
+	if err != nil {
+		t.Fatalf("DecodedTransactionHistory: %v", err)
+	}
+	var ids []types.TransactionId
+	for _, tx := range all {
+		ids = append(ids, tx.TransactionId)
+	}
+	if !reflect.DeepEqual(ids, []types.TransactionId{"t1", "t2", "t3"}) {
+		t.Errorf("transactions = %v", ids)
+	}
+	if !reflect.DeepEqual(revisions, []string{"", "r2"}) {
+		t.Errorf("revisions requested = %q", revisions)
+	}
+}
+
+func TestService_DecodedTransactionHistory_RejectsForeignChain(t *testing.T) {
+	var revisions []string
+	foreign := testchain.New(t)
+	svc, _ := newVerifyingTestService(t, historyPages(t, &foreign, &revisions))
+
+	it := svc.DecodedTransactionHistory("1", TransactionHistoryRequest{})
+	if it.Next(context.Background()) {
+		t.Fatal("yielded a transaction signed by an untrusted chain")
+	}
+	var ve *jws.VerificationError
+	if !errors.As(it.Err(), &ve) {
+		t.Errorf("err = %v, want *jws.VerificationError", it.Err())
+	}
+}
@@ -12,8 +12,8 @@ type TransactionInfoResponse struct {
 	SignedTransactionInfo types.JWSTransaction `json:"signedTransactionInfo"`
 }
 
-func GetTransactionInfo(ctx context.Context, client *Apple.Client, transactionId string) (*TransactionInfoResponse, error) {
-	client.SetService(Apple.AppStoreServerClient)
+// GetTransactionInfo Get information about a single transaction for your app.
+func (s *Service) GetTransactionInfo(ctx context.Context, transactionId string) (*TransactionInfoResponse, error) {
 	var result = new(TransactionInfoResponse)
 	params := Apple.RequestParams{
 		Ctx:    ctx,
@@ -27,8 +27,49 @@ func GetTransactionInfo(ctx context.Context, client *Apple.Client, transactionId
 			"transactionId": transactionId,
 		},
 	}
-	if err := client.Request(params); err != nil {
+	if err := s.request(params); err != nil {
 		return nil, err
 	}
 	return result, nil
 }
+
+// GetTransactionInfo calls [Service.GetTransactionInfo]
+// on a Service for client's environment.
+func GetTransactionInfo(ctx context.Context, client *Apple.Client, transactionId string) (*TransactionInfoResponse, error) {
+	return NewService(client).GetTransactionInfo(ctx, transactionId)
+}
+
+// AppTransactionInfoResponse A response that contains signed app transaction information for a customer.
+type AppTransactionInfoResponse struct {
+	// A customer’s app transaction information, signed by Apple, in JSON Web Signature (JWS) format.
+	SignedAppTransactionInfo types.JWSAppTransaction `json:"signedAppTransactionInfo"`
+}
+
+// GetAppTransactionInfo Get a customer’s app transaction information for your app.
+// transactionId may be any in-app purchase transaction ID, original transaction ID or app transaction ID
+// belonging to the customer. A customer without an app transaction yields [ErrAppTransactionNotFound].
+func (s *Service) GetAppTransactionInfo(ctx context.Context, transactionId string) (*AppTransactionInfoResponse, error) {
+	var result = new(AppTransactionInfoResponse)
+	params := Apple.RequestParams{
+		Ctx:    ctx,
+		Method: "GET",
+		Path:   "/inApps/v1/transactions/appTransactions/{transactionId}",
+		Result: result,
+		Headers: map[string]string{
+			"Accept": "application/json",
+		},
+		PathParams: map[string]string{
+			"transactionId": transactionId,
+		},
+	}
+	if err := s.request(params); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetAppTransactionInfo calls [Service.GetAppTransactionInfo]
+// on a Service for client's environment.
+func GetAppTransactionInfo(ctx context.Context, client *Apple.Client, transactionId string) (*AppTransactionInfoResponse, error) {
+	return NewService(client).GetAppTransactionInfo(ctx, transactionId)
+}
@@ -0,0 +1,49 @@
+package AppStoreServer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/godrealms/go-apple-sdk/internal/testchain"
+	"github.com/godrealms/go-apple-sdk/types"
+)
+
+func TestService_GetAppTransactionInfo(t *testing.T) {
+	var chain *testchain.Chain
+	svc, tc := newVerifyingTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path != "/inApps/v1/transactions/appTransactions/1000" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errorCode":4040019,"errorMessage":"App transaction does not exist."}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(AppTransactionInfoResponse{
+			SignedAppTransactionInfo: types.JWSAppTransaction(chain.SignJWS(t, types.JWSAppTransactionDecodedPayload{
+				AppTransactionId:           "app-tx-1",
+				OriginalApplicationVersion: "1.2",
+				PreorderDate:               1690000000000,
+			})),
+		})
+	}))
+	chain = tc
+	ctx := context.Background()
+
+	resp, err := svc.GetAppTransactionInfo(ctx, "1000")
+	if err != nil {
+		t.Fatalf("GetAppTransactionInfo: %v", err)
+	}
+	app, err := resp.SignedAppTransactionInfo.DecryptWith(svc.jwsVerifier())
+	if err != nil {
+		t.Fatalf("DecryptWith: %v", err)
+	}
+	if app.AppTransactionId != "app-tx-1" || app.OriginalApplicationVersion != "1.2" || !app.IsPreorder() {
+		t.Errorf("app transaction = %+v", app)
+	}
+
+	if _, err := svc.GetAppTransactionInfo(ctx, "2000"); !errors.Is(err, ErrAppTransactionNotFound) {
+		t.Errorf("err = %v, want ErrAppTransactionNotFound", err)
+	}
+}
@@ -1,18 +1,15 @@
 package Apple
 
 import (
-	"bytes"
 	"context"
+	"crypto"
 	"encoding/json"
 	"fmt"
-	"log"
 	"net/http"
-	"strings"
 	"time"
 
 	"github.com/go-resty/resty/v2"
 	AppStoreConnect "github.com/godrealms/go-apple-sdk/app-store-connect"
-	"github.com/godrealms/go-apple-sdk/types"
 	"github.com/golang-jwt/jwt/v5"
 )
 
@@ -39,8 +36,26 @@ type Client struct {
 	sandbox     bool
 	config      *Config
 	service     AppleClient
+	transport   http.RoundTripper
 	httpclient  *resty.Client
 	middlewares []Middleware
+
+	logger        Logger
+	logBody       bool
+	logUnredacted bool
+
+	// keys supplies the kid and signer for scoped tokens and SignJWS.
+	// By default it holds config.Signer, or the key parsed from
+	// config.PrivateKey once at construction, together with any parse
+	// or validation failure, reported on first use.
+	keys          KeySource
+	serverTokens  TokenSource
+	connectTokens TokenSource
+
+	// connectRetry and connectLimiter are handed to the service built
+	// by AppStoreConnect.
+	connectRetry   *AppStoreConnect.RetryPolicy
+	connectLimiter AppStoreConnect.Limiter
 }
 
 // RequestParams contains all possible parameters for making a request
@@ -63,11 +78,28 @@ type RequestParams struct {
 // resty.Client with sane defaults (timeout, retries from
 // [Config]) and any caller-supplied [ClientOption]s applied.
 // Service-specific configuration (base URL, JWT middleware) is
-// installed on demand via [Client.SetService].
+// installed on a derived client via [Client.ForService].
 func NewClient(Sandbox bool, kid, iss, bid, privateKey string, opts ...ClientOption) *Client {
+	return NewClientWithConfig(Sandbox, NewConfig(kid, iss, bid, privateKey), opts...)
+}
+
+// NewClientWithSigner creates a client that signs its tokens with
+// signer instead of a PEM private key, so the key can stay inside a
+// KMS, an HSM behind PKCS#11, or a local signing agent. signer must
+// hold a P-256 key; the SDK converts its ASN.1 DER signatures into
+// the form JWS requires.
+func NewClientWithSigner(Sandbox bool, kid, iss, bid string, signer crypto.Signer, opts ...ClientOption) *Client {
+	return NewClientWithConfig(Sandbox, NewSignerConfig(kid, iss, bid, signer), opts...)
+}
+
+// NewClientWithConfig creates a client from a fully populated config.
+// config.Signer takes precedence over config.PrivateKey. An unusable
+// key does not fail construction; it is reported by the first request.
+func NewClientWithConfig(Sandbox bool, config *Config, opts ...ClientOption) *Client {
 	client := &Client{
 		sandbox:     Sandbox,
-		config:      NewConfig(kid, iss, bid, privateKey),
+		config:      config,
+		transport:   http.DefaultTransport,
 		middlewares: make([]Middleware, 0),
 	}
 	// Always initialise the underlying resty.Client and apply
@@ -79,20 +111,43 @@ func NewClient(Sandbox bool, kid, iss, bid, privateKey string, opts ...ClientOpt
 	// resetHttpClient on its own which masked the nil-deref hazard
 	// in practice — but ClientOption was effectively dead.
 	client.resetHttpClient()
+	signer, keyErr := config.signer()
+	client.keys = staticKey{kid: config.Kid, signer: signer, err: keyErr}
+	if keyErr != nil {
+		client.serverTokens = failedTokenSource(keyErr)
+		client.connectTokens = failedTokenSource(keyErr)
+	} else {
+		client.serverTokens = NewAppStoreServerTokenSource(config.Kid, config.Iss, config.Bid, signer)
+		client.connectTokens = NewAppStoreConnectTokenSource(config.Kid, config.Iss, signer)
+	}
 	for _, opt := range opts {
 		opt(client)
 	}
 	return client
 }
 
-// resetHttpClient reinitializes the HTTP client with base configuration
+// WithTransport replaces the [http.RoundTripper] used for every
+// request made by the client and by any client derived from it via
+// [Client.ForService]. The transport is shared, so connection pools
+// are reused across services.
+func WithTransport(transport http.RoundTripper) ClientOption {
+	return func(client *Client) {
+		client.transport = transport
+		client.httpclient.SetTransport(transport)
+	}
+}
+
+// resetHttpClient reinitializes the HTTP client with base configuration.
+// Every resty client wraps its own http.Client (resty mutates the
+// Timeout on it) but they all share client.transport.
 func (client *Client) resetHttpClient() {
-	client.httpclient = resty.New().
+	client.httpclient = resty.NewWithClient(&http.Client{Transport: client.transport}).
 		SetBaseURL(client.config.BaseUrl).
 		SetTimeout(client.config.Timeout).
 		SetRetryCount(client.config.RetryCount).
 		SetRetryWaitTime(client.config.RetryWaitTime).
-		SetRetryMaxWaitTime(client.config.RetryMaxWaitTime)
+		SetRetryMaxWaitTime(client.config.RetryMaxWaitTime).
+		AddRetryHook(client.logRetry)
 }
 
 // setupServiceHandlers configures service-specific request handlers
@@ -123,37 +178,91 @@ func (client *Client) getServiceHandler(service AppleClient) resty.RequestMiddle
 	}
 }
 
-// SetService sets the current service type and configures appropriate handlers
+// SetService sets the current service type and configures appropriate handlers.
+//
+// Deprecated: SetService rewrites the base URL and rebuilds the HTTP
+// client of a shared *Client, which races when the client is used from
+// several goroutines or for several services at once. Use
+// [Client.ForService] to obtain an independent, service-bound client.
 func (client *Client) SetService(service AppleClient) *Client {
 	if client.service == service {
 		return client
 	}
 
 	client.service = service
+	client.config.BaseUrl = client.serviceBaseURL(service)
+
+	client.resetHttpClient()
+	client.setupServiceHandlers(service)
+
+	return client
+}
+
+// ForService returns a new Client bound to service. The receiver is
+// never modified: the returned client has its own copy of the
+// configuration and its own resty.Client (sharing only the underlying
+// transport), so it is safe to derive clients for several services
+// from one root Client concurrently.
+//
+// baseURL overrides the host that service would normally use; pass ""
+// to get the default production or sandbox host for the client's
+// environment.
+func (client *Client) ForService(service AppleClient, baseURL string) *Client {
+	config := *client.config
+	if baseURL == "" {
+		baseURL = client.serviceBaseURL(service)
+	}
+	config.BaseUrl = baseURL
+	bound := &Client{
+		sandbox:     client.sandbox,
+		config:      &config,
+		service:     service,
+		transport:   client.transport,
+		middlewares: append([]Middleware(nil), client.middlewares...),
+
+		logger:        client.logger,
+		logBody:       client.logBody,
+		logUnredacted: client.logUnredacted,
+
+		keys:          client.keys,
+		serverTokens:  client.serverTokens,
+		connectTokens: client.connectTokens,
+
+		connectRetry:   client.connectRetry,
+		connectLimiter: client.connectLimiter,
+	}
+	bound.resetHttpClient()
+	bound.setupServiceHandlers(service)
+	return bound
+}
+
+// IsSandbox reports whether the client was created for Apple's
+// sandbox environment.
+func (client *Client) IsSandbox() bool { return client.sandbox }
+
+// BaseURL returns the base URL the client currently sends requests to.
+// It is empty for a root client that has not been bound to a service.
+func (client *Client) BaseURL() string { return client.config.BaseUrl }
+
+// serviceBaseURL returns the default host for service in the client's
+// environment.
+func (client *Client) serviceBaseURL(service AppleClient) string {
 	switch service {
 	case AppStoreConnectClient:
 		// App Store Connect API shares a single host for production and sandbox.
 		// See https://developer.apple.com/documentation/appstoreconnectapi
-		client.config.BaseUrl = "https://api.appstoreconnect.apple.com"
-	case AppStoreServerClient:
-		client.config.BaseUrl = "https://api.storekit.itunes.apple.com"
-		if client.sandbox {
-			client.config.BaseUrl = "https://api.storekit-sandbox.itunes.apple.com"
-		}
-	case AppStoreServerNotificationsClient:
+		return "https://api.appstoreconnect.apple.com"
+	case AppStoreServerClient, AppStoreServerNotificationsClient:
 		// App Store Server Notifications V2 uses the same host family as
 		// App Store Server API (storekit.itunes.apple.com / storekit-sandbox).
 		// See https://developer.apple.com/documentation/appstoreservernotifications
-		client.config.BaseUrl = "https://api.storekit.itunes.apple.com"
 		if client.sandbox {
-			client.config.BaseUrl = "https://api.storekit-sandbox.itunes.apple.com"
+			return "https://api.storekit-sandbox.itunes.apple.com"
 		}
+		return "https://api.storekit.itunes.apple.com"
+	default:
+		return client.config.BaseUrl
 	}
-
-	client.resetHttpClient()
-	client.setupServiceHandlers(service)
-
-	return client
 }
 
 // Service-specific handlers
@@ -190,32 +299,25 @@ func (client *Client) handleAppStoreNotifications(req *resty.Request) error {
 // or signing failure rather than silently producing an empty
 // header (the previous behaviour, which led to mysterious 401s
 // from Apple instead of clear local errors).
+//
+// The token comes from the client's cached [TokenSource], so repeated
+// calls return the same token until it is close to expiry.
 func (client *Client) GenerateAppStoreServerAuthorizationJWT() (string, error) {
-	privateKey, err := types.ParsePrivateKey(client.config.PrivateKey)
-	if err != nil {
-		return "", fmt.Errorf("parse private key: %w", err)
-	}
-	now := time.Now()
-	claims := jwt.MapClaims{
-		"iss": client.config.Iss,
-		"iat": now.Unix(),
-		"exp": now.Add(5 * time.Minute).Unix(),
-		"aud": "appstoreconnect-v1",
-		"bid": client.config.Bid,
-	}
-	token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
-	token.Header["kid"] = client.config.Kid
-	signedToken, err := token.SignedString(privateKey)
+	token, err := client.serverTokens.Token()
 	if err != nil {
-		return "", fmt.Errorf("sign token: %w", err)
+		return "", err
 	}
-	return fmt.Sprintf("Bearer %s", signedToken), nil
+	return "Bearer " + token, nil
 }
 
 // GenerateAppStoreConnectAuthorizationJWT builds a "Bearer …" JWT
 // header value scoped to a single (method, endpoint) pair. Returns
 // a wrapped error rather than an empty string on failure.
 //
+// Scoped tokens are tied to one request, so unlike the other tokens
+// they are signed on every call, with the current key of the client's
+// [KeySource], rather than cached.
+//
 // Most callers should use [Client.AppStoreConnect] instead, which
 // goes through the new App Store Connect [Service] with an
 // unscoped JWT — Apple permits 20-minute unscoped tokens, and the
@@ -223,9 +325,9 @@ func (client *Client) GenerateAppStoreServerAuthorizationJWT() (string, error) {
 // callers pass the URL with query parameters that Apple's
 // authoriser does not normalise the same way the SDK does.
 func (client *Client) GenerateAppStoreConnectAuthorizationJWT(method string, endpoint string) (string, error) {
-	privateKey, err := types.ParsePrivateKey(client.config.PrivateKey)
+	kid, signer, err := client.keys.SigningKey()
 	if err != nil {
-		return "", fmt.Errorf("parse private key: %w", err)
+		return "", err
 	}
 	now := time.Now()
 	claims := jwt.MapClaims{
@@ -238,23 +340,60 @@ func (client *Client) GenerateAppStoreConnectAuthorizationJWT(method string, end
 		},
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
-	token.Header["kid"] = client.config.Kid
-	signedToken, err := token.SignedString(privateKey)
+	token.Header["kid"] = kid
+	signedToken, err := signJWT(token, signer)
 	if err != nil {
 		return "", fmt.Errorf("sign token: %w", err)
 	}
 	return fmt.Sprintf("Bearer %s", signedToken), nil
 }
 
+// SignJWS signs claims as a compact ES256 JWS with the client's key,
+// for payloads Apple expects an app to pass along rather than a
+// bearer token, such as Advanced Commerce in-app requests. The kid
+// and typ headers are set; iss, bid and iat default to the client's
+// issuer ID, bundle ID and the current time when claims omits them.
+// claims is not modified.
+//
+// The key and kid are read from the client's [KeySource] on every
+// call, so a rotating source set with [WithKeySource] takes effect on
+// the next payload.
+func (client *Client) SignJWS(claims jwt.MapClaims) (string, error) {
+	kid, signer, err := client.keys.SigningKey()
+	if err != nil {
+		return "", err
+	}
+	merged := jwt.MapClaims{
+		"iss": client.config.Iss,
+		"bid": client.config.Bid,
+		"iat": time.Now().Unix(),
+	}
+	for k, v := range claims {
+		merged[k] = v
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, merged)
+	token.Header["kid"] = kid
+	signed, err := signJWT(token, signer)
+	if err != nil {
+		return "", fmt.Errorf("sign jws: %w", err)
+	}
+	return signed, nil
+}
+
 // Request is the main method for making HTTP requests
 func (client *Client) Request(params RequestParams, opts ...RequestOption) error {
 	req := client.httpclient.R()
 
 	// Attach context for timeout / cancellation propagation.
-	// Defaults to context.Background when callers leave Ctx nil.
-	if params.Ctx != nil {
-		req.SetContext(params.Ctx)
+	// Defaults to context.Background when callers leave Ctx nil. It
+	// also carries the last attempt logRetry logged, so the final
+	// attempt is not logged twice.
+	ctx := params.Ctx
+	if ctx == nil {
+		ctx = context.Background()
 	}
+	var loggedAttempt int
+	req.SetContext(context.WithValue(ctx, loggedAttemptKey{}, &loggedAttempt))
 
 	// Set request body and response result
 	if params.Body != nil {
@@ -277,8 +416,9 @@ func (client *Client) Request(params RequestParams, opts ...RequestOption) error
 			case float32, float64:
 				req.SetQueryParam(k, fmt.Sprintf("%v", val))
 			case []string:
+				// Repeated keys (productId=a&productId=b); SetQueryParam would keep only the last.
 				for _, item := range val {
-					req.SetQueryParam(k, item)
+					req.QueryParam.Add(k, item)
 				}
 			default:
 				// 对于其他类型，尝试使用 json.Marshal
@@ -316,24 +456,77 @@ func (client *Client) Request(params RequestParams, opts ...RequestOption) error
 		opt(req)
 	}
 
+	start := time.Now()
+
 	// Execute middlewares
 	for _, middleware := range client.middlewares {
 		if err := middleware(req); err != nil {
+			client.logRequest(LogRecord{
+				Method:   params.Method,
+				URL:      client.config.BaseUrl + params.Path,
+				Duration: time.Since(start),
+				Err:      err,
+			})
 			return err
 		}
 	}
 
 	// Execute request
 	resp, err := req.Execute(params.Method, params.Path)
-	if err != nil {
-		return err
+	if err == nil && !resp.IsSuccess() {
+		err = client.handleError(resp)
 	}
+	if resp == nil || resp.Request.Attempt != loggedAttempt {
+		client.logRequest(client.newLogRecord(params.Method, client.config.BaseUrl+params.Path, resp, start, err))
+	}
+	return err
+}
 
-	if !resp.IsSuccess() {
-		return client.handleError(resp)
+// logRetry is the resty retry hook. resty calls it after every attempt
+// it retries, and after the last one when retries run out, so each
+// round trip gets its own [LogRecord]; Request logs the rest.
+func (client *Client) logRetry(resp *resty.Response, err error) {
+	if client.logger == nil || resp == nil {
+		return
+	}
+	if err == nil && !resp.IsSuccess() {
+		err = client.handleError(resp)
 	}
+	if logged, ok := resp.Request.Context().Value(loggedAttemptKey{}).(*int); ok {
+		*logged = resp.Request.Attempt
+	}
+	client.logRequest(client.newLogRecord(resp.Request.Method, resp.Request.URL, resp, time.Now(), err))
+}
 
-	return nil
+// loggedAttemptKey is the context key under which Request hands
+// logRetry a pointer to the last attempt it logged.
+type loggedAttemptKey struct{}
+
+// newLogRecord snapshots one attempt for the [Logger]. resp may be nil
+// or carry no raw response when the transport failed; without a raw
+// request the duration is measured from start.
+func (client *Client) newLogRecord(method, url string, resp *resty.Response, start time.Time, err error) LogRecord {
+	record := LogRecord{
+		Method:   method,
+		URL:      url,
+		Duration: time.Since(start),
+		Err:      err,
+	}
+	if resp == nil {
+		return record
+	}
+	record.Attempt = resp.Request.Attempt
+	if raw := resp.Request.RawRequest; raw != nil {
+		record.URL = raw.URL.String()
+		record.RequestHeader = raw.Header
+		record.Duration = resp.Time()
+	}
+	if resp.RawResponse != nil {
+		record.StatusCode = resp.StatusCode()
+		record.ResponseHeader = resp.Header()
+		record.ResponseBody = resp.Body()
+	}
+	return record
 }
 
 // Request option helpers
@@ -390,72 +583,17 @@ func (client *Client) Delete(path string) error {
 	})
 }
 
-// handleError processes request errors
+// handleError converts a non-2xx response into an [*HTTPError].
+// Callers that know the API's error schema (e.g.
+// AppStoreServer.APIError) decode Body themselves. Nothing is logged
+// here: the round trip, error included, is reported once to the
+// configured [Logger] by [Client.Request].
 func (client *Client) handleError(resp *resty.Response) error {
-	// 获取请求信息
-	req := resp.Request.RawRequest
-
-	// 构建详细的错误日志
-	logInfo := []struct {
-		Key   string
-		Value interface{}
-	}{
-		{"Status Code", resp.StatusCode()},
-		{"Request URL", req.URL.String()},
-		{"Request Method", req.Method},
-		{"Request Headers", req.Header},
-		{"Response Time", resp.Time()},
-		{"Response Headers", resp.Header()},
-		{"Response Size", len(resp.Body())},
-	}
-
-	// 使用 strings.Builder 构建日志信息
-	var logMsg strings.Builder
-	logMsg.WriteString("\n=== Error Response Details ===\n")
-
-	for _, info := range logInfo {
-		logMsg.WriteString(fmt.Sprintf("%-20s: %v\n", info.Key, info.Value))
-	}
-
-	// 尝试解析响应体为 JSON 并格式化
-	var prettyJSON bytes.Buffer
-	if json.Valid(resp.Body()) {
-		if err := json.Indent(&prettyJSON, resp.Body(), "", "  "); err == nil {
-			logMsg.WriteString("\nResponse Body (JSON):\n")
-			logMsg.Write(prettyJSON.Bytes())
-		} else {
-			// 如果不是有效的 JSON，直接打印原始响应
-			logMsg.WriteString("\nResponse Body (Raw):\n")
-			logMsg.Write(resp.Body())
-		}
-	} else {
-		logMsg.WriteString("\nResponse Body (Raw):\n")
-		logMsg.WriteString(string(resp.Body()))
+	return &HTTPError{
+		StatusCode: resp.StatusCode(),
+		Header:     resp.Header().Clone(),
+		Body:       resp.Body(),
 	}
-
-	logMsg.WriteString("\n=== End Error Response ===\n")
-
-	// 打印完整的日志信息
-	log.Println(logMsg.String())
-
-	// 如果响应包含错误信息，尝试解析并返回结构化错误
-	if resp.IsError() {
-		var apiError struct {
-			ErrorCode    string `json:"ErrorCode"`
-			ErrorMessage string `json:"errorMessage"`
-			Details      any    `json:"details,omitempty"`
-		}
-
-		if err := json.Unmarshal(resp.Body(), &apiError); err == nil {
-			return fmt.Errorf("API Error - Code: %s, Message: %s, Details: %+v",
-				apiError.ErrorCode, apiError.ErrorMessage, apiError.Details)
-		}
-
-		// 如果无法解析为标准错误格式，返回HTTP状态码和原始响应
-		return fmt.Errorf("HTTP %d: %s", resp.StatusCode(), string(resp.Body()))
-	}
-
-	return nil
 }
 
 // AppStoreConnect returns a service for calling the App Store Connect API.
@@ -463,6 +601,11 @@ func (client *Client) handleError(resp *resty.Response) error {
 // authorizer on every outgoing request. Each call returns a fresh
 // service; the service is safe for concurrent use.
 //
+// The service makes a single attempt per request unless a policy is
+// set with [WithConnectRetry]; the RetryCount settings of [Config] do
+// not apply to it. Requests go through the transport set with
+// [WithTransport].
+//
 // Example:
 //
 //	svc := client.AppStoreConnect()
@@ -471,11 +614,32 @@ func (client *Client) handleError(resp *resty.Response) error {
 // See https://developer.apple.com/documentation/appstoreconnectapi for
 // a full catalog of available endpoints.
 func (client *Client) AppStoreConnect() *AppStoreConnect.Service {
+	var logger AppStoreConnect.Logger
+	if client.logger != nil {
+		logger = AppStoreConnect.LoggerFunc(func(r AppStoreConnect.LogRecord) {
+			client.logger.Log(LogRecord{
+				Method:     r.Method,
+				URL:        r.URL,
+				StatusCode: r.StatusCode,
+				Duration:   r.Duration,
+				Err:        r.Err,
+				Attempt:    r.Attempt,
+				RequestID:  r.RequestID,
+				RateLimit:  r.RateLimit,
+			})
+		})
+	}
 	return AppStoreConnect.New(AppStoreConnect.Config{
 		BaseURL:   "https://api.appstoreconnect.apple.com",
 		UserAgent: "go-apple-sdk",
+		Logger:    logger,
+		Retry:     client.connectRetry,
+		Limiter:   client.connectLimiter,
+		// Share the transport installed with WithTransport, so test
+		// doubles and proxies apply to both APIs.
+		HTTPClient: &http.Client{Transport: client.transport, Timeout: client.config.Timeout},
 		Authorizer: AppStoreConnect.AuthorizerFunc(func(req *http.Request) error {
-			token, err := client.signAppStoreConnectToken()
+			token, err := client.connectTokens.Token()
 			if err != nil {
 				return err
 			}
@@ -485,34 +649,20 @@ func (client *Client) AppStoreConnect() *AppStoreConnect.Service {
 	})
 }
 
-// signAppStoreConnectToken builds an unscoped App Store Connect JWT that
-// is valid for any endpoint. Apple permits tokens up to 20 minutes old;
-// we mint a fresh one on every request rather than caching, which keeps
-// the signer simple and stateless at the cost of a few extra signatures
-// per call.
-//
-// Distinct from [Client.GenerateAppStoreConnectAuthorizationJWT], which
-// includes a scoped "scope" claim tied to a specific method+endpoint.
-// Scoped tokens are stricter than needed for most endpoints and can
-// cause unexpected 401s, so the new AppStoreConnect.Service path uses
-// this unscoped variant instead.
-func (client *Client) signAppStoreConnectToken() (string, error) {
-	privateKey, err := types.ParsePrivateKey(client.config.PrivateKey)
-	if err != nil {
-		return "", fmt.Errorf("parse private key: %w", err)
-	}
-	now := time.Now()
-	claims := jwt.MapClaims{
-		"iss": client.config.Iss,
-		"iat": now.Unix(),
-		"exp": now.Add(20 * time.Minute).Unix(),
-		"aud": "appstoreconnect-v1",
-	}
-	token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
-	token.Header["kid"] = client.config.Kid
-	signed, err := token.SignedString(privateKey)
-	if err != nil {
-		return "", fmt.Errorf("sign token: %w", err)
-	}
-	return signed, nil
+// WithConnectRetry sets the retry policy of the service returned by
+// [Client.AppStoreConnect], which otherwise does not retry. The policy
+// controls every aspect of retrying, including MaxElapsedTime and
+// RetryNonIdempotent; AppStoreConnect.DefaultRetryPolicy is a sensible
+// start. Do not mutate policy after passing it in.
+func WithConnectRetry(policy *AppStoreConnect.RetryPolicy) ClientOption {
+	return func(client *Client) { client.connectRetry = policy }
+}
+
+// WithConnectLimiter sets the [AppStoreConnect.Limiter] of every
+// service returned by [Client.AppStoreConnect]. The limiter keeps the
+// quota it has observed, so the services built on each call pace
+// against the same quota; reuse it for other clients with the same
+// API key.
+func WithConnectLimiter(limiter AppStoreConnect.Limiter) ClientOption {
+	return func(client *Client) { client.connectLimiter = limiter }
 }
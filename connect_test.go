@@ -0,0 +1,120 @@
+package Apple
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	AppStoreConnect "github.com/godrealms/go-apple-sdk/app-store-connect"
+)
+
+// failingOnce answers the first request with 503 and later ones with
+// an empty App Store Connect list.
+type failingOnce struct{ calls atomic.Int32 }
+
+func (f *failingOnce) RoundTrip(req *http.Request) (*http.Response, error) {
+	status, body := http.StatusOK, `{"data":[]}`
+	if f.calls.Add(1) == 1 {
+		status, body = http.StatusServiceUnavailable, `{"errors":[{"status":"503"}]}`
+	}
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": {"application/json"}, "Retry-After": {"0"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+func TestClient_AppStoreConnect_RetryIsOptIn(t *testing.T) {
+	rt := &failingOnce{}
+	client := NewClient(false, "KID", "ISS", "com.example", newTestKeyPEM(t), WithTransport(rt))
+	if _, err := client.AppStoreConnect().Apps().List(context.Background(), nil); err == nil {
+		t.Fatal("expected the 503 to surface without WithConnectRetry")
+	}
+	if got := rt.calls.Load(); got != 1 {
+		t.Fatalf("attempts without WithConnectRetry = %d, want 1", got)
+	}
+
+	rt = &failingOnce{}
+	client = NewClient(false, "KID", "ISS", "com.example", newTestKeyPEM(t), WithTransport(rt),
+		WithConnectRetry(&AppStoreConnect.RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond}))
+	if _, err := client.AppStoreConnect().Apps().List(context.Background(), nil); err != nil {
+		t.Fatalf("List with retry: %v", err)
+	}
+	if got := rt.calls.Load(); got != 2 {
+		t.Fatalf("attempts with WithConnectRetry = %d, want 2", got)
+	}
+}
+
+// countingLimiter counts how often the service consulted it and how
+// many quotas it was told.
+type countingLimiter struct {
+	calls    atomic.Int32
+	observed atomic.Int32
+}
+
+func (l *countingLimiter) Observe(AppStoreConnect.RateLimit) { l.observed.Add(1) }
+
+func (l *countingLimiter) Wait(context.Context) error {
+	l.calls.Add(1)
+	return nil
+}
+
+func TestClient_AppStoreConnect_LogAndLimiter(t *testing.T) {
+	rt := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header: http.Header{
+				"Content-Type": {"application/json"},
+				"X-Request-Id": {"REQ-1"},
+				"X-Rate-Limit": {"user-hour-lim:3600;user-hour-rem:3599;"},
+			},
+			Body:    io.NopCloser(strings.NewReader(`{"data":[]}`)),
+			Request: req,
+		}, nil
+	})
+	logger := &captureLogger{}
+	limiter := &countingLimiter{}
+	client := NewClient(false, "KID", "ISS", "com.example", newTestKeyPEM(t),
+		WithTransport(rt), WithLogger(logger), WithConnectLimiter(limiter))
+	if _, err := client.AppStoreConnect().Apps().List(context.Background(), nil); err != nil {
+		t.Fatalf("List: %v", err)
+	}
+
+	records := logger.snapshot()
+	if len(records) != 1 {
+		t.Fatalf("got %d records, want 1", len(records))
+	}
+	if r := records[0]; r.RequestID != "REQ-1" || r.RateLimit.Limit != 3600 || r.RateLimit.Remaining != 3599 {
+		t.Errorf("record = %+v", r)
+	}
+	if limiter.calls.Load() != 1 || limiter.observed.Load() != 1 {
+		t.Errorf("limiter consulted %d times and told %d quotas, want 1 and 1", limiter.calls.Load(), limiter.observed.Load())
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestClient_AppStoreConnect_Timeout(t *testing.T) {
+	rt := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		<-req.Context().Done()
+		return nil, req.Context().Err()
+	})
+	config := NewConfig("KID", "ISS", "com.example", newTestKeyPEM(t))
+	config.SetWithTimeout(20 * time.Millisecond)
+	client := NewClientWithConfig(false, config, WithTransport(rt))
+
+	start := time.Now()
+	if _, err := client.AppStoreConnect().Apps().List(context.Background(), nil); err == nil {
+		t.Fatal("expected a timeout error")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("request took %v; Config.Timeout was ignored", elapsed)
+	}
+}
@@ -0,0 +1,29 @@
+// Package credentials loads App Store Connect API keys and the
+// identifiers that go with them, and builds *Apple.Client values from
+// them.
+//
+// Credentials come from three places:
+//
+//   - an AuthKey_<kid>.p8 file as downloaded from App Store Connect
+//     ([LoadKeyFile]); the key ID is taken from the file name;
+//   - APPLE_* environment variables ([FromEnv]);
+//   - a JSON or YAML profile file holding several named profiles, one
+//     per team or app ([LoadProfile]).
+//
+// Every key is checked to be an ECDSA P-256 key when it is loaded, so a
+// wrong file fails at startup instead of on the first request.
+//
+// A [Profile] can hold more than one key. All of them live in a
+// [Keyring]; exactly one is active and signs new tokens. When a key is
+// revoked, switch to another one with [Keyring.Activate] — clients built
+// with [Profile.NewClient] pick up the change on their next request, no
+// redeploy needed.
+//
+//	profile, err := credentials.LoadProfile("apple.yaml", "team-a")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	client := profile.NewClient()
+//	// later, after revoking the old key:
+//	err = profile.Keyring.Activate("NEWKEY1234")
+package credentials
@@ -0,0 +1,94 @@
+package credentials
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newTestKeyPEM(t *testing.T, curve elliptic.Curve) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+// writeKeyFile writes a fresh P-256 key to dir/AuthKey_<kid>.p8.
+func writeKeyFile(t *testing.T, dir, kid string) string {
+	t.Helper()
+	path := filepath.Join(dir, "AuthKey_"+kid+".p8")
+	if err := os.WriteFile(path, newTestKeyPEM(t, elliptic.P256()), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadKeyFile(t *testing.T) {
+	path := writeKeyFile(t, t.TempDir(), "ABC123DEFG")
+	key, err := LoadKeyFile(path)
+	if err != nil {
+		t.Fatalf("LoadKeyFile: %v", err)
+	}
+	if key.ID != "ABC123DEFG" {
+		t.Errorf("ID = %q", key.ID)
+	}
+
+	renamed := filepath.Join(filepath.Dir(path), "key.pem")
+	if err := os.Rename(path, renamed); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadKeyFile(renamed); err == nil {
+		t.Error("LoadKeyFile accepted a file without the key ID in its name")
+	}
+	if _, err := LoadKeyFileWithID("ABC123DEFG", renamed); err != nil {
+		t.Errorf("LoadKeyFileWithID: %v", err)
+	}
+}
+
+func TestParseKey(t *testing.T) {
+	pemKey := newTestKeyPEM(t, elliptic.P256())
+	if _, err := ParseKey("KID", pemKey); err != nil {
+		t.Errorf("PEM: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(pemKey)), "\n")
+	bare := strings.Join(lines[1:len(lines)-1], "\n")
+	if _, err := ParseKey("KID", []byte(bare)); err != nil {
+		t.Errorf("bare base64: %v", err)
+	}
+
+	if _, err := ParseKey("KID", newTestKeyPEM(t, elliptic.P384())); err == nil ||
+		!strings.Contains(err.Error(), "P-256") {
+		t.Errorf("P-384 key: err = %v, want P-256 error", err)
+	}
+	if _, err := ParseKey("KID", []byte("garbage")); err == nil {
+		t.Error("garbage accepted")
+	}
+}
+
+func TestKeyIDFromFilename(t *testing.T) {
+	tests := map[string]string{
+		"/keys/AuthKey_2X9R4HXF34.p8": "2X9R4HXF34",
+		"AuthKey_.p8":                 "",
+		"AuthKey_2X9R4HXF34.pem":      "",
+		"2X9R4HXF34.p8":               "",
+	}
+	for path, want := range tests {
+		got, ok := KeyIDFromFilename(path)
+		if got != want || ok != (want != "") {
+			t.Errorf("KeyIDFromFilename(%q) = %q, %v", path, got, ok)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package credentials
+
+import (
+	"crypto"
+	"errors"
+	"fmt"
+	"sync"
+
+	Apple "github.com/godrealms/go-apple-sdk"
+)
+
+// Keyring holds the API keys configured for one issuer and tracks which
+// of them is active. Only the active key signs new tokens; the others
+// stand by so a revoked key can be swapped out at runtime.
+//
+// Keyring is safe for concurrent use.
+type Keyring struct {
+	mu     sync.RWMutex
+	keys   map[string]ringEntry
+	order  []string
+	active string
+	serial uint64
+}
+
+// ringEntry is a key plus a serial number unique within the keyring, so
+// token caches can tell a re-added key apart from the one it replaced.
+type ringEntry struct {
+	Key
+	serial uint64
+}
+
+// NewKeyring returns a keyring holding keys. The first key is active.
+func NewKeyring(keys ...Key) (*Keyring, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("credentials: keyring needs at least one key")
+	}
+	ring := &Keyring{keys: make(map[string]ringEntry, len(keys))}
+	for _, key := range keys {
+		if err := ring.Add(key); err != nil {
+			return nil, err
+		}
+	}
+	return ring, nil
+}
+
+// Add adds key to the keyring without activating it, unless the
+// keyring was empty.
+func (r *Keyring) Add(key Key) error {
+	if _, err := NewKey(key.ID, key.Signer); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.keys[key.ID]; ok {
+		return fmt.Errorf("credentials: key %s is already in the keyring", key.ID)
+	}
+	if r.keys == nil {
+		r.keys = make(map[string]ringEntry)
+	}
+	r.serial++
+	r.keys[key.ID] = ringEntry{Key: key, serial: r.serial}
+	r.order = append(r.order, key.ID)
+	if r.active == "" {
+		r.active = key.ID
+	}
+	return nil
+}
+
+// Activate makes the key with the given ID the one that signs new
+// tokens. Tokens cached for the previous key are no longer handed out.
+func (r *Keyring) Activate(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.keys[id]; !ok {
+		return fmt.Errorf("credentials: key %s is not in the keyring", id)
+	}
+	r.active = id
+	return nil
+}
+
+// Remove drops a key, e.g. after it was revoked in App Store Connect.
+// The active key cannot be removed; activate another one first.
+func (r *Keyring) Remove(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.keys[id]; !ok {
+		return fmt.Errorf("credentials: key %s is not in the keyring", id)
+	}
+	if id == r.active {
+		return fmt.Errorf("credentials: key %s is active and cannot be removed", id)
+	}
+	delete(r.keys, id)
+	for i, kid := range r.order {
+		if kid == id {
+			r.order = append(r.order[:i], r.order[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// Active returns the key that currently signs tokens.
+func (r *Keyring) Active() Key {
+	return r.activeEntry().Key
+}
+
+// KeyIDs returns the IDs of all keys in the order they were added.
+func (r *Keyring) KeyIDs() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]string(nil), r.order...)
+}
+
+// SigningKey implements [Apple.KeySource] with the active key, so
+// payloads a client signs per call follow [Keyring.Activate].
+func (r *Keyring) SigningKey() (string, crypto.Signer, error) {
+	key := r.Active()
+	return key.ID, key.Signer, nil
+}
+
+func (r *Keyring) activeEntry() ringEntry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.keys[r.active]
+}
+
+// ServerTokenSource returns an [Apple.TokenSource] for the App Store
+// Server API that always signs with the keyring's active key. Tokens
+// are cached per key, as with [Apple.NewAppStoreServerTokenSource].
+func (r *Keyring) ServerTokenSource(iss, bid string) Apple.TokenSource {
+	return &rotatingTokenSource{ring: r, newSource: func(key Key) *Apple.JWTTokenSource {
+		return Apple.NewAppStoreServerTokenSource(key.ID, iss, bid, key.Signer)
+	}}
+}
+
+// ConnectTokenSource returns an [Apple.TokenSource] for the App Store
+// Connect API that always signs with the keyring's active key.
+func (r *Keyring) ConnectTokenSource(iss string) Apple.TokenSource {
+	return &rotatingTokenSource{ring: r, newSource: func(key Key) *Apple.JWTTokenSource {
+		return Apple.NewAppStoreConnectTokenSource(key.ID, iss, key.Signer)
+	}}
+}
+
+// rotatingTokenSource delegates to a cached JWTTokenSource for
+// whichever key is active at the time of the call.
+type rotatingTokenSource struct {
+	ring      *Keyring
+	newSource func(Key) *Apple.JWTTokenSource
+
+	mu      sync.Mutex
+	serial  uint64
+	current *Apple.JWTTokenSource
+}
+
+func (s *rotatingTokenSource) Token() (string, error) {
+	entry := s.ring.activeEntry()
+	s.mu.Lock()
+	if s.current == nil || s.serial != entry.serial {
+		s.current = s.newSource(entry.Key)
+		s.serial = entry.serial
+	}
+	source := s.current
+	s.mu.Unlock()
+	return source.Token()
+}
@@ -0,0 +1,104 @@
+package credentials
+
+import (
+	"crypto/elliptic"
+	"sync"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func newTestKey(t *testing.T, kid string) Key {
+	t.Helper()
+	key, err := ParseKey(kid, newTestKeyPEM(t, elliptic.P256()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func tokenKeyID(t *testing.T, token string) string {
+	t.Helper()
+	parsed, _, err := jwt.NewParser().ParseUnverified(token, jwt.MapClaims{})
+	if err != nil {
+		t.Fatalf("parse token: %v", err)
+	}
+	kid, _ := parsed.Header["kid"].(string)
+	return kid
+}
+
+func TestKeyring_Rotation(t *testing.T) {
+	ring, err := NewKeyring(newTestKey(t, "OLD"), newTestKey(t, "NEW"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	source := ring.ServerTokenSource("iss", "com.example")
+
+	token, err := source.Token()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kid := tokenKeyID(t, token); kid != "OLD" {
+		t.Fatalf("kid = %q, want the first key", kid)
+	}
+	again, _ := source.Token()
+	if again != token {
+		t.Error("token not cached")
+	}
+
+	if err := ring.Activate("NEW"); err != nil {
+		t.Fatal(err)
+	}
+	token, err = source.Token()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kid := tokenKeyID(t, token); kid != "NEW" {
+		t.Errorf("kid after Activate = %q", kid)
+	}
+
+	if err := ring.Remove("NEW"); err == nil {
+		t.Error("removed the active key")
+	}
+	if err := ring.Remove("OLD"); err != nil {
+		t.Errorf("Remove: %v", err)
+	}
+	if ids := ring.KeyIDs(); len(ids) != 1 || ids[0] != "NEW" {
+		t.Errorf("KeyIDs = %v", ids)
+	}
+}
+
+func TestKeyring_RejectsDuplicatesAndUnknownKeys(t *testing.T) {
+	if _, err := NewKeyring(); err == nil {
+		t.Error("empty keyring accepted")
+	}
+	if _, err := NewKeyring(newTestKey(t, "A"), newTestKey(t, "A")); err == nil {
+		t.Error("duplicate key ID accepted")
+	}
+	ring, _ := NewKeyring(newTestKey(t, "A"))
+	if err := ring.Activate("B"); err == nil {
+		t.Error("activated unknown key")
+	}
+}
+
+func TestKeyring_ConcurrentActivate(t *testing.T) {
+	ring, _ := NewKeyring(newTestKey(t, "A"), newTestKey(t, "B"))
+	source := ring.ConnectTokenSource("iss")
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				if j%5 == 0 {
+					_ = ring.Activate([]string{"A", "B"}[(i+j)%2])
+				}
+				if _, err := source.Token(); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,189 @@
+package credentials
+
+import (
+	"crypto/elliptic"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	Apple "github.com/godrealms/go-apple-sdk"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadProfile_YAML(t *testing.T) {
+	dir := t.TempDir()
+	writeKeyFile(t, dir, "OLDKEY0001")
+	inline := strings.ReplaceAll(string(newTestKeyPEM(t, elliptic.P256())), "\n", "\n          ")
+	path := filepath.Join(dir, "apple.yaml")
+	writeFile(t, path, `default: team-a
+profiles:
+  team-a:
+    issuerId: issuer-a
+    bundleId: com.example.a
+    sandbox: true
+    activeKey: NEWKEY0002
+    keys:
+      - path: AuthKey_OLDKEY0001.p8
+      - id: NEWKEY0002
+        privateKey: |
+          `+inline+`
+  team-b:
+    issuerId: issuer-b
+    keys:
+      - path: AuthKey_OLDKEY0001.p8
+`)
+
+	profile, err := LoadProfile(path, "")
+	if err != nil {
+		t.Fatalf("LoadProfile: %v", err)
+	}
+	if profile.Name != "team-a" || profile.IssuerID != "issuer-a" || profile.BundleID != "com.example.a" || !profile.Sandbox {
+		t.Errorf("profile = %+v", profile)
+	}
+	if got := profile.Keyring.Active().ID; got != "NEWKEY0002" {
+		t.Errorf("active key = %q", got)
+	}
+	if got := profile.Keyring.KeyIDs(); !reflect.DeepEqual(got, []string{"OLDKEY0001", "NEWKEY0002"}) {
+		t.Errorf("KeyIDs = %v", got)
+	}
+
+	other, err := LoadProfile(path, "team-b")
+	if err != nil {
+		t.Fatalf("LoadProfile(team-b): %v", err)
+	}
+	if other.IssuerID != "issuer-b" || other.Sandbox {
+		t.Errorf("team-b = %+v", other)
+	}
+	if _, err := LoadProfile(path, "team-c"); err == nil {
+		t.Error("unknown profile accepted")
+	}
+	names, _ := ProfileNames(path)
+	if !reflect.DeepEqual(names, []string{"team-a", "team-b"}) {
+		t.Errorf("ProfileNames = %v", names)
+	}
+}
+
+func TestLoadProfile_JSON(t *testing.T) {
+	dir := t.TempDir()
+	writeKeyFile(t, dir, "KEY0000001")
+	path := filepath.Join(dir, "apple.json")
+	writeFile(t, path, `{"profiles": {"only": {"issuerId": "iss", "keys": [{"path": "AuthKey_KEY0000001.p8"}]}}}`)
+
+	profile, err := LoadProfile(path, "")
+	if err != nil {
+		t.Fatalf("LoadProfile: %v", err)
+	}
+	if profile.Name != "only" || profile.Keyring.Active().ID != "KEY0000001" {
+		t.Errorf("profile = %+v", profile)
+	}
+
+	writeFile(t, path, `{"profiles": {"only": {"issuerId": "iss", "keyz": []}}}`)
+	if _, err := LoadProfile(path, ""); err == nil {
+		t.Error("unknown field accepted")
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	dir := t.TempDir()
+	first := writeKeyFile(t, dir, "FIRST00001")
+	second := writeKeyFile(t, dir, "SECOND0002")
+	t.Setenv(EnvProfileFile, "")
+	t.Setenv(EnvIssuerID, "iss")
+	t.Setenv(EnvBundleID, "com.example")
+	t.Setenv(EnvSandbox, "true")
+	t.Setenv(EnvPrivateKey, "")
+	t.Setenv(EnvPrivateKeyPath, first+string(os.PathListSeparator)+second)
+	t.Setenv(EnvKeyID, "SECOND0002")
+
+	profile, err := FromEnv()
+	if err != nil {
+		t.Fatalf("FromEnv: %v", err)
+	}
+	if !profile.Sandbox || profile.BundleID != "com.example" || profile.Keyring.Active().ID != "SECOND0002" {
+		t.Errorf("profile = %+v, active %q", profile, profile.Keyring.Active().ID)
+	}
+
+	t.Setenv(EnvPrivateKeyPath, "")
+	t.Setenv(EnvPrivateKey, string(newTestKeyPEM(t, elliptic.P256())))
+	t.Setenv(EnvKeyID, "INLINE0001")
+	if profile, err = FromEnv(); err != nil || profile.Keyring.Active().ID != "INLINE0001" {
+		t.Errorf("inline key: %v", err)
+	}
+
+	t.Setenv(EnvIssuerID, "")
+	if _, err := FromEnv(); err == nil {
+		t.Error("missing issuer accepted")
+	}
+}
+
+func TestProfile_NewClientFollowsActiveKey(t *testing.T) {
+	ring, _ := NewKeyring(newTestKey(t, "OLD"), newTestKey(t, "NEW"))
+	profile := &Profile{IssuerID: "iss", BundleID: "com.example", Keyring: ring}
+
+	var kids []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		kids = append(kids, tokenKeyID(t, strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")))
+	}))
+	defer srv.Close()
+
+	client := profile.NewClient().ForService(Apple.AppStoreServerClient, srv.URL)
+	params := Apple.RequestParams{Method: http.MethodGet, Path: "/"}
+	if err := client.Request(params); err != nil {
+		t.Fatal(err)
+	}
+	if err := ring.Activate("NEW"); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Request(params); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(kids, []string{"OLD", "NEW"}) {
+		t.Errorf("kids = %v", kids)
+	}
+}
+
+func TestProfile_NewClientSignsWithActiveKey(t *testing.T) {
+	oldKey, newKey := newTestKey(t, "OLD"), newTestKey(t, "NEW")
+	ring, _ := NewKeyring(oldKey, newKey)
+	client := (&Profile{IssuerID: "iss", BundleID: "com.example", Keyring: ring}).NewClient()
+
+	sign := func(want Key) {
+		t.Helper()
+		signed, err := client.SignJWS(jwt.MapClaims{"aud": "test"})
+		if err != nil {
+			t.Fatalf("SignJWS: %v", err)
+		}
+		token, err := jwt.Parse(signed, func(*jwt.Token) (any, error) { return want.Signer.Public(), nil },
+			jwt.WithValidMethods([]string{"ES256"}))
+		if err != nil {
+			t.Fatalf("JWS does not verify with key %s: %v", want.ID, err)
+		}
+		if token.Header["kid"] != want.ID {
+			t.Errorf("kid = %v, want %s", token.Header["kid"], want.ID)
+		}
+		scoped, err := client.GenerateAppStoreConnectAuthorizationJWT(http.MethodGet, "/v1/apps")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if kid := tokenKeyID(t, strings.TrimPrefix(scoped, "Bearer ")); kid != want.ID {
+			t.Errorf("scoped token kid = %s, want %s", kid, want.ID)
+		}
+	}
+
+	sign(oldKey)
+	if err := ring.Activate("NEW"); err != nil {
+		t.Fatal(err)
+	}
+	sign(newKey)
+}
@@ -0,0 +1,35 @@
+// Package entitlements answers "does this customer have access right
+// now?" from the data the App Store Server API returns, so every
+// caller of GetAllSubscriptionStatuses does not have to re-derive it.
+//
+// Input is decoded: [SubscriptionStatus] values (from a StatusResponse,
+// see [DecodeStatusResponse]) for auto-renewable subscriptions, and
+// transactions from the transaction history for everything else.
+// [Compute] turns them into one [Entitlement] per product and per
+// subscription group:
+//
+//	statuses, err := entitlements.DecodeStatusResponse(resp, nil)
+//	if err != nil {
+//	    return err
+//	}
+//	ent := entitlements.Compute(entitlements.Config{}, statuses, history)
+//	if ent.HasProduct("com.example.pro") {
+//	    // unlock
+//	}
+//	group, _ := ent.Group("21345678")
+//	log.Println(group.State, group.Reason, group.ExpiresDate, group.AutoRenew)
+//
+// The rules follow Apple's documented lifecycle:
+//
+//   - Active and grace-period subscriptions grant access until the
+//     expiry, respectively grace-period end; a billing-retry period
+//     does not.
+//   - Expired subscriptions report why through Reason, taken from the
+//     renewal info's expirationIntent.
+//   - Refunds, and the end of Family Sharing for shared purchases, revoke
+//     access immediately.
+//   - A subscription the customer upgraded from is [StateUpgraded]; the
+//     group's entitlement is the product upgraded to.
+//   - Family-shared purchases grant access like the purchaser's own,
+//     with FamilyShared set.
+package entitlements
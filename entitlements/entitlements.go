@@ -0,0 +1,408 @@
+package entitlements
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	AppStoreServer "github.com/godrealms/go-apple-sdk/app-store-server"
+	"github.com/godrealms/go-apple-sdk/jws"
+	"github.com/godrealms/go-apple-sdk/types"
+)
+
+// State Where a product is in its lifecycle when the entitlements are computed.
+type State string
+
+const (
+	StateActive       State = "ACTIVE"        // The customer has access.
+	StateGracePeriod  State = "GRACE_PERIOD"  // Renewal failed, but the customer keeps access until the grace period ends.
+	StateBillingRetry State = "BILLING_RETRY" // Renewal failed and Apple is still retrying; no access.
+	StateExpired      State = "EXPIRED"       // The subscription period ended.
+	StateRevoked      State = "REVOKED"       // Apple refunded the purchase or Family Sharing ended.
+	StateUpgraded     State = "UPGRADED"      // The customer upgraded to another product in the group.
+)
+
+// Reason Why a product is in its state.
+type Reason string
+
+const (
+	ReasonPurchased          Reason = "PURCHASED"           // Access through the customer's own purchase.
+	ReasonFamilyShared       Reason = "FAMILY_SHARED"       // Access through Family Sharing.
+	ReasonBillingIssue       Reason = "BILLING_ISSUE"       // Renewal failed to charge the customer.
+	ReasonCanceled           Reason = "CANCELED"            // The customer turned off automatic renewal.
+	ReasonPriceIncrease      Reason = "PRICE_INCREASE"      // The customer did not consent to a price increase.
+	ReasonProductUnavailable Reason = "PRODUCT_UNAVAILABLE" // The product was not for sale at renewal.
+	ReasonRefunded           Reason = "REFUNDED"            // Apple refunded the transaction.
+	ReasonFamilySharingEnded Reason = "FAMILY_SHARING_ENDED"
+	ReasonUpgraded           Reason = "UPGRADED"
+	ReasonPeriodEnded        Reason = "PERIOD_ENDED" // The period ended and no renewal is known; fetch a fresh status.
+	ReasonOther              Reason = "OTHER"
+)
+
+// SubscriptionStatus One decoded lastTransactions item of a StatusResponse.
+type SubscriptionStatus struct {
+	SubscriptionGroupIdentifier types.SubscriptionGroupIdentifier
+	Status                      types.Status
+	Transaction                 *types.JWSTransactionDecodedPayload
+	// RenewalInfo may be nil; auto-renew state and grace-period end are
+	// then unknown.
+	RenewalInfo *types.JWSRenewalInfoDecodedPayload
+}
+
+// DecodeStatusResponse verifies and decodes every lastTransactions item
+// of resp. v defaults to jws.DefaultVerifier().
+func DecodeStatusResponse(resp *AppStoreServer.StatusResponse, v *jws.Verifier) ([]SubscriptionStatus, error) {
+	if resp == nil {
+		return nil, nil
+	}
+	if v == nil {
+		v = jws.DefaultVerifier()
+	}
+	var statuses []SubscriptionStatus
+	for _, group := range resp.Data {
+		for _, item := range group.LastTransactions {
+			tx, err := item.SignedTransactionInfo.DecryptWith(v)
+			if err != nil {
+				return nil, fmt.Errorf("entitlements: decode transaction of %s: %w", item.OriginalTransactionId, err)
+			}
+			status := SubscriptionStatus{
+				SubscriptionGroupIdentifier: group.SubscriptionGroupIdentifier,
+				Status:                      item.Status,
+				Transaction:                 tx,
+			}
+			if item.SignedRenewalInfo != "" {
+				if status.RenewalInfo, err = item.SignedRenewalInfo.DecryptWith(v); err != nil {
+					return nil, fmt.Errorf("entitlements: decode renewal info of %s: %w", item.OriginalTransactionId, err)
+				}
+			}
+			statuses = append(statuses, status)
+		}
+	}
+	return statuses, nil
+}
+
+// Entitlement The access a customer has to one product or subscription group.
+type Entitlement struct {
+	ProductId                   types.ProductId
+	SubscriptionGroupIdentifier types.SubscriptionGroupIdentifier
+	ProductType                 types.ProductType
+	OriginalTransactionId       types.OriginalTransactionId
+	TransactionId               types.TransactionId
+
+	// Active reports whether the customer has access right now.
+	Active bool
+	State  State
+	Reason Reason
+
+	// ExpiresDate is when the current period ends; zero for products
+	// that do not expire.
+	ExpiresDate time.Time
+	// GracePeriodExpiresDate is when the billing grace period ends;
+	// zero outside a grace period.
+	GracePeriodExpiresDate time.Time
+	// AutoRenew reports whether the subscription renews at ExpiresDate.
+	AutoRenew bool
+	// AutoRenewProductId is the product the subscription renews as.
+	AutoRenewProductId types.ProductId
+	FamilyShared       bool
+
+	// The decoded data the entitlement was computed from.
+	Transaction *types.JWSTransactionDecodedPayload
+	RenewalInfo *types.JWSRenewalInfoDecodedPayload
+}
+
+// Config configures [Compute].
+type Config struct {
+	// Now returns the time access is evaluated at. Defaults to time.Now.
+	Now func() time.Time
+	// NonRenewingDuration returns how long a non-renewing subscription
+	// lasts from its purchase date; Apple leaves that to the app. When
+	// nil, or when it returns 0, non-renewing subscriptions never expire.
+	NonRenewingDuration func(types.ProductId) time.Duration
+}
+
+// Entitlements The result of [Compute].
+type Entitlements struct {
+	// Products holds the best entitlement for each product.
+	Products map[types.ProductId]Entitlement
+	// Groups holds the best entitlement for each subscription group.
+	Groups map[types.SubscriptionGroupIdentifier]Entitlement
+	// All holds one entitlement per subscription and per non-subscription
+	// purchase, ordered by product ID.
+	All []Entitlement
+}
+
+// Product returns the entitlement for productId.
+func (e *Entitlements) Product(productId types.ProductId) (Entitlement, bool) {
+	ent, ok := e.Products[productId]
+	return ent, ok
+}
+
+// HasProduct reports whether the customer has access to productId now.
+func (e *Entitlements) HasProduct(productId types.ProductId) bool {
+	return e.Products[productId].Active
+}
+
+// Group returns the entitlement for a subscription group.
+func (e *Entitlements) Group(group types.SubscriptionGroupIdentifier) (Entitlement, bool) {
+	ent, ok := e.Groups[group]
+	return ent, ok
+}
+
+// HasGroup reports whether the customer has access to any level of group now.
+func (e *Entitlements) HasGroup(group types.SubscriptionGroupIdentifier) bool {
+	return e.Groups[group].Active
+}
+
+// Active returns the active product entitlements, ordered by product ID.
+func (e *Entitlements) Active() []Entitlement {
+	var active []Entitlement
+	for _, ent := range e.Products {
+		if ent.Active {
+			active = append(active, ent)
+		}
+	}
+	sort.Slice(active, func(i, j int) bool { return active[i].ProductId < active[j].ProductId })
+	return active
+}
+
+// Compute derives entitlements from subscription statuses and history
+// transactions. A status wins over history transactions of the same
+// originalTransactionId; among history transactions of one
+// auto-renewable subscription the latest purchase wins. Consumables
+// grant no entitlement and are skipped.
+func Compute(cfg Config, statuses []SubscriptionStatus, transactions []*types.JWSTransactionDecodedPayload) *Entitlements {
+	now := time.Now()
+	if cfg.Now != nil {
+		now = cfg.Now()
+	}
+
+	var all []Entitlement
+	seen := map[types.OriginalTransactionId]bool{}
+	for _, s := range statuses {
+		if s.Transaction == nil {
+			continue
+		}
+		all = append(all, fromStatus(now, s))
+		seen[s.Transaction.OriginalTransactionId] = true
+	}
+
+	latest := map[types.OriginalTransactionId]*types.JWSTransactionDecodedPayload{}
+	var order []types.OriginalTransactionId
+	for _, tx := range transactions {
+		if tx == nil || seen[tx.OriginalTransactionId] {
+			continue
+		}
+		switch tx.Type {
+		case types.TRANSACTION_TYPE_CONSUMABLE:
+		case types.TRANSACTION_TYPE_AUTO_RENEWABLE_SUBSCRIPTION:
+			prev, ok := latest[tx.OriginalTransactionId]
+			if !ok {
+				order = append(order, tx.OriginalTransactionId)
+			}
+			if !ok || supersedes(tx, prev) {
+				latest[tx.OriginalTransactionId] = tx
+			}
+		default:
+			all = append(all, fromTransaction(cfg, now, tx))
+		}
+	}
+	for _, id := range order {
+		all = append(all, fromTransaction(cfg, now, latest[id]))
+	}
+
+	sort.SliceStable(all, func(i, j int) bool { return all[i].ProductId < all[j].ProductId })
+	result := &Entitlements{
+		Products: map[types.ProductId]Entitlement{},
+		Groups:   map[types.SubscriptionGroupIdentifier]Entitlement{},
+		All:      all,
+	}
+	for _, ent := range all {
+		if cur, ok := result.Products[ent.ProductId]; !ok || better(ent, cur) {
+			result.Products[ent.ProductId] = ent
+		}
+		if ent.SubscriptionGroupIdentifier == "" {
+			continue
+		}
+		if cur, ok := result.Groups[ent.SubscriptionGroupIdentifier]; !ok || better(ent, cur) {
+			result.Groups[ent.SubscriptionGroupIdentifier] = ent
+		}
+	}
+	return result
+}
+
+// supersedes reports whether tx is a later transaction of the same
+// subscription than prev. On equal purchase dates the transaction
+// upgraded to wins over the one upgraded from.
+func supersedes(tx, prev *types.JWSTransactionDecodedPayload) bool {
+	if tx.PurchaseDate != prev.PurchaseDate {
+		return tx.PurchaseDate > prev.PurchaseDate
+	}
+	return bool(prev.IsUpgraded) && !bool(tx.IsUpgraded)
+}
+
+// newEntitlement fills the fields every entitlement takes from its transaction.
+func newEntitlement(tx *types.JWSTransactionDecodedPayload) Entitlement {
+	return Entitlement{
+		ProductId:                   tx.ProductId,
+		SubscriptionGroupIdentifier: tx.SubscriptionGroupIdentifier,
+		ProductType:                 productType(tx.Type),
+		OriginalTransactionId:       tx.OriginalTransactionId,
+		TransactionId:               tx.TransactionId,
+		ExpiresDate:                 millis(tx.ExpiresDate),
+		FamilyShared:                tx.InAppOwnershipType == types.IN_APP_OWNERSHIP_TYPE_FAMILY_SHARED,
+		Transaction:                 tx,
+	}
+}
+
+func fromStatus(now time.Time, s SubscriptionStatus) Entitlement {
+	tx := s.Transaction
+	ent := newEntitlement(tx)
+	if ent.SubscriptionGroupIdentifier == "" {
+		ent.SubscriptionGroupIdentifier = s.SubscriptionGroupIdentifier
+	}
+	ent.ProductType = types.PRODUCT_TYPE_AUTO_RENEWABLE
+	if r := s.RenewalInfo; r != nil {
+		ent.RenewalInfo = r
+		ent.AutoRenew = r.AutoRenewStatus == 1
+		ent.AutoRenewProductId = types.ProductId(r.AutoRenewProductId)
+	}
+
+	switch {
+	case tx.RevocationDate != 0 || s.Status == types.StatusRevoked:
+		revoke(&ent)
+	case bool(tx.IsUpgraded):
+		ent.State, ent.Reason = StateUpgraded, ReasonUpgraded
+	case s.Status == types.StatusActive:
+		if now.Before(ent.ExpiresDate) {
+			grant(&ent)
+		} else {
+			ent.State, ent.Reason = StateExpired, ReasonPeriodEnded
+		}
+	case s.Status == types.StatusGracePeriod:
+		if s.RenewalInfo != nil {
+			ent.GracePeriodExpiresDate = millis(s.RenewalInfo.GracePeriodExpiresDate)
+		}
+		ent.State, ent.Reason = StateBillingRetry, ReasonBillingIssue
+		if now.Before(ent.GracePeriodExpiresDate) {
+			ent.Active, ent.State = true, StateGracePeriod
+		}
+	case s.Status == types.StatusRetryPeriod:
+		ent.State, ent.Reason = StateBillingRetry, ReasonBillingIssue
+	default:
+		ent.State, ent.Reason = StateExpired, expirationReason(s.RenewalInfo)
+	}
+	return ent
+}
+
+func fromTransaction(cfg Config, now time.Time, tx *types.JWSTransactionDecodedPayload) Entitlement {
+	ent := newEntitlement(tx)
+	if ent.ProductType == types.PRODUCT_TYPE_NON_RENEWABLE && ent.ExpiresDate.IsZero() && cfg.NonRenewingDuration != nil {
+		if d := cfg.NonRenewingDuration(tx.ProductId); d > 0 {
+			ent.ExpiresDate = millis(tx.PurchaseDate).Add(d)
+		}
+	}
+	switch {
+	case tx.RevocationDate != 0:
+		revoke(&ent)
+	case bool(tx.IsUpgraded):
+		ent.State, ent.Reason = StateUpgraded, ReasonUpgraded
+	case ent.ExpiresDate.IsZero() && ent.ProductType != types.PRODUCT_TYPE_AUTO_RENEWABLE:
+		grant(&ent)
+	case now.Before(ent.ExpiresDate):
+		// History alone does not say whether the subscription renews,
+		// so AutoRenew stays false.
+		grant(&ent)
+	default:
+		ent.State, ent.Reason = StateExpired, ReasonPeriodEnded
+	}
+	return ent
+}
+
+func grant(ent *Entitlement) {
+	ent.Active, ent.State, ent.Reason = true, StateActive, ReasonPurchased
+	if ent.FamilyShared {
+		ent.Reason = ReasonFamilyShared
+	}
+}
+
+func revoke(ent *Entitlement) {
+	ent.Active, ent.State, ent.Reason, ent.AutoRenew = false, StateRevoked, ReasonRefunded, false
+	if ent.FamilyShared {
+		ent.Reason = ReasonFamilySharingEnded
+	}
+}
+
+// expirationReason maps the renewal info's expirationIntent to a Reason.
+func expirationReason(r *types.JWSRenewalInfoDecodedPayload) Reason {
+	if r == nil {
+		return ReasonOther
+	}
+	switch r.ExpirationIntent {
+	case 1:
+		return ReasonCanceled
+	case 2:
+		return ReasonBillingIssue
+	case 3:
+		return ReasonPriceIncrease
+	case 4:
+		return ReasonProductUnavailable
+	}
+	return ReasonOther
+}
+
+// rank orders states from most to least access.
+func rank(ent Entitlement) int {
+	switch {
+	case ent.Active:
+		return 0
+	case ent.State == StateBillingRetry:
+		return 1
+	case ent.State == StateExpired:
+		return 2
+	case ent.State == StateRevoked:
+		return 3
+	}
+	return 4
+}
+
+// better reports whether a should be preferred over b for the same
+// product or group: more access first, then the later expiry (no
+// expiry counts as latest), then the customer's own purchase.
+func better(a, b Entitlement) bool {
+	if ra, rb := rank(a), rank(b); ra != rb {
+		return ra < rb
+	}
+	if !a.ExpiresDate.Equal(b.ExpiresDate) {
+		switch {
+		case a.ExpiresDate.IsZero():
+			return true
+		case b.ExpiresDate.IsZero():
+			return false
+		}
+		return a.ExpiresDate.After(b.ExpiresDate)
+	}
+	return !a.FamilyShared && b.FamilyShared
+}
+
+func productType(t string) types.ProductType {
+	switch t {
+	case types.TRANSACTION_TYPE_AUTO_RENEWABLE_SUBSCRIPTION:
+		return types.PRODUCT_TYPE_AUTO_RENEWABLE
+	case types.TRANSACTION_TYPE_NON_RENEWING_SUBSCRIPTION:
+		return types.PRODUCT_TYPE_NON_RENEWABLE
+	case types.TRANSACTION_TYPE_NON_CONSUMABLE:
+		return types.PRODUCT_TYPE_NON_CONSUMABLE
+	case types.TRANSACTION_TYPE_CONSUMABLE:
+		return types.PRODUCT_TYPE_CONSUMABLE
+	}
+	return ""
+}
+
+// millis converts an Apple millisecond timestamp; 0 stays the zero time.
+func millis(ts types.Timestamp) time.Time {
+	if ts == 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(int64(ts)).UTC()
+}
@@ -0,0 +1,289 @@
+package entitlements
+
+import (
+	"testing"
+	"time"
+
+	AppStoreServer "github.com/godrealms/go-apple-sdk/app-store-server"
+	"github.com/godrealms/go-apple-sdk/internal/testchain"
+	"github.com/godrealms/go-apple-sdk/jws"
+	"github.com/godrealms/go-apple-sdk/types"
+)
+
+var testNow = time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func ts(t time.Time) types.Timestamp { return types.Timestamp(t.UnixMilli()) }
+
+func subTx(product string, expires time.Time) *types.JWSTransactionDecodedPayload {
+	return &types.JWSTransactionDecodedPayload{
+		OriginalTransactionId:       "1000",
+		TransactionId:               "1001",
+		ProductId:                   types.ProductId(product),
+		SubscriptionGroupIdentifier: "group",
+		Type:                        types.TRANSACTION_TYPE_AUTO_RENEWABLE_SUBSCRIPTION,
+		InAppOwnershipType:          types.IN_APP_OWNERSHIP_TYPE_PURCHASED,
+		PurchaseDate:                ts(expires.AddDate(0, -1, 0)),
+		ExpiresDate:                 ts(expires),
+	}
+}
+
+func renewal(autoRenew bool) *types.JWSRenewalInfoDecodedPayload {
+	r := &types.JWSRenewalInfoDecodedPayload{AutoRenewProductId: "monthly"}
+	if autoRenew {
+		r.AutoRenewStatus = 1
+	}
+	return r
+}
+
+func TestCompute_SubscriptionLifecycle(t *testing.T) {
+	future, past := testNow.Add(72*time.Hour), testNow.Add(-72*time.Hour)
+	tests := []struct {
+		name   string
+		status SubscriptionStatus
+		active bool
+		state  State
+		reason Reason
+		renew  bool
+	}{
+		{
+			name:   "active",
+			status: SubscriptionStatus{Status: types.StatusActive, Transaction: subTx("monthly", future), RenewalInfo: renewal(true)},
+			active: true, state: StateActive, reason: ReasonPurchased, renew: true,
+		},
+		{
+			name:   "active with auto-renew off",
+			status: SubscriptionStatus{Status: types.StatusActive, Transaction: subTx("monthly", future), RenewalInfo: renewal(false)},
+			active: true, state: StateActive, reason: ReasonPurchased,
+		},
+		{
+			name:   "stale active status past expiry",
+			status: SubscriptionStatus{Status: types.StatusActive, Transaction: subTx("monthly", past), RenewalInfo: renewal(true)},
+			state:  StateExpired, reason: ReasonPeriodEnded, renew: true,
+		},
+		{
+			name: "family shared",
+			status: func() SubscriptionStatus {
+				tx := subTx("monthly", future)
+				tx.InAppOwnershipType = types.IN_APP_OWNERSHIP_TYPE_FAMILY_SHARED
+				return SubscriptionStatus{Status: types.StatusActive, Transaction: tx}
+			}(),
+			active: true, state: StateActive, reason: ReasonFamilyShared,
+		},
+		{
+			name: "grace period",
+			status: func() SubscriptionStatus {
+				r := renewal(true)
+				r.GracePeriodExpiresDate = ts(future)
+				r.IsInBillingRetryPeriod = true
+				return SubscriptionStatus{Status: types.StatusGracePeriod, Transaction: subTx("monthly", past), RenewalInfo: r}
+			}(),
+			active: true, state: StateGracePeriod, reason: ReasonBillingIssue, renew: true,
+		},
+		{
+			name: "grace period ended",
+			status: func() SubscriptionStatus {
+				r := renewal(true)
+				r.GracePeriodExpiresDate = ts(past)
+				return SubscriptionStatus{Status: types.StatusGracePeriod, Transaction: subTx("monthly", past), RenewalInfo: r}
+			}(),
+			state: StateBillingRetry, reason: ReasonBillingIssue, renew: true,
+		},
+		{
+			name:   "billing retry",
+			status: SubscriptionStatus{Status: types.StatusRetryPeriod, Transaction: subTx("monthly", past), RenewalInfo: renewal(true)},
+			state:  StateBillingRetry, reason: ReasonBillingIssue, renew: true,
+		},
+		{
+			name: "expired voluntarily",
+			status: func() SubscriptionStatus {
+				r := renewal(false)
+				r.ExpirationIntent = 1
+				return SubscriptionStatus{Status: types.StatusExpired, Transaction: subTx("monthly", past), RenewalInfo: r}
+			}(),
+			state: StateExpired, reason: ReasonCanceled,
+		},
+		{
+			name: "expired after billing retry",
+			status: func() SubscriptionStatus {
+				r := renewal(false)
+				r.ExpirationIntent = 2
+				return SubscriptionStatus{Status: types.StatusExpired, Transaction: subTx("monthly", past), RenewalInfo: r}
+			}(),
+			state: StateExpired, reason: ReasonBillingIssue,
+		},
+		{
+			name: "expired on price increase",
+			status: func() SubscriptionStatus {
+				r := renewal(false)
+				r.ExpirationIntent = 3
+				return SubscriptionStatus{Status: types.StatusExpired, Transaction: subTx("monthly", past), RenewalInfo: r}
+			}(),
+			state: StateExpired, reason: ReasonPriceIncrease,
+		},
+		{
+			name: "expired, product unavailable",
+			status: func() SubscriptionStatus {
+				r := renewal(false)
+				r.ExpirationIntent = 4
+				return SubscriptionStatus{Status: types.StatusExpired, Transaction: subTx("monthly", past), RenewalInfo: r}
+			}(),
+			state: StateExpired, reason: ReasonProductUnavailable,
+		},
+		{
+			name: "refunded",
+			status: func() SubscriptionStatus {
+				tx := subTx("monthly", future)
+				tx.RevocationDate = ts(past)
+				return SubscriptionStatus{Status: types.StatusRevoked, Transaction: tx, RenewalInfo: renewal(true)}
+			}(),
+			state: StateRevoked, reason: ReasonRefunded,
+		},
+		{
+			name: "family sharing ended",
+			status: func() SubscriptionStatus {
+				tx := subTx("monthly", future)
+				tx.InAppOwnershipType = types.IN_APP_OWNERSHIP_TYPE_FAMILY_SHARED
+				tx.RevocationDate = ts(past)
+				return SubscriptionStatus{Status: types.StatusRevoked, Transaction: tx}
+			}(),
+			state: StateRevoked, reason: ReasonFamilySharingEnded,
+		},
+		{
+			name: "upgraded from",
+			status: func() SubscriptionStatus {
+				tx := subTx("monthly", future)
+				tx.IsUpgraded = true
+				return SubscriptionStatus{Status: types.StatusActive, Transaction: tx}
+			}(),
+			state: StateUpgraded, reason: ReasonUpgraded,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ent := Compute(Config{Now: func() time.Time { return testNow }}, []SubscriptionStatus{tt.status}, nil)
+			got, ok := ent.Product("monthly")
+			if !ok {
+				t.Fatal("no entitlement for product")
+			}
+			if got.Active != tt.active || got.State != tt.state || got.Reason != tt.reason || got.AutoRenew != tt.renew {
+				t.Errorf("got active=%v state=%s reason=%s renew=%v, want %v %s %s %v",
+					got.Active, got.State, got.Reason, got.AutoRenew, tt.active, tt.state, tt.reason, tt.renew)
+			}
+			if ent.HasProduct("monthly") != tt.active || ent.HasGroup("group") != tt.active {
+				t.Errorf("HasProduct/HasGroup disagree with Active=%v", tt.active)
+			}
+		})
+	}
+}
+
+func TestCompute_GroupPrefersUpgradedToProduct(t *testing.T) {
+	future := testNow.Add(72 * time.Hour)
+	basic := subTx("basic", future)
+	basic.IsUpgraded = true
+	premium := subTx("premium", future)
+	premium.OriginalTransactionId = "2000"
+	ent := Compute(Config{Now: func() time.Time { return testNow }}, []SubscriptionStatus{
+		{Status: types.StatusActive, Transaction: basic},
+		{Status: types.StatusActive, Transaction: premium, RenewalInfo: renewal(true)},
+	}, nil)
+
+	group, ok := ent.Group("group")
+	if !ok || group.ProductId != "premium" || !group.Active {
+		t.Errorf("group = %+v", group)
+	}
+	if ent.HasProduct("basic") || !ent.HasProduct("premium") {
+		t.Errorf("products = %+v", ent.Products)
+	}
+}
+
+func TestCompute_HistoryTransactions(t *testing.T) {
+	future, past := testNow.Add(72*time.Hour), testNow.Add(-72*time.Hour)
+	purchase := func(id, product, kind string) *types.JWSTransactionDecodedPayload {
+		return &types.JWSTransactionDecodedPayload{
+			OriginalTransactionId: types.OriginalTransactionId(id),
+			TransactionId:         types.TransactionId(id),
+			ProductId:             types.ProductId(product),
+			Type:                  kind,
+			InAppOwnershipType:    types.IN_APP_OWNERSHIP_TYPE_PURCHASED,
+			PurchaseDate:          ts(testNow.AddDate(0, -2, 0)),
+		}
+	}
+	lifetime := purchase("1", "lifetime", types.TRANSACTION_TYPE_NON_CONSUMABLE)
+	refunded := purchase("2", "refunded", types.TRANSACTION_TYPE_NON_CONSUMABLE)
+	refunded.RevocationDate = ts(past)
+	coins := purchase("3", "coins", types.TRANSACTION_TYPE_CONSUMABLE)
+	season := purchase("4", "season", types.TRANSACTION_TYPE_NON_RENEWING_SUBSCRIPTION)
+	forever := purchase("5", "forever", types.TRANSACTION_TYPE_NON_RENEWING_SUBSCRIPTION)
+	renewed := subTx("yearly", future)
+	renewed.OriginalTransactionId, renewed.TransactionId = "6", "62"
+	lapsed := subTx("yearly", past)
+	lapsed.OriginalTransactionId, lapsed.TransactionId = "6", "61"
+	covered := subTx("monthly", past) // superseded by the status below
+
+	statusTx := subTx("monthly", future)
+	ent := Compute(Config{
+		Now: func() time.Time { return testNow },
+		NonRenewingDuration: func(p types.ProductId) time.Duration {
+			if p == "season" {
+				return 30 * 24 * time.Hour
+			}
+			return 0
+		},
+	}, []SubscriptionStatus{{Status: types.StatusActive, Transaction: statusTx, RenewalInfo: renewal(true)}},
+		[]*types.JWSTransactionDecodedPayload{lifetime, refunded, coins, season, forever, renewed, lapsed, covered})
+
+	want := map[types.ProductId]State{
+		"lifetime": StateActive,
+		"refunded": StateRevoked,
+		"season":   StateExpired,
+		"forever":  StateActive,
+		"yearly":   StateActive,
+		"monthly":  StateActive,
+	}
+	for product, state := range want {
+		if got, ok := ent.Product(product); !ok || got.State != state {
+			t.Errorf("%s: state = %q (found %v), want %q", product, got.State, ok, state)
+		}
+	}
+	if _, ok := ent.Product("coins"); ok {
+		t.Error("consumable produced an entitlement")
+	}
+	if got := ent.Products["yearly"]; got.TransactionId != "62" {
+		t.Errorf("yearly from transaction %s, want the latest renewal", got.TransactionId)
+	}
+	if got := ent.Products["monthly"]; got.RenewalInfo == nil || !got.AutoRenew {
+		t.Errorf("monthly = %+v, want the status to win over history", got)
+	}
+	if len(ent.All) != 6 || len(ent.Active()) != 4 {
+		t.Errorf("All = %d, Active = %d", len(ent.All), len(ent.Active()))
+	}
+}
+
+func TestDecodeStatusResponse(t *testing.T) {
+	chain := testchain.New(t)
+	verifier := jws.NewVerifier(jws.WithRootCAs(chain.RootPool), jws.WithRequiredOIDs(jws.OIDAppleReceiptSigning))
+	tx := subTx("monthly", testNow.Add(time.Hour))
+	resp := &AppStoreServer.StatusResponse{Data: []AppStoreServer.SubscriptionGroupIdentifierItem{{
+		SubscriptionGroupIdentifier: "group",
+		LastTransactions: []AppStoreServer.LastTransactionsItem{{
+			OriginalTransactionId: "1000",
+			Status:                types.StatusActive,
+			SignedTransactionInfo: types.JWSTransaction(chain.SignJWS(t, tx)),
+			SignedRenewalInfo:     types.JWSRenewalInfo(chain.SignJWS(t, renewal(true))),
+		}},
+	}}}
+
+	statuses, err := DecodeStatusResponse(resp, verifier)
+	if err != nil {
+		t.Fatalf("DecodeStatusResponse: %v", err)
+	}
+	if len(statuses) != 1 || statuses[0].Transaction.ProductId != "monthly" || statuses[0].RenewalInfo.AutoRenewStatus != 1 ||
+		statuses[0].SubscriptionGroupIdentifier != "group" {
+		t.Errorf("statuses = %+v", statuses)
+	}
+
+	resp.Data[0].LastTransactions[0].SignedTransactionInfo = "a.b.c"
+	if _, err := DecodeStatusResponse(resp, verifier); err == nil {
+		t.Error("tampered transaction accepted")
+	}
+}
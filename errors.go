@@ -0,0 +1,28 @@
+package Apple
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// HTTPError is returned by [Client.Request] when Apple responds with a
+// non-2xx status. It carries the raw status, headers and body so that
+// API-specific packages can decode the error format of their own API
+// (see AppStoreServer.APIError for the App Store Server API).
+type HTTPError struct {
+	// StatusCode is the HTTP status code returned by Apple.
+	StatusCode int
+	// Header holds the response headers (e.g. Retry-After).
+	Header http.Header
+	// Body is the raw response body.
+	Body []byte
+}
+
+// Error implements the error interface.
+func (e *HTTPError) Error() string {
+	if len(e.Body) == 0 {
+		return fmt.Sprintf("apple: HTTP %d", e.StatusCode)
+	}
+	return fmt.Sprintf("apple: HTTP %d: %s", e.StatusCode, strings.TrimSpace(string(e.Body)))
+}
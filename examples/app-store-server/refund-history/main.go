@@ -15,19 +15,13 @@ func main() {
 	privateKey := ""    // Your private key
 	transactionId := "" // Transaction ID
 	client := Apple.NewClient(true, kid, iss, bid, privateKey)
-	response, err := AppStoreServer.GetRefundHistory(context.Background(), client, transactionId)
-	if err != nil {
-		log.Fatal(err)
-		return
+	// DecodedRefundHistory follows the revision token past Apple's 20-per-page
+	// limit and verifies each refunded transaction.
+	it := AppStoreServer.NewService(client).DecodedRefundHistory(transactionId)
+	for it.Next(context.Background()) {
+		log.Printf("transaction: %+v\n", it.Value())
 	}
-	log.Println("HasMore: ", response.HasMore)
-	log.Println("Revision: ", response.Revision)
-	for _, transaction := range response.SignedTransactions {
-		decrypt, decryptErr := transaction.Decrypt()
-		if decryptErr != nil {
-			log.Fatalln(decryptErr)
-			return
-		}
-		log.Printf("transaction: %+v\n", decrypt)
+	if err := it.Err(); err != nil {
+		log.Fatalln(err)
 	}
 }
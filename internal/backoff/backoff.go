@@ -0,0 +1,48 @@
+// Package backoff holds the retry helpers shared by the App Store
+// Server and App Store Connect clients.
+//
+// The package lives under internal/ on purpose: each client exposes
+// its own retry configuration, and these helpers are not part of the
+// SDK's public API.
+package backoff
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseRetryAfter accepts both forms RFC 9110 allows for a Retry-After
+// header: delay-seconds and an HTTP-date. It returns zero for an empty,
+// malformed, negative or past value.
+func ParseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
+
+// Sleep waits for d or until ctx is done, returning ctx.Err() in the
+// latter case.
+func Sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
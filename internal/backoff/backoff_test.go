@@ -0,0 +1,35 @@
+package backoff
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	if got := ParseRetryAfter("30", now); got != 30*time.Second {
+		t.Errorf("seconds = %v", got)
+	}
+	if got := ParseRetryAfter(now.Add(time.Minute).Format(http.TimeFormat), now); got != time.Minute {
+		t.Errorf("http-date = %v", got)
+	}
+	for _, v := range []string{"", "-1", "soon", now.Add(-time.Minute).Format(http.TimeFormat)} {
+		if got := ParseRetryAfter(v, now); got != 0 {
+			t.Errorf("ParseRetryAfter(%q) = %v, want 0", v, got)
+		}
+	}
+}
+
+func TestSleep(t *testing.T) {
+	if err := Sleep(context.Background(), time.Millisecond); err != nil {
+		t.Errorf("Sleep = %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := Sleep(ctx, time.Hour); !errors.Is(err, context.Canceled) {
+		t.Errorf("Sleep on cancelled ctx = %v", err)
+	}
+}
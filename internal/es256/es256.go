@@ -0,0 +1,28 @@
+// Package es256 holds the key rule shared by the root client and the
+// credentials package: App Store Connect API keys are ECDSA P-256 keys,
+// used to sign ES256 tokens.
+package es256
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"errors"
+	"fmt"
+)
+
+// ValidateSigner checks that signer holds a P-256 ECDSA key, the only
+// kind App Store Connect API keys can be.
+func ValidateSigner(signer crypto.Signer) error {
+	if signer == nil {
+		return errors.New("signer is nil")
+	}
+	pub, ok := signer.Public().(*ecdsa.PublicKey)
+	if !ok {
+		return fmt.Errorf("signer public key is %T, want *ecdsa.PublicKey", signer.Public())
+	}
+	if pub.Curve != elliptic.P256() {
+		return fmt.Errorf("signer key is on curve %s, want P-256", pub.Curve.Params().Name)
+	}
+	return nil
+}
@@ -0,0 +1,11 @@
+// Package redact holds the secret-matching rules shared by the root
+// client's logger and the replay recorder, so log records and recorded
+// fixtures mask the same values.
+package redact
+
+import "regexp"
+
+// JWSPattern matches a compact JWS/JWT: three base64url segments whose
+// header starts with `{"` (base64url "eyJ"). The signature segment may
+// be empty for unsecured tokens.
+var JWSPattern = regexp.MustCompile(`eyJ[A-Za-z0-9_-]*\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)
@@ -3,9 +3,9 @@ package jws
 import (
 	"encoding/base64"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"strings"
+	"time"
 )
 
 // VerifyAndDecode is the single verification entry point. It
@@ -55,19 +55,11 @@ func VerifyAndDecode[T any](v *Verifier, raw string) (*T, error) {
 		return nil, err
 	}
 
-	// 4. Chain validation.
-	if err := verifyChain(chain, v.roots, v.clock()); err != nil {
+	// 4-5. Chain validation and OID check.
+	if err := v.VerifyChain(chain, time.Time{}); err != nil {
 		return nil, err
 	}
 
-	// 5. OID check.
-	if !matchOID(chain[0].Extensions, v.requiredOIDs) {
-		return nil, &VerificationError{
-			Reason: ReasonOID,
-			Cause:  errors.New("leaf cert carries none of the required OIDs"),
-		}
-	}
-
 	// 6. Signature verification — BEFORE payload JSON decode so we
 	// never run a JSON parser on untrusted bytes.
 	sig, err := base64.RawURLEncoding.DecodeString(sigB64)
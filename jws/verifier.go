@@ -3,6 +3,7 @@ package jws
 import (
 	"crypto/x509"
 	"encoding/asn1"
+	"errors"
 	"time"
 )
 
@@ -61,3 +62,26 @@ func WithRequiredOIDs(oids ...asn1.ObjectIdentifier) Option {
 func WithClock(now func() time.Time) Option {
 	return func(v *Verifier) { v.clock = now }
 }
+
+// VerifyChain runs the certificate checks of VerifyAndDecode — path
+// validation to the trust anchors and the leaf OID check — on a chain
+// that did not arrive in a JWS x5c header, such as the certificates
+// embedded in a PKCS#7 app receipt. chain[0] must be the leaf.
+//
+// The chain is checked as of at, or the Verifier's clock when at is
+// the zero time. Failures are *VerificationError.
+func (v *Verifier) VerifyChain(chain []*x509.Certificate, at time.Time) error {
+	if at.IsZero() {
+		at = v.clock()
+	}
+	if err := verifyChain(chain, v.roots, at); err != nil {
+		return err
+	}
+	if !matchOID(chain[0].Extensions, v.requiredOIDs) {
+		return &VerificationError{
+			Reason: ReasonOID,
+			Cause:  errors.New("leaf cert carries none of the required OIDs"),
+		}
+	}
+	return nil
+}
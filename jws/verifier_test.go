@@ -5,6 +5,8 @@ import (
 	"encoding/asn1"
 	"testing"
 	"time"
+
+	"github.com/godrealms/go-apple-sdk/internal/testchain"
 )
 
 func TestNewVerifier_Defaults(t *testing.T) {
@@ -62,3 +64,19 @@ func TestNewVerifier_OptionsCompose(t *testing.T) {
 		t.Fatalf("options did not all apply")
 	}
 }
+
+func TestVerifier_VerifyChain(t *testing.T) {
+	tc := testchain.New(t, testchain.WithLeafNotBefore(time.Now().Add(time.Hour)))
+	chain := []*x509.Certificate{tc.Leaf, tc.Intermediate}
+	v := NewVerifier(WithRootCAs(tc.RootPool))
+
+	// The leaf is not yet valid by the Verifier's clock but is at an
+	// explicit instant inside its validity window.
+	assertReason(t, v.VerifyChain(chain, time.Time{}), ReasonExpired)
+	if err := v.VerifyChain(chain, time.Now().Add(2*time.Hour)); err != nil {
+		t.Fatalf("VerifyChain at validity window: %v", err)
+	}
+
+	other := NewVerifier(WithRootCAs(tc.RootPool), WithRequiredOIDs(asn1.ObjectIdentifier{1, 2, 3}))
+	assertReason(t, other.VerifyChain(chain, time.Now().Add(2*time.Hour)), ReasonOID)
+}
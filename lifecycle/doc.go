@@ -0,0 +1,36 @@
+// Package lifecycle turns App Store Server Notifications V2 into a
+// per-subscription state machine, so consumers do not each have to
+// interpret sequences such as DID_FAIL_TO_RENEW → GRACE_PERIOD_EXPIRED
+// → DID_RENEW (BILLING_RECOVERY).
+//
+// Every auto-renewable subscription, keyed by its originalTransactionId,
+// has a [Record]: the notifications applied so far, in signedDate order,
+// and the resulting [State]. Applying a notification returns typed
+// [Transition] values (trial started, converted, churned, recovered,
+// refunded, upgraded, downgraded, ...):
+//
+//	machine := lifecycle.New(lifecycle.Config{Store: store})
+//
+//	http.HandleFunc("/apple/notifications", func(w http.ResponseWriter, r *http.Request) {
+//	    var body AppStoreNotifications.NotificationsResponseBodyV2
+//	    _ = json.NewDecoder(r.Body).Decode(&body)
+//	    payload, err := body.SignedPayload.DecodedPayload()
+//	    if err != nil {
+//	        w.WriteHeader(http.StatusBadRequest)
+//	        return
+//	    }
+//	    transitions, err := machine.ApplyNotification(r.Context(), payload)
+//	    ...
+//	})
+//
+// Apple retries deliveries and does not guarantee their order. A
+// notification whose notificationUUID was already applied is ignored.
+// One that arrives after a later-signed notification is slotted into
+// place and the record is replayed; transitions that only appear
+// because of it are returned with Late set. Records keep at most
+// Config.MaxEvents notifications; older ones are folded into a base
+// state, and notifications signed before that base are dropped.
+//
+// Records are plain JSON-serialisable values. Persist them through
+// the small [Store] interface; [MemoryStore] keeps them in memory.
+package lifecycle
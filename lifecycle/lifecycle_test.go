@@ -0,0 +1,278 @@
+package lifecycle
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+
+	AppStoreNotifications "github.com/godrealms/go-apple-sdk/app-store-server-notifications"
+	"github.com/godrealms/go-apple-sdk/internal/testchain"
+	"github.com/godrealms/go-apple-sdk/jws"
+	"github.com/godrealms/go-apple-sdk/types"
+)
+
+// ev builds the n-th notification of subscription "1000".
+func ev(n int, typ types.NotificationType, subtype types.Subtype) Event {
+	return Event{
+		NotificationUUID:      types.UUID(fmt.Sprintf("00000000-0000-4000-8000-%012d", n)),
+		SignedDate:            types.Timestamp(1_700_000_000_000 + int64(n)*1000),
+		NotificationType:      typ,
+		Subtype:               subtype,
+		OriginalTransactionId: "1000",
+		ProductId:             "monthly",
+	}
+}
+
+func trial(e Event) Event { e.FreeTrial = true; return e }
+
+func kinds(ts []Transition) []TransitionKind {
+	var out []TransitionKind
+	for _, t := range ts {
+		out = append(out, t.Kind)
+	}
+	return out
+}
+
+func TestRecord_Sequences(t *testing.T) {
+	tests := []struct {
+		name   string
+		events []Event
+		want   []TransitionKind
+		phase  Phase
+	}{
+		{
+			name: "trial converts",
+			events: []Event{
+				trial(ev(1, types.NOTIFICATION_TYPE_SUBSCRIBED, types.SUBTYPE_INITIAL_BUY)),
+				ev(2, types.NOTIFICATION_TYPE_DID_RENEW, ""),
+				ev(3, types.NOTIFICATION_TYPE_DID_RENEW, ""),
+			},
+			want:  []TransitionKind{TransitionTrialStarted, TransitionConverted, TransitionRenewed},
+			phase: PhaseActive,
+		},
+		{
+			name: "trial churns voluntarily",
+			events: []Event{
+				trial(ev(1, types.NOTIFICATION_TYPE_SUBSCRIBED, types.SUBTYPE_INITIAL_BUY)),
+				ev(2, types.NOTIFICATION_TYPE_DID_CHANGE_RENEWAL_STATUS, types.SUBTYPE_AUTO_RENEW_DISABLED),
+				ev(3, types.NOTIFICATION_TYPE_EXPIRED, types.SUBTYPE_VOLUNTARY),
+			},
+			want:  []TransitionKind{TransitionTrialStarted, TransitionAutoRenewDisabled, TransitionChurnedVoluntary},
+			phase: PhaseExpired,
+		},
+		{
+			name: "grace period, then billing recovery",
+			events: []Event{
+				ev(1, types.NOTIFICATION_TYPE_SUBSCRIBED, types.SUBTYPE_INITIAL_BUY),
+				ev(2, types.NOTIFICATION_TYPE_DID_FAIL_TO_RENEW, types.SUBTYPE_GRACE_PERIOD),
+				ev(3, types.NOTIFICATION_TYPE_GRACE_PERIOD_EXPIRED, ""),
+				ev(4, types.NOTIFICATION_TYPE_DID_RENEW, types.SUBTYPE_BILLING_RECOVERY),
+			},
+			want:  []TransitionKind{TransitionSubscribed, TransitionBillingIssue, TransitionGracePeriodExpired, TransitionRecovered},
+			phase: PhaseActive,
+		},
+		{
+			name: "billing retry runs out",
+			events: []Event{
+				ev(1, types.NOTIFICATION_TYPE_SUBSCRIBED, types.SUBTYPE_INITIAL_BUY),
+				ev(2, types.NOTIFICATION_TYPE_DID_FAIL_TO_RENEW, ""),
+				ev(3, types.NOTIFICATION_TYPE_EXPIRED, types.SUBTYPE_BILLING_RETRY),
+				ev(4, types.NOTIFICATION_TYPE_SUBSCRIBED, types.SUBTYPE_RESUBSCRIBE),
+			},
+			want:  []TransitionKind{TransitionSubscribed, TransitionBillingIssue, TransitionChurnedInvoluntary, TransitionResubscribed},
+			phase: PhaseActive,
+		},
+		{
+			name: "upgrade, downgrade, refund",
+			events: []Event{
+				ev(1, types.NOTIFICATION_TYPE_SUBSCRIBED, types.SUBTYPE_INITIAL_BUY),
+				ev(2, types.NOTIFICATION_TYPE_DID_CHANGE_RENEWAL_PREF, types.SUBTYPE_UPGRADE),
+				ev(3, types.NOTIFICATION_TYPE_DID_CHANGE_RENEWAL_PREF, types.SUBTYPE_DOWNGRADE),
+				ev(4, types.NOTIFICATION_TYPE_REFUND, ""),
+			},
+			want:  []TransitionKind{TransitionSubscribed, TransitionUpgraded, TransitionDowngraded, TransitionRefunded},
+			phase: PhaseRefunded,
+		},
+		{
+			name: "family sharing revoked",
+			events: []Event{
+				ev(1, types.NOTIFICATION_TYPE_SUBSCRIBED, types.SUBTYPE_INITIAL_BUY),
+				ev(2, types.NOTIFICATION_TYPE_REVOKE, ""),
+			},
+			want:  []TransitionKind{TransitionSubscribed, TransitionRevoked},
+			phase: PhaseRevoked,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var r Record
+			var got []TransitionKind
+			for _, e := range tt.events {
+				out, applied := r.Apply(e, 0)
+				if !applied {
+					t.Fatalf("%s not applied", e.NotificationType)
+				}
+				got = append(got, kinds(out)...)
+			}
+			if !reflect.DeepEqual(got, tt.want) || r.State.Phase != tt.phase {
+				t.Errorf("got %v, phase %s; want %v, phase %s", got, r.State.Phase, tt.want, tt.phase)
+			}
+		})
+	}
+}
+
+func TestRecord_DuplicatesAndOutOfOrder(t *testing.T) {
+	var r Record
+	subscribed := ev(1, types.NOTIFICATION_TYPE_SUBSCRIBED, types.SUBTYPE_INITIAL_BUY)
+	failed := ev(2, types.NOTIFICATION_TYPE_DID_FAIL_TO_RENEW, types.SUBTYPE_GRACE_PERIOD)
+	renewed := ev(3, types.NOTIFICATION_TYPE_DID_RENEW, "")
+
+	r.Apply(subscribed, 0)
+	if out, _ := r.Apply(renewed, 0); !reflect.DeepEqual(kinds(out), []TransitionKind{TransitionRenewed}) {
+		t.Fatalf("renewed = %v", kinds(out))
+	}
+	if out, applied := r.Apply(renewed, 0); applied || out != nil {
+		t.Fatalf("duplicate applied: %v", out)
+	}
+
+	// The failure was signed before the renewal but delivered after it.
+	out, applied := r.Apply(failed, 0)
+	if !applied {
+		t.Fatal("late event not applied")
+	}
+	if !reflect.DeepEqual(kinds(out), []TransitionKind{TransitionBillingIssue, TransitionRecovered}) {
+		t.Errorf("late transitions = %v", kinds(out))
+	}
+	for _, tr := range out {
+		if !tr.Late {
+			t.Errorf("%s not marked late", tr.Kind)
+		}
+	}
+	if r.State.Phase != PhaseActive || r.State.SignedDate != renewed.SignedDate {
+		t.Errorf("state = %+v", r.State)
+	}
+	if len(r.Events) != 3 || r.Events[1].NotificationUUID != failed.NotificationUUID {
+		t.Errorf("events out of order: %+v", r.Events)
+	}
+}
+
+func TestRecord_MaxEvents(t *testing.T) {
+	var r Record
+	r.Apply(ev(1, types.NOTIFICATION_TYPE_SUBSCRIBED, types.SUBTYPE_INITIAL_BUY), 2)
+	r.Apply(ev(2, types.NOTIFICATION_TYPE_DID_RENEW, ""), 2)
+	r.Apply(ev(3, types.NOTIFICATION_TYPE_DID_RENEW, ""), 2)
+	if len(r.Events) != 2 || r.Base.Phase != PhaseActive || r.Base.SignedDate != ev(1, "", "").SignedDate {
+		t.Fatalf("record = %+v", r)
+	}
+	if _, applied := r.Apply(ev(0, types.NOTIFICATION_TYPE_DID_RENEW, ""), 2); applied {
+		t.Error("event older than the base applied")
+	}
+}
+
+func TestRecord_RedeliveryOfFoldedEvent(t *testing.T) {
+	var r Record
+	subscribed := ev(1, types.NOTIFICATION_TYPE_SUBSCRIBED, types.SUBTYPE_INITIAL_BUY)
+	renewed := ev(2, types.NOTIFICATION_TYPE_DID_RENEW, "")
+	r.Apply(subscribed, 1)
+	r.Apply(renewed, 1)
+	if len(r.Events) != 1 || r.Base.SignedDate != subscribed.SignedDate {
+		t.Fatalf("record = %+v", r)
+	}
+
+	// Apple retries the first notification after it was folded into
+	// the base; its signedDate equals the base's.
+	if out, applied := r.Apply(subscribed, 1); applied || out != nil {
+		t.Fatalf("redelivered folded event applied: %v", kinds(out))
+	}
+
+	// The list survives persistence and is reset once the base moves on.
+	data, err := json.Marshal(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var loaded Record
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		t.Fatal(err)
+	}
+	if _, applied := loaded.Apply(subscribed, 1); applied {
+		t.Error("redelivered folded event applied after reload")
+	}
+	loaded.Apply(ev(3, types.NOTIFICATION_TYPE_DID_RENEW, ""), 1)
+	if len(loaded.BaseNotifications) != 1 || loaded.BaseNotifications[0] != renewed.NotificationUUID {
+		t.Errorf("BaseNotifications = %v", loaded.BaseNotifications)
+	}
+}
+
+func TestMachine_PersistsThroughStore(t *testing.T) {
+	ctx := context.Background()
+	store := new(MemoryStore)
+	machine := New(Config{Store: store})
+
+	if _, err := machine.Apply(ctx, trial(ev(1, types.NOTIFICATION_TYPE_SUBSCRIBED, types.SUBTYPE_INITIAL_BUY))); err != nil {
+		t.Fatal(err)
+	}
+	// A second machine over the same store continues the record.
+	out, err := New(Config{Store: store}).Apply(ctx, ev(2, types.NOTIFICATION_TYPE_DID_RENEW, ""))
+	if err != nil || !reflect.DeepEqual(kinds(out), []TransitionKind{TransitionConverted}) {
+		t.Fatalf("Apply = %v, %v", kinds(out), err)
+	}
+	if out[0].From != PhaseTrial || out[0].To != PhaseActive {
+		t.Errorf("transition = %+v", out[0])
+	}
+
+	record, ok, _ := store.Load(ctx, "1000")
+	data, err := json.Marshal(record)
+	if !ok || err != nil {
+		t.Fatalf("load/marshal: %v %v", ok, err)
+	}
+	var restored Record
+	if err := json.Unmarshal(data, &restored); err != nil || !reflect.DeepEqual(&restored, record) {
+		t.Errorf("JSON round trip changed the record: %s", data)
+	}
+	if _, err := machine.Apply(ctx, Event{NotificationUUID: "x"}); err == nil {
+		t.Error("event without originalTransactionId accepted")
+	}
+}
+
+func TestMachine_ApplyNotification(t *testing.T) {
+	chain := testchain.New(t)
+	verifier := jws.NewVerifier(jws.WithRootCAs(chain.RootPool), jws.WithRequiredOIDs(jws.OIDAppleReceiptSigning))
+	machine := New(Config{Verifier: verifier})
+	ctx := context.Background()
+
+	tx := &types.JWSTransactionDecodedPayload{
+		OriginalTransactionId: "1000", TransactionId: "1000", ProductId: "monthly",
+		Type: types.TRANSACTION_TYPE_AUTO_RENEWABLE_SUBSCRIPTION, OfferType: types.OFFER_TYPE_INTRODUCTORY, OfferDiscountType: types.OFFER_DISCOUNT_TYPE_FREE_TRIAL,
+	}
+	renewal := &types.JWSRenewalInfoDecodedPayload{AutoRenewStatus: 1, AutoRenewProductId: "monthly"}
+	payload := &AppStoreNotifications.ResponseBodyV2DecodedPayload{
+		NotificationType: types.NOTIFICATION_TYPE_SUBSCRIBED,
+		Subtype:          types.SUBTYPE_INITIAL_BUY,
+		NotificationUUID: "00000000-0000-4000-8000-000000000001",
+		SignedDate:       1_700_000_000_000,
+		Data: AppStoreNotifications.Data{
+			Status:                types.StatusActive,
+			SignedTransactionInfo: types.JWSTransaction(chain.SignJWS(t, tx)),
+			SignedRenewalInfo:     types.JWSRenewalInfo(chain.SignJWS(t, renewal)),
+		},
+	}
+	out, err := machine.ApplyNotification(ctx, payload)
+	if err != nil || !reflect.DeepEqual(kinds(out), []TransitionKind{TransitionTrialStarted}) {
+		t.Fatalf("ApplyNotification = %v, %v", kinds(out), err)
+	}
+	state, ok, err := machine.State(ctx, "1000")
+	if err != nil || !ok || state.Phase != PhaseTrial || !state.AutoRenew {
+		t.Errorf("State = %+v, %v, %v", state, ok, err)
+	}
+
+	test := &AppStoreNotifications.ResponseBodyV2DecodedPayload{NotificationType: types.NOTIFICATION_TYPE_TEST}
+	if out, err := machine.ApplyNotification(ctx, test); err != nil || out != nil {
+		t.Errorf("TEST notification = %v, %v", out, err)
+	}
+	payload.Data.SignedTransactionInfo = "a.b.c"
+	if _, err := machine.ApplyNotification(ctx, payload); err == nil {
+		t.Error("tampered notification accepted")
+	}
+}
@@ -0,0 +1,189 @@
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	AppStoreNotifications "github.com/godrealms/go-apple-sdk/app-store-server-notifications"
+	"github.com/godrealms/go-apple-sdk/jws"
+	"github.com/godrealms/go-apple-sdk/types"
+)
+
+// DefaultMaxEvents is the number of notifications a record keeps when
+// Config.MaxEvents is 0.
+const DefaultMaxEvents = 100
+
+// Store persists records by originalTransactionId.
+type Store interface {
+	// Load returns the record saved under id; ok is false if none.
+	Load(ctx context.Context, id types.OriginalTransactionId) (record *Record, ok bool, err error)
+	// Save records r under id.
+	Save(ctx context.Context, id types.OriginalTransactionId, r *Record) error
+}
+
+// MemoryStore is a [Store] that lives as long as the process. The zero
+// value is ready to use.
+type MemoryStore struct {
+	mu      sync.Mutex
+	records map[types.OriginalTransactionId]*Record
+}
+
+// Load implements [Store].
+func (m *MemoryStore) Load(_ context.Context, id types.OriginalTransactionId) (*Record, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	r, ok := m.records[id]
+	if !ok {
+		return nil, false, nil
+	}
+	return r.clone(), true, nil
+}
+
+// Save implements [Store].
+func (m *MemoryStore) Save(_ context.Context, id types.OriginalTransactionId, r *Record) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.records == nil {
+		m.records = make(map[types.OriginalTransactionId]*Record)
+	}
+	m.records[id] = r.clone()
+	return nil
+}
+
+func (r *Record) clone() *Record {
+	c := *r
+	c.Events = make([]AppliedEvent, len(r.Events))
+	for i, e := range r.Events {
+		c.Events[i] = e
+		c.Events[i].Transitions = append([]TransitionKind(nil), e.Transitions...)
+	}
+	return &c
+}
+
+// Config configures a [Machine].
+type Config struct {
+	// Store persists records. Defaults to a new [MemoryStore].
+	Store Store
+	// MaxEvents bounds the notifications kept per record. Defaults to
+	// [DefaultMaxEvents].
+	MaxEvents int
+	// Verifier checks the signed transaction and renewal info in
+	// notifications. Defaults to jws.DefaultVerifier().
+	Verifier *jws.Verifier
+}
+
+// Machine applies notifications to the records in a [Store].
+//
+// Machine serialises updates within the process. Processes sharing a
+// Store must route notifications for the same subscription to one of
+// them, or the Store must provide its own locking.
+type Machine struct {
+	store     Store
+	maxEvents int
+	verifier  *jws.Verifier
+	mu        sync.Mutex
+}
+
+// New constructs a [Machine].
+func New(cfg Config) *Machine {
+	m := &Machine{store: cfg.Store, maxEvents: cfg.MaxEvents, verifier: cfg.Verifier}
+	if m.store == nil {
+		m.store = new(MemoryStore)
+	}
+	if m.maxEvents == 0 {
+		m.maxEvents = DefaultMaxEvents
+	}
+	if m.verifier == nil {
+		m.verifier = jws.DefaultVerifier()
+	}
+	return m
+}
+
+// Apply adds ev to its subscription's record and saves the record.
+func (m *Machine) Apply(ctx context.Context, ev Event) ([]Transition, error) {
+	if ev.OriginalTransactionId == "" {
+		return nil, fmt.Errorf("lifecycle: event %s has no originalTransactionId", ev.NotificationUUID)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	record, ok, err := m.store.Load(ctx, ev.OriginalTransactionId)
+	if err != nil {
+		return nil, fmt.Errorf("lifecycle: load %s: %w", ev.OriginalTransactionId, err)
+	}
+	if !ok {
+		record = &Record{}
+	}
+	transitions, applied := record.Apply(ev, m.maxEvents)
+	if !applied {
+		// Duplicate or stale; nothing changed.
+		return nil, nil
+	}
+	if err := m.store.Save(ctx, ev.OriginalTransactionId, record); err != nil {
+		return nil, fmt.Errorf("lifecycle: save %s: %w", ev.OriginalTransactionId, err)
+	}
+	return transitions, nil
+}
+
+// ApplyNotification decodes payload with [EventFromNotification] and
+// applies it. Notifications that are not about an auto-renewable
+// subscription (TEST, ONE_TIME_CHARGE, RENEWAL_EXTENSION summaries,
+// external purchase tokens, ...) are ignored.
+func (m *Machine) ApplyNotification(ctx context.Context, payload *AppStoreNotifications.ResponseBodyV2DecodedPayload) ([]Transition, error) {
+	ev, ok, err := EventFromNotification(payload, m.verifier)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return m.Apply(ctx, ev)
+}
+
+// State returns the current state of a subscription.
+func (m *Machine) State(ctx context.Context, id types.OriginalTransactionId) (State, bool, error) {
+	record, ok, err := m.store.Load(ctx, id)
+	if err != nil || !ok {
+		return State{}, false, err
+	}
+	return record.State, true, nil
+}
+
+// EventFromNotification verifies and decodes the signed transaction and
+// renewal info of payload into an [Event]. ok is false for
+// notifications without an auto-renewable subscription transaction.
+// v defaults to jws.DefaultVerifier().
+func EventFromNotification(payload *AppStoreNotifications.ResponseBodyV2DecodedPayload, v *jws.Verifier) (ev Event, ok bool, err error) {
+	if payload == nil || payload.Data.SignedTransactionInfo == "" {
+		return Event{}, false, nil
+	}
+	if v == nil {
+		v = jws.DefaultVerifier()
+	}
+	tx, err := payload.Data.SignedTransactionInfo.DecryptWith(v)
+	if err != nil {
+		return Event{}, false, fmt.Errorf("lifecycle: decode transaction of notification %s: %w", payload.NotificationUUID, err)
+	}
+	if tx.Type != types.TRANSACTION_TYPE_AUTO_RENEWABLE_SUBSCRIPTION {
+		return Event{}, false, nil
+	}
+	ev = Event{
+		NotificationUUID:      payload.NotificationUUID,
+		SignedDate:            payload.SignedDate,
+		NotificationType:      payload.NotificationType,
+		Subtype:               payload.Subtype,
+		Status:                payload.Data.Status,
+		OriginalTransactionId: tx.OriginalTransactionId,
+		TransactionId:         tx.TransactionId,
+		ProductId:             tx.ProductId,
+		ExpiresDate:           tx.ExpiresDate,
+		FreeTrial:             tx.OfferType == types.OFFER_TYPE_INTRODUCTORY && tx.OfferDiscountType == types.OFFER_DISCOUNT_TYPE_FREE_TRIAL,
+	}
+	if payload.Data.SignedRenewalInfo != "" {
+		renewal, err := payload.Data.SignedRenewalInfo.DecryptWith(v)
+		if err != nil {
+			return Event{}, false, fmt.Errorf("lifecycle: decode renewal info of notification %s: %w", payload.NotificationUUID, err)
+		}
+		autoRenew := renewal.AutoRenewStatus == 1
+		ev.AutoRenew = &autoRenew
+		ev.AutoRenewProductId = types.ProductId(renewal.AutoRenewProductId)
+	}
+	return ev, true, nil
+}
@@ -0,0 +1,324 @@
+package lifecycle
+
+import (
+	"sort"
+
+	"github.com/godrealms/go-apple-sdk/types"
+)
+
+// Phase Where a subscription is in its lifecycle.
+type Phase string
+
+const (
+	PhaseUnknown      Phase = ""
+	PhaseTrial        Phase = "TRIAL"         // In a free trial.
+	PhaseActive       Phase = "ACTIVE"        // Paid and current.
+	PhaseGracePeriod  Phase = "GRACE_PERIOD"  // Renewal failed; access continues during the grace period.
+	PhaseBillingRetry Phase = "BILLING_RETRY" // Renewal failed; Apple is retrying and access has ended.
+	PhaseExpired      Phase = "EXPIRED"
+	PhaseRefunded     Phase = "REFUNDED"
+	PhaseRevoked      Phase = "REVOKED" // Family Sharing access ended.
+)
+
+// TransitionKind What happened to a subscription.
+type TransitionKind string
+
+const (
+	TransitionTrialStarted       TransitionKind = "TRIAL_STARTED"
+	TransitionSubscribed         TransitionKind = "SUBSCRIBED"   // First paid purchase without a trial.
+	TransitionResubscribed       TransitionKind = "RESUBSCRIBED" // The customer came back after the subscription ended.
+	TransitionConverted          TransitionKind = "CONVERTED"    // A trial renewed into a paid period.
+	TransitionRenewed            TransitionKind = "RENEWED"
+	TransitionBillingIssue       TransitionKind = "BILLING_ISSUE" // Renewal failed; see the Phase for grace period or retry.
+	TransitionGracePeriodExpired TransitionKind = "GRACE_PERIOD_EXPIRED"
+	TransitionRecovered          TransitionKind = "RECOVERED" // Renewal succeeded after a billing issue.
+	// TransitionChurnedVoluntary is an expiry the customer chose: they
+	// turned off renewal or declined a price increase.
+	TransitionChurnedVoluntary TransitionKind = "CHURNED_VOLUNTARY"
+	// TransitionChurnedInvoluntary is an expiry the customer did not
+	// choose: billing retry ran out, or the product was not for sale.
+	TransitionChurnedInvoluntary TransitionKind = "CHURNED_INVOLUNTARY"
+	TransitionAutoRenewDisabled  TransitionKind = "AUTO_RENEW_DISABLED"
+	TransitionAutoRenewEnabled   TransitionKind = "AUTO_RENEW_ENABLED"
+	TransitionUpgraded           TransitionKind = "UPGRADED"   // Effective immediately.
+	TransitionDowngraded         TransitionKind = "DOWNGRADED" // Effective at the next renewal.
+	TransitionRefunded           TransitionKind = "REFUNDED"
+	TransitionRefundReversed     TransitionKind = "REFUND_REVERSED"
+	TransitionRevoked            TransitionKind = "REVOKED"
+	TransitionRenewalExtended    TransitionKind = "RENEWAL_EXTENDED"
+)
+
+// Event The parts of a V2 notification the state machine uses. Build
+// one with [EventFromNotification], or by hand from stored data.
+type Event struct {
+	NotificationUUID      types.UUID                  `json:"notificationUUID"`
+	SignedDate            types.Timestamp             `json:"signedDate"`
+	NotificationType      types.NotificationType      `json:"notificationType"`
+	Subtype               types.Subtype               `json:"subtype,omitempty"`
+	Status                types.Status                `json:"status,omitempty"`
+	OriginalTransactionId types.OriginalTransactionId `json:"originalTransactionId"`
+	TransactionId         types.TransactionId         `json:"transactionId,omitempty"`
+	ProductId             types.ProductId             `json:"productId,omitempty"`
+	ExpiresDate           types.Timestamp             `json:"expiresDate,omitempty"`
+	// FreeTrial reports whether the transaction is an introductory free trial.
+	FreeTrial bool `json:"freeTrial,omitempty"`
+	// AutoRenew is the renewal info's auto-renew status; nil when the
+	// notification carried no renewal info.
+	AutoRenew          *bool           `json:"autoRenew,omitempty"`
+	AutoRenewProductId types.ProductId `json:"autoRenewProductId,omitempty"`
+}
+
+// State A subscription's state after the notifications applied so far.
+type State struct {
+	OriginalTransactionId types.OriginalTransactionId `json:"originalTransactionId"`
+	ProductId             types.ProductId             `json:"productId,omitempty"`
+	Phase                 Phase                       `json:"phase"`
+	AutoRenew             bool                        `json:"autoRenew"`
+	AutoRenewProductId    types.ProductId             `json:"autoRenewProductId,omitempty"`
+	ExpiresDate           types.Timestamp             `json:"expiresDate,omitempty"`
+	// SignedDate is the signedDate of the latest notification applied.
+	SignedDate types.Timestamp `json:"signedDate,omitempty"`
+}
+
+// Transition One change [Record.Apply] found.
+type Transition struct {
+	Kind                  TransitionKind
+	OriginalTransactionId types.OriginalTransactionId
+	ProductId             types.ProductId
+	From, To              Phase
+	// The notification that caused the transition.
+	NotificationUUID types.UUID
+	SignedDate       types.Timestamp
+	// Late is set when the transition surfaced because a notification
+	// arrived after one signed later than it.
+	Late bool
+}
+
+// AppliedEvent An event in a record, with the transitions it produced.
+type AppliedEvent struct {
+	Event
+	Transitions []TransitionKind `json:"transitions,omitempty"`
+}
+
+// Record The persisted lifecycle of one subscription.
+type Record struct {
+	// Base is the state before the first of Events; events folded into
+	// it are no longer kept.
+	Base State `json:"base"`
+	// BaseNotifications are the notificationUUIDs of the folded events
+	// signed at Base.SignedDate, so that their redeliveries, which are
+	// not older than Base, are still recognised as duplicates.
+	BaseNotifications []types.UUID `json:"baseNotifications,omitempty"`
+	// Events are the applied notifications in signedDate order.
+	Events []AppliedEvent `json:"events"`
+	// State is the state after all of Events.
+	State State `json:"state"`
+}
+
+// Apply adds ev to the record and returns the transitions it causes.
+// Duplicates and notifications signed before the record's base are
+// ignored, with applied false. maxEvents bounds len(r.Events); 0 means
+// no bound.
+func (r *Record) Apply(ev Event, maxEvents int) (out []Transition, applied bool) {
+	if ev.SignedDate < r.Base.SignedDate {
+		return nil, false
+	}
+	for _, id := range r.BaseNotifications {
+		if id == ev.NotificationUUID {
+			return nil, false
+		}
+	}
+	for _, e := range r.Events {
+		if e.NotificationUUID == ev.NotificationUUID {
+			return nil, false
+		}
+	}
+	idx := sort.Search(len(r.Events), func(i int) bool { return eventLess(ev, r.Events[i].Event) })
+	r.Events = append(r.Events, AppliedEvent{})
+	copy(r.Events[idx+1:], r.Events[idx:])
+	r.Events[idx] = AppliedEvent{Event: ev}
+
+	if idx == len(r.Events)-1 {
+		next, kinds := step(r.State, ev)
+		r.Events[idx].Transitions = kinds
+		out = transitions(r.State, next, ev, kinds, false)
+		r.State = next
+	} else {
+		// An out-of-order delivery: replay everything and report what
+		// is new from the late event on.
+		state := r.Base
+		for i := range r.Events {
+			e := &r.Events[i]
+			next, kinds := step(state, e.Event)
+			if i >= idx {
+				out = append(out, transitions(state, next, e.Event, newKinds(kinds, e.Transitions), true)...)
+			}
+			e.Transitions = kinds
+			state = next
+		}
+		r.State = state
+	}
+
+	for maxEvents > 0 && len(r.Events) > maxEvents {
+		folded := r.Events[0].Event
+		if folded.SignedDate != r.Base.SignedDate {
+			r.BaseNotifications = nil
+		}
+		r.Base, _ = step(r.Base, folded)
+		r.BaseNotifications = append(r.BaseNotifications, folded.NotificationUUID)
+		r.Events = r.Events[1:]
+	}
+	return out, true
+}
+
+func eventLess(a, b Event) bool {
+	if a.SignedDate != b.SignedDate {
+		return a.SignedDate < b.SignedDate
+	}
+	return a.NotificationUUID < b.NotificationUUID
+}
+
+func newKinds(kinds, before []TransitionKind) []TransitionKind {
+	var fresh []TransitionKind
+	for _, k := range kinds {
+		seen := false
+		for _, b := range before {
+			seen = seen || b == k
+		}
+		if !seen {
+			fresh = append(fresh, k)
+		}
+	}
+	return fresh
+}
+
+func transitions(from, to State, ev Event, kinds []TransitionKind, late bool) []Transition {
+	out := make([]Transition, 0, len(kinds))
+	for _, k := range kinds {
+		out = append(out, Transition{
+			Kind:                  k,
+			OriginalTransactionId: to.OriginalTransactionId,
+			ProductId:             to.ProductId,
+			From:                  from.Phase,
+			To:                    to.Phase,
+			NotificationUUID:      ev.NotificationUUID,
+			SignedDate:            ev.SignedDate,
+			Late:                  late,
+		})
+	}
+	return out
+}
+
+// step applies one event to a state.
+func step(state State, ev Event) (State, []TransitionKind) {
+	next := state
+	next.OriginalTransactionId = ev.OriginalTransactionId
+	next.SignedDate = ev.SignedDate
+	if ev.ProductId != "" {
+		next.ProductId = ev.ProductId
+	}
+	if ev.ExpiresDate != 0 {
+		next.ExpiresDate = ev.ExpiresDate
+	}
+	if ev.AutoRenew != nil {
+		next.AutoRenew = *ev.AutoRenew
+		next.AutoRenewProductId = ev.AutoRenewProductId
+	}
+
+	var kinds []TransitionKind
+	switch ev.NotificationType {
+	case types.NOTIFICATION_TYPE_SUBSCRIBED:
+		switch {
+		case ev.FreeTrial:
+			next.Phase = PhaseTrial
+			kinds = append(kinds, TransitionTrialStarted)
+		case ev.Subtype == types.SUBTYPE_RESUBSCRIBE:
+			next.Phase = PhaseActive
+			kinds = append(kinds, TransitionResubscribed)
+		default:
+			next.Phase = PhaseActive
+			kinds = append(kinds, TransitionSubscribed)
+		}
+	case types.NOTIFICATION_TYPE_DID_RENEW:
+		switch {
+		case ev.Subtype == types.SUBTYPE_BILLING_RECOVERY,
+			state.Phase == PhaseGracePeriod, state.Phase == PhaseBillingRetry:
+			kinds = append(kinds, TransitionRecovered)
+		case state.Phase == PhaseTrial && !ev.FreeTrial:
+			kinds = append(kinds, TransitionConverted)
+		default:
+			kinds = append(kinds, TransitionRenewed)
+		}
+		next.Phase = PhaseActive
+		if ev.FreeTrial {
+			next.Phase = PhaseTrial
+		}
+	case types.NOTIFICATION_TYPE_DID_FAIL_TO_RENEW:
+		next.Phase = PhaseBillingRetry
+		if ev.Subtype == types.SUBTYPE_GRACE_PERIOD {
+			next.Phase = PhaseGracePeriod
+		}
+		kinds = append(kinds, TransitionBillingIssue)
+	case types.NOTIFICATION_TYPE_GRACE_PERIOD_EXPIRED:
+		next.Phase = PhaseBillingRetry
+		kinds = append(kinds, TransitionGracePeriodExpired)
+	case types.NOTIFICATION_TYPE_EXPIRED:
+		next.Phase = PhaseExpired
+		switch ev.Subtype {
+		case types.SUBTYPE_VOLUNTARY, types.SUBTYPE_PRICE_INCREASE:
+			kinds = append(kinds, TransitionChurnedVoluntary)
+		default:
+			kinds = append(kinds, TransitionChurnedInvoluntary)
+		}
+	case types.NOTIFICATION_TYPE_DID_CHANGE_RENEWAL_STATUS:
+		switch ev.Subtype {
+		case types.SUBTYPE_AUTO_RENEW_DISABLED:
+			next.AutoRenew = false
+			kinds = append(kinds, TransitionAutoRenewDisabled)
+		case types.SUBTYPE_AUTO_RENEW_ENABLED:
+			next.AutoRenew = true
+			kinds = append(kinds, TransitionAutoRenewEnabled)
+		}
+	case types.NOTIFICATION_TYPE_DID_CHANGE_RENEWAL_PREF, types.NOTIFICATION_TYPE_OFFER_REDEEMED:
+		switch ev.Subtype {
+		case types.SUBTYPE_UPGRADE:
+			next.Phase = PhaseActive
+			kinds = append(kinds, TransitionUpgraded)
+		case types.SUBTYPE_DOWNGRADE:
+			kinds = append(kinds, TransitionDowngraded)
+		}
+	case types.NOTIFICATION_TYPE_REFUND:
+		next.Phase = PhaseRefunded
+		next.AutoRenew = false
+		kinds = append(kinds, TransitionRefunded)
+	case types.NOTIFICATION_TYPE_REFUND_REVERSED:
+		next.Phase = phaseOfStatus(ev.Status, PhaseActive)
+		kinds = append(kinds, TransitionRefundReversed)
+	case types.NOTIFICATION_TYPE_REVOKE:
+		next.Phase = PhaseRevoked
+		kinds = append(kinds, TransitionRevoked)
+	case types.NOTIFICATION_TYPE_RENEWAL_EXTENDED:
+		kinds = append(kinds, TransitionRenewalExtended)
+	}
+	if next.Phase == PhaseUnknown {
+		next.Phase = phaseOfStatus(ev.Status, PhaseUnknown)
+	}
+	return next, kinds
+}
+
+// phaseOfStatus maps a notification's subscription status to a phase.
+func phaseOfStatus(status types.Status, fallback Phase) Phase {
+	switch status {
+	case types.StatusActive:
+		return PhaseActive
+	case types.StatusExpired:
+		return PhaseExpired
+	case types.StatusRetryPeriod:
+		return PhaseBillingRetry
+	case types.StatusGracePeriod:
+		return PhaseGracePeriod
+	case types.StatusRevoked:
+		return PhaseRevoked
+	}
+	return fallback
+}
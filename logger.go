@@ -0,0 +1,147 @@
+package Apple
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	AppStoreConnect "github.com/godrealms/go-apple-sdk/app-store-connect"
+	"github.com/godrealms/go-apple-sdk/internal/redact"
+)
+
+// Logger is the structured-logging hook for [Client]. It has the same
+// shape as AppStoreConnect.Logger so one adapter can serve both APIs;
+// install it with [WithLogger].
+//
+// Implementations should be safe for concurrent use — a Client and
+// every client derived from it via [Client.ForService] share one
+// Logger.
+type Logger interface {
+	// Log is invoked once per HTTP round trip, after the response is
+	// read (or after a transport error).
+	Log(record LogRecord)
+}
+
+// LoggerFunc is an adapter that lets a plain function satisfy
+// [Logger].
+type LoggerFunc func(LogRecord)
+
+// Log implements [Logger].
+func (f LoggerFunc) Log(r LogRecord) { f(r) }
+
+// LogRecord describes a single HTTP round trip; a retried request
+// yields one record per attempt. Zero-valued fields indicate "not
+// available" — e.g. StatusCode is 0 when the transport layer failed
+// before receiving a response.
+//
+// Unless redaction is switched off with [WithLogRedaction], the
+// Authorization header is masked and every JWS in ResponseBody is
+// replaced by a placeholder before the record reaches the Logger.
+type LogRecord struct {
+	// Method is the HTTP verb (GET, POST, etc.).
+	Method string
+	// URL is the full request URL including any resolved query.
+	URL string
+	// StatusCode is the HTTP status Apple returned. 0 on transport
+	// failure.
+	StatusCode int
+	// Duration measures the wall-clock time spent on this attempt,
+	// including body read.
+	Duration time.Duration
+	// Err is non-nil when the request failed — either a transport
+	// error or a non-2xx status reported as an [*HTTPError].
+	Err error
+	// RequestHeader is a copy of the headers sent to Apple.
+	RequestHeader http.Header
+	// ResponseHeader is a copy of the headers Apple sent back.
+	ResponseHeader http.Header
+	// ResponseBody is the response body. Only populated when body
+	// logging is enabled with [WithLogBody].
+	ResponseBody []byte
+	// Attempt is the 1-based attempt number; above 1 when the request
+	// was retried.
+	Attempt int
+	// RequestID is the request ID Apple sent back, for support
+	// tickets. Only App Store Connect responses carry one.
+	RequestID string
+	// RateLimit is the hourly quota reported with an App Store Connect
+	// response. Zero for other APIs.
+	RateLimit AppStoreConnect.RateLimit
+}
+
+// WithLogger installs logger on the client. Leaving it unset silences
+// the SDK; the client never writes to the standard library logger.
+// The logger is also handed to the App Store Connect service returned
+// by [Client.AppStoreConnect].
+func WithLogger(logger Logger) ClientOption {
+	return func(client *Client) { client.logger = logger }
+}
+
+// WithLogBody controls whether [LogRecord.ResponseBody] is populated.
+// Off by default: response bodies carry customer purchase data.
+func WithLogBody(enabled bool) ClientOption {
+	return func(client *Client) { client.logBody = enabled }
+}
+
+// WithLogRedaction controls secret redaction in log records. It is on
+// by default; switch it off only for local debugging.
+func WithLogRedaction(enabled bool) ClientOption {
+	return func(client *Client) { client.logUnredacted = !enabled }
+}
+
+// redactedValue replaces secrets in log records.
+const redactedValue = "[REDACTED]"
+
+// sensitiveHeaders lists the headers masked in log records.
+var sensitiveHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+// redactHeader returns a copy of h with credentials masked. The auth
+// scheme ("Bearer") is kept so operators can still tell which kind of
+// credential was sent.
+func redactHeader(h http.Header) http.Header {
+	out := h.Clone()
+	for _, name := range sensitiveHeaders {
+		key := http.CanonicalHeaderKey(name)
+		values := out[key]
+		if len(values) == 0 {
+			continue
+		}
+		masked := make([]string, len(values))
+		for i, v := range values {
+			masked[i] = redactedValue
+			if scheme, _, ok := strings.Cut(v, " "); ok && strings.HasSuffix(key, "Authorization") {
+				masked[i] = scheme + " " + redactedValue
+			}
+		}
+		out[key] = masked
+	}
+	return out
+}
+
+// redactBody replaces every JWS in body with a placeholder.
+func redactBody(body []byte) []byte {
+	if len(body) == 0 {
+		return body
+	}
+	return redact.JWSPattern.ReplaceAll(body, []byte(redactedValue))
+}
+
+// logRequest emits a [LogRecord] for a completed round trip. It is a
+// no-op when no [Logger] is configured.
+func (client *Client) logRequest(record LogRecord) {
+	if client.logger == nil {
+		return
+	}
+	if !client.logBody {
+		record.ResponseBody = nil
+	}
+	if client.logUnredacted {
+		record.RequestHeader = record.RequestHeader.Clone()
+		record.ResponseHeader = record.ResponseHeader.Clone()
+	} else {
+		record.RequestHeader = redactHeader(record.RequestHeader)
+		record.ResponseHeader = redactHeader(record.ResponseHeader)
+		record.ResponseBody = redactBody(record.ResponseBody)
+	}
+	client.logger.Log(record)
+}
@@ -0,0 +1,215 @@
+package Apple
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// captureLogger records every LogRecord it receives.
+type captureLogger struct {
+	mu      sync.Mutex
+	records []LogRecord
+}
+
+func (c *captureLogger) Log(r LogRecord) {
+	c.mu.Lock()
+	c.records = append(c.records, r)
+	c.mu.Unlock()
+}
+
+func (c *captureLogger) snapshot() []LogRecord {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]LogRecord(nil), c.records...)
+}
+
+// newTestKeyPEM returns a freshly generated P-256 key in PKCS#8 PEM form.
+func newTestKeyPEM(t *testing.T) string {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+}
+
+const signedBody = `{"signedTransactionInfo":"eyJhbGciOiJFUzI1NiJ9.eyJ0cmFuc2FjdGlvbklkIjoiMSJ9.c2ln"}`
+
+func newLoggingServer(t *testing.T, status int) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(signedBody))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestLogger_RedactsByDefault(t *testing.T) {
+	srv := newLoggingServer(t, http.StatusOK)
+	logger := &captureLogger{}
+	client := NewClient(true, "KID", "iss", "bid", newTestKeyPEM(t), WithLogger(logger), WithLogBody(true)).
+		ForService(AppStoreServerClient, srv.URL)
+
+	if err := client.Get("/inApps/v1/transactions/1", new(map[string]any), nil); err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	recs := logger.snapshot()
+	if len(recs) != 1 {
+		t.Fatalf("records = %d, want 1", len(recs))
+	}
+	r := recs[0]
+	if r.Method != "GET" || r.StatusCode != 200 || r.Err != nil {
+		t.Errorf("record = %+v", r)
+	}
+	if r.URL != srv.URL+"/inApps/v1/transactions/1" {
+		t.Errorf("url = %q", r.URL)
+	}
+	if got := r.RequestHeader.Get("Authorization"); got != "Bearer [REDACTED]" {
+		t.Errorf("Authorization = %q", got)
+	}
+	if strings.Contains(string(r.ResponseBody), "eyJ") || !strings.Contains(string(r.ResponseBody), "[REDACTED]") {
+		t.Errorf("body not redacted: %s", r.ResponseBody)
+	}
+}
+
+func TestLogger_BodyOffByDefault(t *testing.T) {
+	srv := newLoggingServer(t, http.StatusOK)
+	logger := &captureLogger{}
+	client := NewClient(true, "KID", "iss", "bid", newTestKeyPEM(t), WithLogger(logger)).
+		ForService(AppStoreServerClient, srv.URL)
+
+	if err := client.Get("/x", nil, nil); err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if body := logger.snapshot()[0].ResponseBody; body != nil {
+		t.Errorf("ResponseBody = %s, want nil", body)
+	}
+}
+
+func TestLogger_Unredacted(t *testing.T) {
+	srv := newLoggingServer(t, http.StatusOK)
+	logger := &captureLogger{}
+	client := NewClient(true, "KID", "iss", "bid", newTestKeyPEM(t),
+		WithLogger(logger), WithLogBody(true), WithLogRedaction(false)).
+		ForService(AppStoreServerClient, srv.URL)
+
+	if err := client.Get("/x", nil, nil); err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	r := logger.snapshot()[0]
+	if !strings.HasPrefix(r.RequestHeader.Get("Authorization"), "Bearer eyJ") {
+		t.Errorf("Authorization = %q", r.RequestHeader.Get("Authorization"))
+	}
+	if string(r.ResponseBody) != signedBody {
+		t.Errorf("body = %s", r.ResponseBody)
+	}
+}
+
+func TestLogger_ErrorResponse(t *testing.T) {
+	srv := newLoggingServer(t, http.StatusNotFound)
+	logger := &captureLogger{}
+	client := NewClient(true, "KID", "iss", "bid", newTestKeyPEM(t), WithLogger(logger)).
+		ForService(AppStoreServerClient, srv.URL)
+
+	err := client.Get("/x", nil, nil)
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("err = %v, want *HTTPError 404", err)
+	}
+	recs := logger.snapshot()
+	if len(recs) != 1 {
+		t.Fatalf("records = %d, want 1", len(recs))
+	}
+	if recs[0].StatusCode != http.StatusNotFound || !errors.As(recs[0].Err, &httpErr) {
+		t.Errorf("record = %+v", recs[0])
+	}
+}
+
+func TestRedactHeader(t *testing.T) {
+	h := http.Header{}
+	h.Set("Authorization", "Bearer secret")
+	h.Set("Cookie", "session=secret")
+	h.Set("Accept", "application/json")
+	out := redactHeader(h)
+	if out.Get("Authorization") != "Bearer [REDACTED]" || out.Get("Cookie") != "[REDACTED]" {
+		t.Errorf("redacted = %v", out)
+	}
+	if out.Get("Accept") != "application/json" {
+		t.Errorf("Accept changed: %q", out.Get("Accept"))
+	}
+	if h.Get("Authorization") != "Bearer secret" {
+		t.Error("redactHeader mutated its input")
+	}
+}
+
+func TestLogger_RecordPerAttempt(t *testing.T) {
+	srv := newLoggingServer(t, http.StatusOK)
+	var calls atomic.Int32
+	flaky := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if calls.Add(1) == 1 {
+			return nil, errors.New("connection reset")
+		}
+		return http.DefaultTransport.RoundTrip(req)
+	})
+	logger := &captureLogger{}
+	root := NewClient(true, "KID", "iss", "bid", newTestKeyPEM(t), WithLogger(logger), WithTransport(flaky))
+	root.config.RetryWaitTime = time.Millisecond
+	root.config.RetryMaxWaitTime = time.Millisecond
+	client := root.ForService(AppStoreServerClient, srv.URL)
+
+	if err := client.Get("/x", nil, nil); err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	recs := logger.snapshot()
+	if len(recs) != 2 {
+		t.Fatalf("records = %d, want 2", len(recs))
+	}
+	if recs[0].Attempt != 1 || recs[0].Err == nil || recs[0].StatusCode != 0 {
+		t.Errorf("first attempt = %+v", recs[0])
+	}
+	if recs[1].Attempt != 2 || recs[1].Err != nil || recs[1].StatusCode != http.StatusOK {
+		t.Errorf("second attempt = %+v", recs[1])
+	}
+}
+
+func TestLogger_RetriesExhausted(t *testing.T) {
+	logger := &captureLogger{}
+	down := roundTripFunc(func(*http.Request) (*http.Response, error) {
+		return nil, errors.New("connection refused")
+	})
+	root := NewClient(true, "KID", "iss", "bid", newTestKeyPEM(t), WithLogger(logger), WithTransport(down))
+	root.config.RetryCount = 2
+	root.config.RetryWaitTime = time.Millisecond
+	root.config.RetryMaxWaitTime = time.Millisecond
+	client := root.ForService(AppStoreServerClient, "http://apple.invalid")
+
+	if err := client.Get("/x", nil, nil); err == nil {
+		t.Fatal("Get succeeded")
+	}
+	recs := logger.snapshot()
+	if len(recs) != 3 {
+		t.Fatalf("records = %d, want 3", len(recs))
+	}
+	for i, r := range recs {
+		if r.Attempt != i+1 || r.Err == nil {
+			t.Errorf("record %d = %+v", i, r)
+		}
+	}
+}
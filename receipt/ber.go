@@ -0,0 +1,169 @@
+package receipt
+
+import (
+	"errors"
+	"fmt"
+)
+
+// maxDepth bounds nesting so a hostile receipt cannot exhaust the stack.
+const maxDepth = 32
+
+// ASN.1 universal tags used in receipts.
+const (
+	tagInteger     = 2
+	tagOctetString = 4
+	tagOID         = 6
+	tagUTF8String  = 12
+	tagSequence    = 16
+	tagSet         = 17
+	tagIA5String   = 22
+)
+
+const (
+	classUniversal = 0
+	classContext   = 2
+)
+
+// node is one BER-encoded value. App Store receipts are BER, not DER:
+// newer ones use indefinite lengths and constructed OCTET STRINGs,
+// which encoding/asn1 rejects, so receipts are read with this parser.
+type node struct {
+	class       int
+	tag         int
+	constructed bool
+	// raw is the complete encoding, header included.
+	raw []byte
+	// content is the contents of a primitive value.
+	content  []byte
+	children []node
+}
+
+// parseBER reads one value from data and returns it with the bytes
+// that follow it.
+func parseBER(data []byte) (node, []byte, error) {
+	return parseNode(data, 0)
+}
+
+func parseNode(data []byte, depth int) (node, []byte, error) {
+	if depth > maxDepth {
+		return node{}, nil, errors.New("nesting too deep")
+	}
+	if len(data) < 2 {
+		return node{}, nil, errors.New("truncated header")
+	}
+	n := node{class: int(data[0] >> 6), constructed: data[0]&0x20 != 0, tag: int(data[0] & 0x1f)}
+	i := 1
+	if n.tag == 0x1f {
+		n.tag = 0
+		for {
+			if i >= len(data) || i > 4 {
+				return node{}, nil, errors.New("bad tag")
+			}
+			b := data[i]
+			i++
+			n.tag = n.tag<<7 | int(b&0x7f)
+			if b&0x80 == 0 {
+				break
+			}
+		}
+	}
+	if i >= len(data) {
+		return node{}, nil, errors.New("truncated length")
+	}
+	lb := data[i]
+	i++
+
+	if lb == 0x80 {
+		if !n.constructed {
+			return node{}, nil, errors.New("indefinite length on primitive value")
+		}
+		rest := data[i:]
+		for {
+			if len(rest) >= 2 && rest[0] == 0 && rest[1] == 0 {
+				rest = rest[2:]
+				break
+			}
+			child, r, err := parseNode(rest, depth+1)
+			if err != nil {
+				return node{}, nil, err
+			}
+			n.children = append(n.children, child)
+			rest = r
+		}
+		n.raw = data[:len(data)-len(rest)]
+		return n, rest, nil
+	}
+
+	length := int(lb)
+	if lb&0x80 != 0 {
+		count := int(lb & 0x7f)
+		if count > 4 || i+count > len(data) {
+			return node{}, nil, errors.New("bad length")
+		}
+		length = 0
+		for _, b := range data[i : i+count] {
+			length = length<<8 | int(b)
+		}
+		i += count
+	}
+	if length < 0 || length > len(data)-i {
+		return node{}, nil, fmt.Errorf("length %d exceeds data", length)
+	}
+	body := data[i : i+length]
+	n.raw = data[:i+length]
+	if !n.constructed {
+		n.content = body
+		return n, data[i+length:], nil
+	}
+	for len(body) > 0 {
+		child, r, err := parseNode(body, depth+1)
+		if err != nil {
+			return node{}, nil, err
+		}
+		n.children = append(n.children, child)
+		body = r
+	}
+	return n, data[i+length:], nil
+}
+
+func (n node) is(class, tag int) bool { return n.class == class && n.tag == tag }
+
+// octets returns the bytes of an OCTET STRING, joining the segments
+// of a constructed one.
+func (n node) octets() ([]byte, error) {
+	if !n.is(classUniversal, tagOctetString) {
+		return nil, fmt.Errorf("expected OCTET STRING, got tag %d", n.tag)
+	}
+	if !n.constructed {
+		return n.content, nil
+	}
+	var out []byte
+	for _, c := range n.children {
+		b, err := c.octets()
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, b...)
+	}
+	return out, nil
+}
+
+// int returns the value of a small INTEGER.
+func (n node) int() (int64, error) {
+	if !n.is(classUniversal, tagInteger) || n.constructed || len(n.content) == 0 || len(n.content) > 8 {
+		return 0, errors.New("expected INTEGER")
+	}
+	v := int64(int8(n.content[0]))
+	for _, b := range n.content[1:] {
+		v = v<<8 | int64(b)
+	}
+	return v, nil
+}
+
+// string returns the text of a UTF8String or IA5String.
+func (n node) string() (string, error) {
+	if n.constructed || n.class != classUniversal || (n.tag != tagUTF8String && n.tag != tagIA5String) {
+		return "", fmt.Errorf("expected string, got tag %d", n.tag)
+	}
+	return string(n.content), nil
+}
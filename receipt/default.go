@@ -0,0 +1,67 @@
+package receipt
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	_ "embed"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"sync"
+
+	"github.com/godrealms/go-apple-sdk/jws"
+)
+
+//go:embed apple_inc_root.pem
+var appleIncRootPEM []byte
+
+// appleIncRootSHA256 is the SHA-256 fingerprint of the DER encoding of
+// the Apple Inc. Root Certificate Apple publishes at
+// https://www.apple.com/certificateauthority/. The embedded file must
+// match it, so a stale or substituted file is never trusted.
+const appleIncRootSHA256 = "b0b1730ecbc7ff4505142c49f1295e6eda6bcaed7e2c68c5be91b5a11001f024"
+
+var (
+	defaultOnce sync.Once
+	defaultV    *jws.Verifier
+	defaultErr  error
+)
+
+// DefaultVerifier returns the process-wide verifier a [Validator] uses
+// when its Verifier is nil: the embedded Apple Inc. Root Certificate,
+// which issues the receipt-signing chain, with jws.DefaultRequiredOIDs.
+// The first call parses the embedded PEM under sync.Once.
+//
+// It fails when the embedded file is not the pinned certificate; run
+// scripts/update-root-ca.sh to install it.
+func DefaultVerifier() (*jws.Verifier, error) {
+	defaultOnce.Do(func() {
+		pool, err := rootPool(appleIncRootPEM, appleIncRootSHA256)
+		if err != nil {
+			defaultErr = fmt.Errorf("receipt: embedded Apple Inc. Root Certificate: %w", err)
+			return
+		}
+		defaultV = jws.NewVerifier(jws.WithRootCAs(pool))
+	})
+	return defaultV, defaultErr
+}
+
+// rootPool parses the single certificate in pemData and returns a pool
+// holding it, provided its DER has the given SHA-256 fingerprint.
+func rootPool(pemData []byte, fingerprint string) (*x509.CertPool, error) {
+	block, _ := pem.Decode(pemData)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, fmt.Errorf("no PEM certificate; run scripts/update-root-ca.sh")
+	}
+	sum := sha256.Sum256(block.Bytes)
+	if got := hex.EncodeToString(sum[:]); got != fingerprint {
+		return nil, fmt.Errorf("SHA-256 %s, want %s; run scripts/update-root-ca.sh", got, fingerprint)
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	return pool, nil
+}
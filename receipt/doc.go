@@ -0,0 +1,31 @@
+// Package receipt reads legacy App Store receipts locally, for apps
+// moving off the deprecated verifyReceipt endpoint whose older
+// versions still send receipts instead of signed transactions.
+//
+// Both legacy formats are supported:
+//
+//   - App receipts (Bundle.main.appStoreReceiptURL): a PKCS#7
+//     container around an ASN.1 set of receipt attributes.
+//     [ParseAppReceipt] decodes it and [ExtractTransactionIdFromAppReceipt]
+//     returns the first in-app purchase's transaction ID.
+//   - Transaction receipts (SKPaymentTransaction.transactionReceipt):
+//     [ExtractTransactionIdFromTransactionReceipt].
+//
+// A transaction ID is enough to look up the customer's purchases with
+// the App Store Server API, which returns them signed:
+//
+//	txID, err := receipt.ExtractTransactionIdFromAppReceipt(base64Receipt)
+//	if err != nil {
+//	    return err
+//	}
+//	history := svc.DecodedTransactionHistory(txID, AppStoreServer.TransactionHistoryRequest{})
+//
+// These functions do not check the receipt's signature; a forged
+// receipt only yields a transaction ID that Apple's API will not
+// recognise.
+//
+// To trust the receipt itself without calling Apple, use [Validator]:
+// it verifies the PKCS#7 signature and certificate chain with a
+// jws.Verifier, then checks the bundle ID, version and, optionally,
+// the device identifier hash.
+package receipt
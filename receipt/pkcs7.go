@@ -0,0 +1,81 @@
+package receipt
+
+import (
+	"encoding/asn1"
+	"errors"
+	"fmt"
+)
+
+var (
+	oidData       = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}
+	oidSignedData = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+)
+
+// signedData is the part of a PKCS#7 SignedData container receipts use.
+type signedData struct {
+	// content is the signed receipt payload.
+	content []byte
+	// certificates holds the DER of each embedded certificate.
+	certificates [][]byte
+	signerInfos  []node
+}
+
+// parsePKCS7 unwraps a PKCS#7 ContentInfo holding SignedData.
+func parsePKCS7(der []byte) (*signedData, error) {
+	ci, _, err := parseBER(der)
+	if err != nil {
+		return nil, err
+	}
+	if !ci.is(classUniversal, tagSequence) || len(ci.children) < 2 {
+		return nil, errors.New("not a PKCS#7 ContentInfo")
+	}
+	if oid, err := parseOID(ci.children[0]); err != nil || !oid.Equal(oidSignedData) {
+		return nil, errors.New("PKCS#7 content is not SignedData")
+	}
+	wrapper := ci.children[1]
+	if !wrapper.is(classContext, 0) || len(wrapper.children) != 1 {
+		return nil, errors.New("malformed PKCS#7 content")
+	}
+	sd := wrapper.children[0]
+	if !sd.is(classUniversal, tagSequence) || len(sd.children) < 4 {
+		return nil, errors.New("malformed SignedData")
+	}
+
+	result := &signedData{}
+	inner := sd.children[2]
+	if !inner.is(classUniversal, tagSequence) || len(inner.children) == 0 {
+		return nil, errors.New("malformed SignedData content")
+	}
+	if oid, err := parseOID(inner.children[0]); err != nil || !oid.Equal(oidData) {
+		return nil, errors.New("SignedData content is not data")
+	}
+	if len(inner.children) < 2 || !inner.children[1].is(classContext, 0) || len(inner.children[1].children) != 1 {
+		return nil, errors.New("SignedData has no content")
+	}
+	if result.content, err = inner.children[1].children[0].octets(); err != nil {
+		return nil, fmt.Errorf("SignedData content: %w", err)
+	}
+
+	for _, field := range sd.children[3:] {
+		switch {
+		case field.is(classContext, 0):
+			for _, cert := range field.children {
+				result.certificates = append(result.certificates, cert.raw)
+			}
+		case field.is(classUniversal, tagSet):
+			result.signerInfos = field.children
+		}
+	}
+	return result, nil
+}
+
+func parseOID(n node) (asn1.ObjectIdentifier, error) {
+	if !n.is(classUniversal, tagOID) || n.constructed {
+		return nil, errors.New("expected OBJECT IDENTIFIER")
+	}
+	var oid asn1.ObjectIdentifier
+	if _, err := asn1.Unmarshal(n.raw, &oid); err != nil {
+		return nil, err
+	}
+	return oid, nil
+}
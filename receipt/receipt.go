@@ -0,0 +1,316 @@
+package receipt
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrMalformedReceipt is matched under errors.Is when a receipt
+	// cannot be decoded.
+	ErrMalformedReceipt = errors.New("receipt: malformed receipt")
+	// ErrNoTransactionId is returned when a receipt decodes but holds no
+	// transaction identifier.
+	ErrNoTransactionId = errors.New("receipt: no transaction identifier")
+)
+
+// Receipt attribute types Apple documents for the app receipt payload.
+const (
+	attrBundleId                   = 2
+	attrApplicationVersion         = 3
+	attrOpaqueValue                = 4
+	attrSHA1Hash                   = 5
+	attrCreationDate               = 12
+	attrInApp                      = 17
+	attrOriginalApplicationVersion = 19
+	attrExpirationDate             = 21
+)
+
+// In-app purchase receipt attribute types.
+const (
+	attrQuantity                   = 1701
+	attrProductId                  = 1702
+	attrTransactionId              = 1703
+	attrPurchaseDate               = 1704
+	attrOriginalTransactionId      = 1705
+	attrOriginalPurchaseDate       = 1706
+	attrSubscriptionExpirationDate = 1708
+	attrWebOrderLineItemId         = 1711
+	attrCancellationDate           = 1712
+	attrIsTrialPeriod              = 1713
+	attrIsInIntroOfferPeriod       = 1719
+	attrPromotionalOfferId         = 1721
+)
+
+// Receipt The decoded payload of an app receipt.
+type Receipt struct {
+	// The app's bundle identifier.
+	BundleId string
+	// The app's CFBundleVersion when the receipt was created.
+	ApplicationVersion string
+	// The version of the app the customer originally purchased.
+	OriginalApplicationVersion string
+	// When the App Store created the receipt.
+	CreationDate time.Time
+	// When the receipt expires. Zero unless the app was bought through
+	// the Volume Purchase Program.
+	ExpirationDate time.Time
+	// An opaque value hashed with the device identifier and bundle ID
+	// into SHA1Hash.
+	Opaque []byte
+	// The SHA-1 hash that ties the receipt to a device; see
+	// [Receipt.VerifyDeviceIdentifier].
+	SHA1Hash []byte
+	// The in-app purchase receipts, in the order the receipt lists them.
+	InApp []InAppPurchase
+
+	// bundleIdData is the encoded bundle ID attribute value, an input
+	// of SHA1Hash.
+	bundleIdData []byte
+}
+
+// InAppPurchase An in-app purchase record of an app receipt.
+type InAppPurchase struct {
+	// The number of items purchased.
+	Quantity              int64
+	ProductId             string
+	TransactionId         string
+	OriginalTransactionId string
+	// When the App Store charged the customer, or restored the purchase.
+	PurchaseDate time.Time
+	// The purchase date of the original transaction.
+	OriginalPurchaseDate time.Time
+	// When an auto-renewable subscription expires. Zero for other
+	// products.
+	SubscriptionExpirationDate time.Time
+	// When Apple customer support refunded the transaction. Zero unless
+	// it was refunded.
+	CancellationDate time.Time
+	// Identifies the subscription purchase across devices.
+	WebOrderLineItemId int64
+	// Whether the subscription period is a free trial.
+	IsTrialPeriod bool
+	// Whether the subscription period is an introductory price period.
+	IsInIntroOfferPeriod bool
+	// The identifier of the promotional offer the customer redeemed.
+	PromotionalOfferId string
+}
+
+// VerifyDeviceIdentifier reports whether the receipt was issued to the
+// device with the given identifier: on iOS the 16 bytes of
+// identifierForVendor, on macOS the MAC address of the primary network
+// interface. It checks SHA1Hash against the SHA-1 of the identifier,
+// Opaque and the encoded bundle ID.
+func (r *Receipt) VerifyDeviceIdentifier(deviceId []byte) bool {
+	h := sha1.New()
+	h.Write(deviceId)
+	h.Write(r.Opaque)
+	h.Write(r.bundleIdData)
+	return len(r.SHA1Hash) > 0 && bytes.Equal(h.Sum(nil), r.SHA1Hash)
+}
+
+// ParseAppReceipt decodes a base64 app receipt, as the app reads it
+// from Bundle.main.appStoreReceiptURL, without verifying its signature;
+// [Validator.Validate] verifies it. Errors match [ErrMalformedReceipt] under errors.Is.
+func ParseAppReceipt(appReceipt string) (*Receipt, error) {
+	der, err := decodeBase64(appReceipt)
+	if err != nil {
+		return nil, err
+	}
+	sd, err := parsePKCS7(der)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformedReceipt, err)
+	}
+	r, err := parsePayload(sd.content)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformedReceipt, err)
+	}
+	return r, nil
+}
+
+// ExtractTransactionIdFromAppReceipt returns the transaction ID of the
+// first in-app purchase in a base64 app receipt, to pass to
+// GetTransactionHistory. The signature is not verified. A receipt
+// without purchases yields [ErrNoTransactionId].
+func ExtractTransactionIdFromAppReceipt(appReceipt string) (string, error) {
+	r, err := ParseAppReceipt(appReceipt)
+	if err != nil {
+		return "", err
+	}
+	for _, p := range r.InApp {
+		if p.TransactionId != "" {
+			return p.TransactionId, nil
+		}
+	}
+	return "", ErrNoTransactionId
+}
+
+func decodeBase64(s string) ([]byte, error) {
+	s = strings.Join(strings.Fields(s), "")
+	data, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformedReceipt, err)
+	}
+	return data, nil
+}
+
+// attribute is one ReceiptAttribute: SEQUENCE { type, version, value }.
+type attribute struct {
+	typ   int64
+	value []byte
+}
+
+// parseAttributes reads a SET OF ReceiptAttribute.
+func parseAttributes(data []byte) ([]attribute, error) {
+	set, _, err := parseBER(data)
+	if err != nil {
+		return nil, err
+	}
+	if !set.is(classUniversal, tagSet) {
+		return nil, errors.New("receipt payload is not a SET")
+	}
+	attrs := make([]attribute, 0, len(set.children))
+	for _, seq := range set.children {
+		if !seq.is(classUniversal, tagSequence) || len(seq.children) != 3 {
+			return nil, errors.New("malformed receipt attribute")
+		}
+		typ, err := seq.children[0].int()
+		if err != nil {
+			return nil, fmt.Errorf("receipt attribute type: %w", err)
+		}
+		value, err := seq.children[2].octets()
+		if err != nil {
+			return nil, fmt.Errorf("receipt attribute %d: %w", typ, err)
+		}
+		attrs = append(attrs, attribute{typ: typ, value: value})
+	}
+	return attrs, nil
+}
+
+func parsePayload(data []byte) (*Receipt, error) {
+	attrs, err := parseAttributes(data)
+	if err != nil {
+		return nil, err
+	}
+	r := &Receipt{}
+	for _, a := range attrs {
+		switch a.typ {
+		case attrBundleId:
+			r.BundleId, err = stringValue(a)
+			r.bundleIdData = a.value
+		case attrApplicationVersion:
+			r.ApplicationVersion, err = stringValue(a)
+		case attrOriginalApplicationVersion:
+			r.OriginalApplicationVersion, err = stringValue(a)
+		case attrCreationDate:
+			r.CreationDate, err = dateValue(a)
+		case attrExpirationDate:
+			r.ExpirationDate, err = dateValue(a)
+		case attrOpaqueValue:
+			r.Opaque = a.value
+		case attrSHA1Hash:
+			r.SHA1Hash = a.value
+		case attrInApp:
+			var p *InAppPurchase
+			if p, err = parseInApp(a.value); err == nil {
+				r.InApp = append(r.InApp, *p)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+func parseInApp(data []byte) (*InAppPurchase, error) {
+	attrs, err := parseAttributes(data)
+	if err != nil {
+		return nil, fmt.Errorf("in-app purchase: %w", err)
+	}
+	p := &InAppPurchase{}
+	for _, a := range attrs {
+		switch a.typ {
+		case attrQuantity:
+			p.Quantity, err = intValue(a)
+		case attrProductId:
+			p.ProductId, err = stringValue(a)
+		case attrTransactionId:
+			p.TransactionId, err = stringValue(a)
+		case attrPurchaseDate:
+			p.PurchaseDate, err = dateValue(a)
+		case attrOriginalTransactionId:
+			p.OriginalTransactionId, err = stringValue(a)
+		case attrOriginalPurchaseDate:
+			p.OriginalPurchaseDate, err = dateValue(a)
+		case attrSubscriptionExpirationDate:
+			p.SubscriptionExpirationDate, err = dateValue(a)
+		case attrWebOrderLineItemId:
+			p.WebOrderLineItemId, err = intValue(a)
+		case attrCancellationDate:
+			p.CancellationDate, err = dateValue(a)
+		case attrIsTrialPeriod:
+			p.IsTrialPeriod, err = boolValue(a)
+		case attrIsInIntroOfferPeriod:
+			p.IsInIntroOfferPeriod, err = boolValue(a)
+		case attrPromotionalOfferId:
+			p.PromotionalOfferId, err = stringValue(a)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("in-app purchase: %w", err)
+		}
+	}
+	return p, nil
+}
+
+// stringValue decodes an attribute whose value is an encoded string.
+func stringValue(a attribute) (string, error) {
+	n, _, err := parseBER(a.value)
+	if err != nil {
+		return "", fmt.Errorf("attribute %d: %w", a.typ, err)
+	}
+	s, err := n.string()
+	if err != nil {
+		return "", fmt.Errorf("attribute %d: %w", a.typ, err)
+	}
+	return s, nil
+}
+
+// dateValue decodes an attribute whose value is an RFC 3339 date
+// string. An empty string, which receipts use for absent dates, yields
+// the zero time.
+func dateValue(a attribute) (time.Time, error) {
+	s, err := stringValue(a)
+	if err != nil || s == "" {
+		return time.Time{}, err
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("attribute %d: %w", a.typ, err)
+	}
+	return t, nil
+}
+
+// intValue decodes an attribute whose value is an encoded INTEGER.
+func intValue(a attribute) (int64, error) {
+	n, _, err := parseBER(a.value)
+	if err != nil {
+		return 0, fmt.Errorf("attribute %d: %w", a.typ, err)
+	}
+	v, err := n.int()
+	if err != nil {
+		return 0, fmt.Errorf("attribute %d: %w", a.typ, err)
+	}
+	return v, nil
+}
+
+// boolValue decodes an INTEGER flag attribute.
+func boolValue(a attribute) (bool, error) {
+	v, err := intValue(a)
+	return v != 0, err
+}
@@ -0,0 +1,89 @@
+package receipt
+
+import (
+	"errors"
+	"testing"
+)
+
+func testPayload(t *testing.T) []byte {
+	return attrSet(t,
+		utf8Attr(attrBundleId, "com.example.app"),
+		utf8Attr(attrApplicationVersion, "42"),
+		rawAttr(attrInApp, attrSet(t,
+			utf8Attr(attrProductId, "com.example.monthly"),
+			utf8Attr(attrTransactionId, "2000000111"),
+			utf8Attr(attrOriginalTransactionId, "2000000100"),
+		)),
+		rawAttr(attrInApp, attrSet(t,
+			utf8Attr(attrProductId, "com.example.coins"),
+			utf8Attr(attrTransactionId, "2000000222"),
+			utf8Attr(attrOriginalTransactionId, "2000000222"),
+		)),
+	)
+}
+
+func TestParseAppReceipt(t *testing.T) {
+	for name, container := range map[string][]byte{
+		"DER": wrapPKCS7(t, testPayload(t)),
+		"BER": wrapPKCS7BER(t, testPayload(t)),
+	} {
+		t.Run(name, func(t *testing.T) {
+			r, err := ParseAppReceipt(b64(container))
+			if err != nil {
+				t.Fatalf("ParseAppReceipt: %v", err)
+			}
+			if r.BundleId != "com.example.app" || r.ApplicationVersion != "42" || len(r.InApp) != 2 {
+				t.Fatalf("receipt = %+v", r)
+			}
+			if p := r.InApp[0]; p.ProductId != "com.example.monthly" || p.TransactionId != "2000000111" || p.OriginalTransactionId != "2000000100" {
+				t.Errorf("InApp[0] = %+v", p)
+			}
+			id, err := ExtractTransactionIdFromAppReceipt(b64(container))
+			if err != nil || id != "2000000111" {
+				t.Errorf("ExtractTransactionIdFromAppReceipt = %q, %v", id, err)
+			}
+		})
+	}
+}
+
+func TestExtractTransactionIdFromAppReceipt_Errors(t *testing.T) {
+	empty := wrapPKCS7(t, attrSet(t, utf8Attr(attrBundleId, "com.example.app")))
+	if _, err := ExtractTransactionIdFromAppReceipt(b64(empty)); !errors.Is(err, ErrNoTransactionId) {
+		t.Errorf("no purchases: err = %v", err)
+	}
+	for name, input := range map[string]string{
+		"not base64":  "%%%",
+		"not PKCS#7":  b64([]byte{0x30, 0x03, 0x02, 0x01, 0x01}),
+		"truncated":   b64(wrapPKCS7(t, testPayload(t))[:40]),
+		"bad payload": b64(wrapPKCS7(t, []byte{0x04, 0x01, 0x00})),
+	} {
+		if _, err := ExtractTransactionIdFromAppReceipt(input); !errors.Is(err, ErrMalformedReceipt) {
+			t.Errorf("%s: err = %v", name, err)
+		}
+	}
+
+	// Deep nesting is rejected instead of recursing without bound.
+	deep := []byte{0x04, 0x00}
+	for i := 0; i < 2*maxDepth; i++ {
+		deep = der(0x30, deep)
+	}
+	if _, _, err := parseBER(deep); err == nil {
+		t.Error("deeply nested value accepted")
+	}
+}
+
+func TestExtractTransactionIdFromTransactionReceipt(t *testing.T) {
+	purchaseInfo := b64([]byte("{\n\t\"original-transaction-id\" = \"1000000001\";\n\t\"transaction-id\" = \"1000000002\";\n\t\"product-id\" = \"com.example.coins\";\n}"))
+	receipt := b64([]byte("{\n\t\"signature\" = \"AAAA\";\n\t\"purchase-info\" = \"" + purchaseInfo + "\";\n\t\"environment\" = \"Sandbox\";\n}"))
+
+	id, err := ExtractTransactionIdFromTransactionReceipt(receipt)
+	if err != nil || id != "1000000002" {
+		t.Errorf("ExtractTransactionIdFromTransactionReceipt = %q, %v", id, err)
+	}
+	if _, err := ExtractTransactionIdFromTransactionReceipt(b64([]byte(`{"signature" = "AAAA";}`))); !errors.Is(err, ErrNoTransactionId) {
+		t.Errorf("no purchase-info: err = %v", err)
+	}
+	if _, err := ExtractTransactionIdFromTransactionReceipt("%%%"); !errors.Is(err, ErrMalformedReceipt) {
+		t.Errorf("not base64: err = %v", err)
+	}
+}
@@ -0,0 +1,201 @@
+package receipt
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	_ "crypto/sha1"
+	_ "crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+var (
+	oidSHA1            = asn1.ObjectIdentifier{1, 3, 14, 3, 2, 26}
+	oidSHA256          = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+	oidRSAEncryption   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 1}
+	oidSHA1WithRSA     = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 5}
+	oidSHA256WithRSA   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 11}
+	oidECDSAWithSHA256 = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 2}
+	oidContentType     = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 3}
+	oidMessageDigest   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 4}
+)
+
+// signerInfo is the part of a PKCS#7 SignerInfo needed to check it.
+type signerInfo struct {
+	issuer []byte
+	serial *big.Int
+	digest crypto.Hash
+	// signedAttrs is the [0] IMPLICIT SET OF Attribute, if present.
+	signedAttrs *node
+	sigAlg      asn1.ObjectIdentifier
+	signature   []byte
+}
+
+// parseSignerInfo reads SEQUENCE { version, issuerAndSerialNumber,
+// digestAlgorithm, [0] signedAttrs OPTIONAL, signatureAlgorithm,
+// signature, ... }.
+func parseSignerInfo(n node) (*signerInfo, error) {
+	if !n.is(classUniversal, tagSequence) || len(n.children) < 5 {
+		return nil, errors.New("malformed SignerInfo")
+	}
+	sid := n.children[1]
+	if !sid.is(classUniversal, tagSequence) || len(sid.children) != 2 {
+		return nil, errors.New("SignerInfo is not identified by issuer and serial number")
+	}
+	si := &signerInfo{issuer: sid.children[0].raw, serial: new(big.Int)}
+	if _, err := asn1.Unmarshal(sid.children[1].raw, &si.serial); err != nil {
+		return nil, fmt.Errorf("SignerInfo serial number: %w", err)
+	}
+
+	digestAlg, err := parseAlgorithm(n.children[2])
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case digestAlg.Equal(oidSHA1):
+		si.digest = crypto.SHA1
+	case digestAlg.Equal(oidSHA256):
+		si.digest = crypto.SHA256
+	default:
+		return nil, fmt.Errorf("unsupported digest algorithm %v", digestAlg)
+	}
+
+	rest := n.children[3:]
+	if rest[0].is(classContext, 0) {
+		si.signedAttrs = &rest[0]
+		rest = rest[1:]
+	}
+	if len(rest) < 2 {
+		return nil, errors.New("malformed SignerInfo")
+	}
+	if si.sigAlg, err = parseAlgorithm(rest[0]); err != nil {
+		return nil, err
+	}
+	if si.signature, err = rest[1].octets(); err != nil {
+		return nil, fmt.Errorf("SignerInfo signature: %w", err)
+	}
+	return si, nil
+}
+
+// parseAlgorithm returns the OID of an AlgorithmIdentifier.
+func parseAlgorithm(n node) (asn1.ObjectIdentifier, error) {
+	if !n.is(classUniversal, tagSequence) || len(n.children) == 0 {
+		return nil, errors.New("malformed AlgorithmIdentifier")
+	}
+	return parseOID(n.children[0])
+}
+
+// verifySignedData checks the signature of sd's single signer over its
+// content and returns the certificates with the signer's first, ready
+// for chain validation. It does not validate the chain itself.
+func verifySignedData(sd *signedData) ([]*x509.Certificate, error) {
+	if len(sd.signerInfos) != 1 {
+		return nil, fmt.Errorf("expected one signer, got %d", len(sd.signerInfos))
+	}
+	si, err := parseSignerInfo(sd.signerInfos[0])
+	if err != nil {
+		return nil, err
+	}
+
+	var signer *x509.Certificate
+	var others []*x509.Certificate
+	for i, der := range sd.certificates {
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return nil, fmt.Errorf("certificate %d: %w", i, err)
+		}
+		if signer == nil && bytes.Equal(cert.RawIssuer, si.issuer) && cert.SerialNumber.Cmp(si.serial) == 0 {
+			signer = cert
+		} else {
+			others = append(others, cert)
+		}
+	}
+	if signer == nil {
+		return nil, errors.New("signer certificate not found")
+	}
+
+	signed := sd.content
+	if si.signedAttrs != nil {
+		if err := checkSignedAttrs(*si.signedAttrs, si.digest, sd.content); err != nil {
+			return nil, err
+		}
+		// The signature covers the DER SET OF encoding of the
+		// attributes, not their [0] IMPLICIT form.
+		signed = append([]byte{0x31}, si.signedAttrs.raw[1:]...)
+	}
+	h := si.digest.New()
+	h.Write(signed)
+	if err := checkSignature(signer.PublicKey, si, h.Sum(nil)); err != nil {
+		return nil, err
+	}
+	return append([]*x509.Certificate{signer}, others...), nil
+}
+
+// checkSignedAttrs requires a messageDigest attribute matching content
+// and, if present, a contentType attribute of data.
+func checkSignedAttrs(attrs node, digest crypto.Hash, content []byte) error {
+	h := digest.New()
+	h.Write(content)
+	want := h.Sum(nil)
+
+	found := false
+	for _, a := range attrs.children {
+		if !a.is(classUniversal, tagSequence) || len(a.children) != 2 || len(a.children[1].children) != 1 {
+			return errors.New("malformed signed attribute")
+		}
+		oid, err := parseOID(a.children[0])
+		if err != nil {
+			return err
+		}
+		value := a.children[1].children[0]
+		switch {
+		case oid.Equal(oidMessageDigest):
+			got, err := value.octets()
+			if err != nil {
+				return fmt.Errorf("messageDigest: %w", err)
+			}
+			if !bytes.Equal(got, want) {
+				return errors.New("messageDigest does not match content")
+			}
+			found = true
+		case oid.Equal(oidContentType):
+			if ct, err := parseOID(value); err != nil || !ct.Equal(oidData) {
+				return errors.New("contentType is not data")
+			}
+		}
+	}
+	if !found {
+		return errors.New("signed attributes lack messageDigest")
+	}
+	return nil
+}
+
+func checkSignature(pub any, si *signerInfo, digest []byte) error {
+	switch key := pub.(type) {
+	case *rsa.PublicKey:
+		ok := si.sigAlg.Equal(oidRSAEncryption) ||
+			si.sigAlg.Equal(oidSHA1WithRSA) && si.digest == crypto.SHA1 ||
+			si.sigAlg.Equal(oidSHA256WithRSA) && si.digest == crypto.SHA256
+		if !ok {
+			return fmt.Errorf("signature algorithm %v does not match RSA key", si.sigAlg)
+		}
+		if err := rsa.VerifyPKCS1v15(key, si.digest, digest, si.signature); err != nil {
+			return fmt.Errorf("RSA verify: %w", err)
+		}
+	case *ecdsa.PublicKey:
+		if !si.sigAlg.Equal(oidECDSAWithSHA256) || si.digest != crypto.SHA256 {
+			return fmt.Errorf("signature algorithm %v does not match ECDSA key", si.sigAlg)
+		}
+		if !ecdsa.VerifyASN1(key, digest, si.signature) {
+			return errors.New("ECDSA verify failed")
+		}
+	default:
+		return fmt.Errorf("unsupported signer key %T", pub)
+	}
+	return nil
+}
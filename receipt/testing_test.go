@@ -0,0 +1,127 @@
+package receipt
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/asn1"
+	"encoding/base64"
+	"testing"
+
+	"github.com/godrealms/go-apple-sdk/internal/testchain"
+)
+
+// der encodes a definite-length TLV.
+func der(tag byte, content ...[]byte) []byte {
+	var body []byte
+	for _, c := range content {
+		body = append(body, c...)
+	}
+	out := []byte{tag}
+	switch n := len(body); {
+	case n < 0x80:
+		out = append(out, byte(n))
+	case n < 0x100:
+		out = append(out, 0x81, byte(n))
+	case n < 0x10000:
+		out = append(out, 0x82, byte(n>>8), byte(n))
+	default:
+		out = append(out, 0x83, byte(n>>16), byte(n>>8), byte(n))
+	}
+	return append(out, body...)
+}
+
+// indefinite encodes a constructed value with indefinite length.
+func indefinite(tag byte, children ...[]byte) []byte {
+	out := []byte{tag, 0x80}
+	for _, c := range children {
+		out = append(out, c...)
+	}
+	return append(out, 0, 0)
+}
+
+func mustMarshal(t *testing.T, v any) []byte {
+	t.Helper()
+	b, err := asn1.Marshal(v)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	return b
+}
+
+// attr is a receipt attribute whose value is the encoding of v.
+type attr struct {
+	typ int
+	v   []byte
+}
+
+func utf8Attr(typ int, s string) attr { return attr{typ, der(0x0c, []byte(s))} }
+func ia5Attr(typ int, s string) attr  { return attr{typ, der(0x16, []byte(s))} }
+func rawAttr(typ int, v []byte) attr  { return attr{typ, v} }
+
+func intAttr(t *testing.T, typ int, v int64) attr { return attr{typ, mustMarshal(t, v)} }
+
+// attrSet encodes a SET OF ReceiptAttribute.
+func attrSet(t *testing.T, attrs ...attr) []byte {
+	var items [][]byte
+	for _, a := range attrs {
+		items = append(items, der(0x30, mustMarshal(t, a.typ), mustMarshal(t, 1), der(0x04, a.v)))
+	}
+	return der(0x31, items...)
+}
+
+// wrapPKCS7 wraps payload in an unsigned DER SignedData ContentInfo.
+func wrapPKCS7(t *testing.T, payload []byte) []byte {
+	content := der(0x30, mustMarshal(t, oidData), der(0xa0, der(0x04, payload)))
+	sd := der(0x30, mustMarshal(t, 1), der(0x31), content, der(0x31))
+	return der(0x30, mustMarshal(t, oidSignedData), der(0xa0, sd))
+}
+
+// wrapSignedPKCS7 wraps payload in DER SignedData signed by tc's leaf
+// with ECDSA-SHA256, embedding the leaf and intermediate certificates.
+// With signedAttrs the signature covers contentType and messageDigest
+// attributes instead of the payload itself.
+func wrapSignedPKCS7(t *testing.T, payload []byte, tc *testchain.Chain, signedAttrs bool) []byte {
+	t.Helper()
+	algID := func(oid asn1.ObjectIdentifier) []byte { return der(0x30, mustMarshal(t, oid)) }
+
+	signed, attrs := payload, []byte(nil)
+	if signedAttrs {
+		digest := sha256.Sum256(payload)
+		set := der(0x31,
+			der(0x30, mustMarshal(t, oidContentType), der(0x31, mustMarshal(t, oidData))),
+			der(0x30, mustMarshal(t, oidMessageDigest), der(0x31, der(0x04, digest[:]))),
+		)
+		signed, attrs = set, append([]byte{0xa0}, set[1:]...)
+	}
+	hash := sha256.Sum256(signed)
+	sig, err := ecdsa.SignASN1(rand.Reader, tc.LeafKey, hash[:])
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+
+	si := der(0x30,
+		mustMarshal(t, 1),
+		der(0x30, tc.Leaf.RawIssuer, mustMarshal(t, tc.Leaf.SerialNumber)),
+		algID(oidSHA256),
+		attrs,
+		algID(oidECDSAWithSHA256),
+		der(0x04, sig),
+	)
+	content := der(0x30, mustMarshal(t, oidData), der(0xa0, der(0x04, payload)))
+	certs := der(0xa0, tc.Intermediate.Raw, tc.Leaf.Raw)
+	sd := der(0x30, mustMarshal(t, 1), der(0x31, algID(oidSHA256)), content, certs, der(0x31, si))
+	return der(0x30, mustMarshal(t, oidSignedData), der(0xa0, sd))
+}
+
+// wrapPKCS7BER is wrapPKCS7 using indefinite lengths and a chunked
+// OCTET STRING, the way newer receipts are encoded.
+func wrapPKCS7BER(t *testing.T, payload []byte) []byte {
+	half := len(payload) / 2
+	octets := indefinite(0x24, der(0x04, payload[:half]), der(0x04, payload[half:]))
+	content := indefinite(0x30, mustMarshal(t, oidData), indefinite(0xa0, octets))
+	sd := indefinite(0x30, mustMarshal(t, 1), der(0x31), content, der(0x31))
+	return indefinite(0x30, mustMarshal(t, oidSignedData), indefinite(0xa0, sd))
+}
+
+func b64(b []byte) string { return base64.StdEncoding.EncodeToString(b) }
@@ -0,0 +1,33 @@
+package receipt
+
+import (
+	"regexp"
+)
+
+var (
+	purchaseInfoPattern  = regexp.MustCompile(`"purchase-info"\s*=\s*"([A-Za-z0-9+/=]+)";`)
+	transactionIdPattern = regexp.MustCompile(`"transaction-id"\s*=\s*"([A-Za-z0-9+/=]+)";`)
+)
+
+// ExtractTransactionIdFromTransactionReceipt returns the transaction ID
+// in a base64 SKPaymentTransaction.transactionReceipt, the per-purchase
+// receipt of iOS 6 and earlier StoreKit. The signature is not verified.
+func ExtractTransactionIdFromTransactionReceipt(transactionReceipt string) (string, error) {
+	outer, err := decodeBase64(transactionReceipt)
+	if err != nil {
+		return "", err
+	}
+	m := purchaseInfoPattern.FindSubmatch(outer)
+	if m == nil {
+		return "", ErrNoTransactionId
+	}
+	info, err := decodeBase64(string(m[1]))
+	if err != nil {
+		return "", err
+	}
+	m = transactionIdPattern.FindSubmatch(info)
+	if m == nil {
+		return "", ErrNoTransactionId
+	}
+	return string(m[1]), nil
+}
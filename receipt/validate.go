@@ -0,0 +1,87 @@
+package receipt
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/godrealms/go-apple-sdk/jws"
+)
+
+var (
+	// ErrInvalidSignature is matched under errors.Is when a receipt's
+	// PKCS#7 signature or certificate chain does not verify. A chain
+	// failure also matches *jws.VerificationError under errors.As.
+	ErrInvalidSignature = errors.New("receipt: invalid signature")
+	// ErrReceiptMismatch is matched under errors.Is when a validly
+	// signed receipt was issued for another app, version or device.
+	ErrReceiptMismatch = errors.New("receipt: receipt does not match")
+)
+
+// Validator validates app receipts offline, the checks Apple documents
+// for on-device validation, in place of the verifyReceipt endpoint.
+type Validator struct {
+	// Verifier holds the trust anchors the receipt's signing
+	// certificate must chain to. App receipts are issued under the
+	// Apple Inc. Root Certificate rather than the Apple Root CA G3 that
+	// jws.DefaultVerifier embeds; nil uses [DefaultVerifier], which
+	// embeds that root.
+	Verifier *jws.Verifier
+	// BundleId The app's bundle identifier. Required.
+	BundleId string
+	// ApplicationVersion When set, the receipt's ApplicationVersion
+	// must equal it.
+	ApplicationVersion string
+	// DeviceIdentifier When set, the receipt must have been issued to
+	// this device; see [Receipt.VerifyDeviceIdentifier].
+	DeviceIdentifier []byte
+}
+
+// Validate verifies a base64 app receipt and returns its decoded
+// payload. It checks the PKCS#7 signature, chains the signing
+// certificate to the Verifier's roots as of the receipt's creation date,
+// since the App Store does not re-sign receipts apps keep on disk, and
+// then checks the bundle ID, version and device.
+//
+// Errors match [ErrMalformedReceipt], [ErrInvalidSignature] or
+// [ErrReceiptMismatch] under errors.Is.
+func (v Validator) Validate(appReceipt string) (*Receipt, error) {
+	if v.BundleId == "" {
+		return nil, errors.New("receipt: Validator needs a BundleId")
+	}
+	verifier := v.Verifier
+	if verifier == nil {
+		var err error
+		if verifier, err = DefaultVerifier(); err != nil {
+			return nil, err
+		}
+	}
+	der, err := decodeBase64(appReceipt)
+	if err != nil {
+		return nil, err
+	}
+	sd, err := parsePKCS7(der)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformedReceipt, err)
+	}
+	chain, err := verifySignedData(sd)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+	r, err := parsePayload(sd.content)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformedReceipt, err)
+	}
+	if err := verifier.VerifyChain(chain, r.CreationDate); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSignature, err)
+	}
+
+	switch {
+	case r.BundleId != v.BundleId:
+		return nil, fmt.Errorf("%w: bundle ID %q", ErrReceiptMismatch, r.BundleId)
+	case v.ApplicationVersion != "" && r.ApplicationVersion != v.ApplicationVersion:
+		return nil, fmt.Errorf("%w: application version %q", ErrReceiptMismatch, r.ApplicationVersion)
+	case v.DeviceIdentifier != nil && !r.VerifyDeviceIdentifier(v.DeviceIdentifier):
+		return nil, fmt.Errorf("%w: device identifier", ErrReceiptMismatch)
+	}
+	return r, nil
+}
@@ -0,0 +1,168 @@
+package receipt
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/godrealms/go-apple-sdk/internal/testchain"
+	"github.com/godrealms/go-apple-sdk/jws"
+)
+
+var testDevice = []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f, 0x10}
+
+// fullPayload is a receipt carrying every decoded field, issued to
+// testDevice at created.
+func fullPayload(t *testing.T, created time.Time) []byte {
+	opaque := []byte{0xde, 0xad, 0xbe, 0xef}
+	h := sha1.New()
+	h.Write(testDevice)
+	h.Write(opaque)
+	h.Write(der(0x0c, []byte("com.example.app")))
+
+	return attrSet(t,
+		utf8Attr(attrBundleId, "com.example.app"),
+		utf8Attr(attrApplicationVersion, "42"),
+		utf8Attr(attrOriginalApplicationVersion, "7"),
+		ia5Attr(attrCreationDate, created.Format(time.RFC3339)),
+		ia5Attr(attrExpirationDate, ""),
+		rawAttr(attrOpaqueValue, opaque),
+		rawAttr(attrSHA1Hash, h.Sum(nil)),
+		rawAttr(attrInApp, attrSet(t,
+			intAttr(t, attrQuantity, 1),
+			utf8Attr(attrProductId, "com.example.monthly"),
+			utf8Attr(attrTransactionId, "2000000111"),
+			utf8Attr(attrOriginalTransactionId, "2000000100"),
+			ia5Attr(attrPurchaseDate, "2024-05-01T08:00:00Z"),
+			ia5Attr(attrOriginalPurchaseDate, "2024-04-01T08:00:00Z"),
+			ia5Attr(attrSubscriptionExpirationDate, "2024-06-01T08:00:00Z"),
+			ia5Attr(attrCancellationDate, ""),
+			intAttr(t, attrWebOrderLineItemId, 2000000033),
+			intAttr(t, attrIsTrialPeriod, 0),
+			intAttr(t, attrIsInIntroOfferPeriod, 1),
+			utf8Attr(attrPromotionalOfferId, "winback"),
+		)),
+	)
+}
+
+func TestValidator_Validate(t *testing.T) {
+	tc := testchain.New(t)
+	created := time.Now().UTC().Truncate(time.Second)
+	v := Validator{
+		Verifier:           jws.NewVerifier(jws.WithRootCAs(tc.RootPool)),
+		BundleId:           "com.example.app",
+		ApplicationVersion: "42",
+		DeviceIdentifier:   testDevice,
+	}
+
+	for _, signedAttrs := range []bool{false, true} {
+		r, err := v.Validate(b64(wrapSignedPKCS7(t, fullPayload(t, created), tc, signedAttrs)))
+		if err != nil {
+			t.Fatalf("signedAttrs=%v: Validate: %v", signedAttrs, err)
+		}
+		if r.OriginalApplicationVersion != "7" || !r.CreationDate.Equal(created) || !r.ExpirationDate.IsZero() || len(r.InApp) != 1 {
+			t.Fatalf("receipt = %+v", r)
+		}
+		want := InAppPurchase{
+			Quantity:                   1,
+			ProductId:                  "com.example.monthly",
+			TransactionId:              "2000000111",
+			OriginalTransactionId:      "2000000100",
+			PurchaseDate:               time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC),
+			OriginalPurchaseDate:       time.Date(2024, 4, 1, 8, 0, 0, 0, time.UTC),
+			SubscriptionExpirationDate: time.Date(2024, 6, 1, 8, 0, 0, 0, time.UTC),
+			WebOrderLineItemId:         2000000033,
+			IsInIntroOfferPeriod:       true,
+			PromotionalOfferId:         "winback",
+		}
+		if r.InApp[0] != want {
+			t.Errorf("InApp[0] = %+v", r.InApp[0])
+		}
+	}
+}
+
+func TestValidator_Validate_Signature(t *testing.T) {
+	tc := testchain.New(t)
+	created := time.Now().UTC().Truncate(time.Second)
+	v := Validator{Verifier: jws.NewVerifier(jws.WithRootCAs(tc.RootPool)), BundleId: "com.example.app"}
+
+	tamper := func(signedAttrs bool) []byte {
+		b := wrapSignedPKCS7(t, fullPayload(t, created), tc, signedAttrs)
+		i := bytes.Index(b, []byte("com.example.monthly"))
+		b[i] = 'C'
+		return b
+	}
+	for name, input := range map[string][]byte{
+		"unsigned":                 wrapPKCS7(t, fullPayload(t, created)),
+		"tampered":                 tamper(false),
+		"tampered with attributes": tamper(true),
+	} {
+		if _, err := v.Validate(b64(input)); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("%s: err = %v", name, err)
+		}
+	}
+
+	// A receipt signed under another root fails chain validation.
+	other := testchain.New(t)
+	_, err := v.Validate(b64(wrapSignedPKCS7(t, fullPayload(t, created), other, true)))
+	var vErr *jws.VerificationError
+	if !errors.Is(err, ErrInvalidSignature) || !errors.As(err, &vErr) || vErr.Reason != jws.ReasonChain {
+		t.Errorf("other root: err = %v", err)
+	}
+
+	// The chain is checked as of the receipt's creation date.
+	_, err = v.Validate(b64(wrapSignedPKCS7(t, fullPayload(t, created.Add(48*time.Hour)), tc, true)))
+	if !errors.As(err, &vErr) || vErr.Reason != jws.ReasonExpired {
+		t.Errorf("created after leaf expiry: err = %v", err)
+	}
+}
+
+func TestValidator_Validate_Mismatch(t *testing.T) {
+	tc := testchain.New(t)
+	receipt := b64(wrapSignedPKCS7(t, fullPayload(t, time.Now().UTC()), tc, true))
+	verifier := jws.NewVerifier(jws.WithRootCAs(tc.RootPool))
+
+	for name, v := range map[string]Validator{
+		"bundle ID": {Verifier: verifier, BundleId: "com.example.other"},
+		"version":   {Verifier: verifier, BundleId: "com.example.app", ApplicationVersion: "41"},
+		"device":    {Verifier: verifier, BundleId: "com.example.app", DeviceIdentifier: []byte{0x01}},
+	} {
+		if _, err := v.Validate(receipt); !errors.Is(err, ErrReceiptMismatch) {
+			t.Errorf("%s: err = %v", name, err)
+		}
+	}
+	// Without a Verifier the receipt must chain to the Apple Inc. Root.
+	if _, err := (Validator{BundleId: "com.example.app"}).Validate(receipt); err == nil {
+		t.Error("DefaultVerifier accepted a receipt from a test root")
+	}
+	if _, err := (Validator{Verifier: verifier}).Validate(receipt); err == nil {
+		t.Error("Validator without BundleId accepted a receipt")
+	}
+}
+
+func TestRootPool(t *testing.T) {
+	tc := testchain.New(t)
+	rootPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: tc.Root.Raw})
+	sum := sha256.Sum256(tc.Root.Raw)
+
+	pool, err := rootPool(rootPEM, hex.EncodeToString(sum[:]))
+	if err != nil {
+		t.Fatalf("rootPool: %v", err)
+	}
+	v := Validator{Verifier: jws.NewVerifier(jws.WithRootCAs(pool)), BundleId: "com.example.app"}
+	if _, err := v.Validate(b64(wrapSignedPKCS7(t, fullPayload(t, time.Now().UTC()), tc, true))); err != nil {
+		t.Errorf("Validate under pinned root: %v", err)
+	}
+
+	if _, err := rootPool(rootPEM, appleIncRootSHA256); err == nil {
+		t.Error("rootPool accepted a certificate with another fingerprint")
+	}
+	if _, err := rootPool([]byte("not a certificate"), appleIncRootSHA256); err == nil {
+		t.Error("rootPool accepted a file without a certificate")
+	}
+}
@@ -0,0 +1,28 @@
+// Package replay records real App Store Server API and App Store
+// Connect API traffic to fixture files and serves it back without a
+// network, for integration tests of code built on the SDK.
+//
+// A [Recorder] is an http.RoundTripper. Plug it into the root client
+// with Apple.WithTransport, or into an App Store Connect service through
+// Config.HTTPClient (see [Recorder.Client]):
+//
+//	rec, err := replay.New("testdata/lookup.json", replay.ModeReplay)
+//	if err != nil {
+//	    t.Fatal(err)
+//	}
+//	client := Apple.NewClient(false, kid, iss, bid, key, Apple.WithTransport(rec))
+//	asc := AppStoreConnect.New(AppStoreConnect.Config{
+//	    Authorizer: auth,
+//	    HTTPClient: rec.Client(),
+//	})
+//
+// In [ModeRecord] requests go to Apple and each exchange is kept in
+// memory until [Recorder.Save] writes the fixture. The Authorization
+// header is scrubbed by default; signed JWS payloads in response bodies
+// can be scrubbed too with [WithScrubJWS].
+//
+// In [ModeReplay] a request is answered with the first unused recorded
+// exchange that has the same method, path and query (compared with
+// parameters sorted). Repeated identical requests get the recorded
+// responses in order, so polling and retries replay faithfully.
+package replay
@@ -0,0 +1,356 @@
+package replay
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/godrealms/go-apple-sdk/internal/redact"
+)
+
+// Mode selects whether a [Recorder] talks to Apple or to its fixture.
+type Mode int
+
+const (
+	// ModeReplay serves responses from the fixture file and never
+	// touches the network.
+	ModeReplay Mode = iota
+	// ModeRecord forwards requests to the real transport and captures
+	// every exchange.
+	ModeRecord
+)
+
+// String implements fmt.Stringer.
+func (m Mode) String() string {
+	switch m {
+	case ModeReplay:
+		return "replay"
+	case ModeRecord:
+		return "record"
+	}
+	return fmt.Sprintf("Mode(%d)", int(m))
+}
+
+// scrubbed replaces secrets in recorded fixtures.
+const scrubbed = "[REDACTED]"
+
+// Option configures a [Recorder].
+type Option func(*Recorder)
+
+// WithTransport sets the transport used in record mode. Defaults to
+// http.DefaultTransport.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(r *Recorder) { r.transport = rt }
+}
+
+// WithScrubAuthorization controls whether the Authorization request
+// header is masked in recorded fixtures. On by default.
+func WithScrubAuthorization(enabled bool) Option {
+	return func(r *Recorder) { r.scrubAuth = enabled }
+}
+
+// WithScrubJWS controls whether signed JWS values in recorded bodies
+// are masked. Off by default, since code under test usually needs to
+// decode them; switch it on before committing fixtures that carry real
+// customer transactions.
+func WithScrubJWS(enabled bool) Option {
+	return func(r *Recorder) { r.scrubJWS = enabled }
+}
+
+// WithMatchHost controls whether the request host takes part in
+// matching. On by default, so production and sandbox calls to the same
+// path (as made by AppStoreServer.FallbackService) replay their own
+// responses. Switch it off for fixtures recorded against a local test
+// server whose port changes between runs.
+func WithMatchHost(enabled bool) Option {
+	return func(r *Recorder) { r.matchHost = enabled }
+}
+
+// Recorder is an http.RoundTripper that records or replays exchanges.
+// It is safe for concurrent use.
+type Recorder struct {
+	path      string
+	mode      Mode
+	transport http.RoundTripper
+	scrubAuth bool
+	scrubJWS  bool
+	matchHost bool
+
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+// New returns a Recorder backed by the fixture at path. In replay mode
+// the fixture is loaded immediately and must exist.
+func New(path string, mode Mode, opts ...Option) (*Recorder, error) {
+	r := &Recorder{
+		path:      path,
+		mode:      mode,
+		transport: http.DefaultTransport,
+		scrubAuth: true,
+		matchHost: true,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	switch mode {
+	case ModeReplay:
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("replay: %w", err)
+		}
+		var fixture Fixture
+		if err := json.Unmarshal(data, &fixture); err != nil {
+			return nil, fmt.Errorf("replay: %s: %w", path, err)
+		}
+		r.interactions = fixture.Interactions
+		r.used = make([]bool, len(fixture.Interactions))
+	case ModeRecord:
+	default:
+		return nil, fmt.Errorf("replay: unknown mode %v", mode)
+	}
+	return r, nil
+}
+
+// Mode returns the recorder's mode.
+func (r *Recorder) Mode() Mode { return r.mode }
+
+// Client returns an *http.Client using the recorder as its transport,
+// for AppStoreConnect.Config.HTTPClient.
+func (r *Recorder) Client() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	if r.mode == ModeRecord {
+		return r.record(req)
+	}
+	return r.replay(req)
+}
+
+// Save writes the recorded exchanges to the fixture file, creating its
+// directory. It does nothing in replay mode.
+func (r *Recorder) Save() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+	r.mu.Lock()
+	data, err := json.MarshalIndent(Fixture{Interactions: r.interactions}, "", "  ")
+	r.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("replay: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return fmt.Errorf("replay: %w", err)
+	}
+	if err := os.WriteFile(r.path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("replay: %w", err)
+	}
+	return nil
+}
+
+// Unused returns the recorded exchanges replay has not served yet, so
+// tests can assert that the code under test made every expected call.
+func (r *Recorder) Unused() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []Interaction
+	for i, used := range r.used {
+		if !used {
+			out = append(out, r.interactions[i])
+		}
+	}
+	return out
+}
+
+// record sends req through the wrapped transport and stores the
+// exchange. The body is buffered for the recording, so a clone carrying
+// the buffered body is sent; a RoundTripper must not modify req.
+func (r *Recorder) record(req *http.Request) (*http.Response, error) {
+	out := req
+	var reqBody []byte
+	if req.Body != nil {
+		var err error
+		reqBody, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		out = req.Clone(req.Context())
+		out.Body = io.NopCloser(bytes.NewReader(reqBody))
+		out.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(reqBody)), nil
+		}
+	}
+	resp, err := r.transport.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	reqHeader := req.Header.Clone()
+	if r.scrubAuth && reqHeader.Get("Authorization") != "" {
+		reqHeader.Set("Authorization", scrubbed)
+	}
+	recordedBody := respBody
+	if r.scrubJWS {
+		reqBody = redact.JWSPattern.ReplaceAll(reqBody, []byte(scrubbed))
+		recordedBody = redact.JWSPattern.ReplaceAll(respBody, []byte(scrubbed))
+	}
+
+	r.mu.Lock()
+	r.interactions = append(r.interactions, Interaction{
+		Request: Request{
+			Method: req.Method,
+			URL:    req.URL.String(),
+			Header: reqHeader,
+			Body:   newBody(reqBody),
+		},
+		Response: Response{
+			StatusCode: resp.StatusCode,
+			Header:     resp.Header.Clone(),
+			Body:       newBody(recordedBody),
+		},
+	})
+	r.mu.Unlock()
+	return resp, nil
+}
+
+func (r *Recorder) replay(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	key := matchKey(req.Method, req.URL, r.matchHost)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, in := range r.interactions {
+		if r.used[i] {
+			continue
+		}
+		u, err := url.Parse(in.Request.URL)
+		if err != nil || matchKey(in.Request.Method, u, r.matchHost) != key {
+			continue
+		}
+		r.used[i] = true
+		body, err := in.Response.Body.bytes()
+		if err != nil {
+			return nil, fmt.Errorf("replay: %s: interaction %d: %w", r.path, i, err)
+		}
+		header := in.Response.Header.Clone()
+		if header == nil {
+			header = make(http.Header)
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", in.Response.StatusCode, http.StatusText(in.Response.StatusCode)),
+			StatusCode:    in.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          io.NopCloser(bytes.NewReader(body)),
+			ContentLength: int64(len(body)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("replay: %s: no unused recorded response for %s", r.path, key)
+}
+
+// matchKey is the identity a request is replayed by: method, host
+// (unless host is false), path and the query with keys and values
+// sorted.
+func matchKey(method string, u *url.URL, host bool) string {
+	query := u.Query()
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	for _, k := range keys {
+		values := append([]string(nil), query[k]...)
+		sort.Strings(values)
+		for _, v := range values {
+			if b.Len() > 0 {
+				b.WriteByte('&')
+			}
+			b.WriteString(url.QueryEscape(k) + "=" + url.QueryEscape(v))
+		}
+	}
+	path := u.EscapedPath()
+	if host {
+		path = u.Host + path
+	}
+	key := strings.ToUpper(method) + " " + path
+	if b.Len() > 0 {
+		key += "?" + b.String()
+	}
+	return key
+}
+
+// Fixture is the on-disk format of a recording.
+type Fixture struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is one recorded request/response pair.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request is the recorded request. Only Method and URL take part in
+// matching: the method, host, path and query, with the host left out
+// under WithMatchHost(false). The scheme and the rest are kept for the
+// reader of the fixture.
+type Request struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   Body        `json:"body"`
+}
+
+// Response is the recorded response.
+type Response struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       Body        `json:"body"`
+}
+
+// Body holds a message body as text when it is valid UTF-8 (JSON, TSV)
+// and as base64 otherwise (gzipped reports), so fixtures stay readable
+// and diffable where possible.
+type Body struct {
+	Text   string `json:"text,omitempty"`
+	Base64 string `json:"base64,omitempty"`
+}
+
+func newBody(b []byte) Body {
+	if utf8.Valid(b) {
+		return Body{Text: string(b)}
+	}
+	return Body{Base64: base64.StdEncoding.EncodeToString(b)}
+}
+
+func (b Body) bytes() ([]byte, error) {
+	if b.Base64 != "" {
+		return base64.StdEncoding.DecodeString(b.Base64)
+	}
+	return []byte(b.Text), nil
+}
@@ -0,0 +1,244 @@
+package replay_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	Apple "github.com/godrealms/go-apple-sdk"
+	AppStoreConnect "github.com/godrealms/go-apple-sdk/app-store-connect"
+	AppStoreServer "github.com/godrealms/go-apple-sdk/app-store-server"
+	"github.com/godrealms/go-apple-sdk/replay"
+)
+
+const signedTransaction = "eyJhbGciOiJFUzI1NiJ9.eyJ0cmFuc2FjdGlvbklkIjoiMSJ9.c2ln"
+
+func newTestKeyPEM(t *testing.T) string {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+}
+
+// apple stands in for both Apple APIs.
+func apple(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/inApps/v1/transactions/"):
+			_, _ = w.Write([]byte(`{"signedTransactionInfo":"` + signedTransaction + `"}`))
+		case r.URL.Path == "/v1/apps":
+			_, _ = w.Write([]byte(`{"data":[{"type":"apps","id":"` + r.URL.Query().Get("limit") + `"}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// exercise drives both clients through rec and returns what they saw.
+func exercise(t *testing.T, rec *replay.Recorder, baseURL string, key string) (string, string) {
+	t.Helper()
+	client := Apple.NewClient(false, "KID", "iss", "com.example", key, Apple.WithTransport(rec))
+	info, err := AppStoreServer.New(client, AppStoreServer.Config{BaseURL: baseURL}).
+		GetTransactionInfo(context.Background(), "1")
+	if err != nil {
+		t.Fatalf("GetTransactionInfo: %v", err)
+	}
+
+	asc := AppStoreConnect.New(AppStoreConnect.Config{
+		BaseURL: baseURL,
+		Authorizer: AppStoreConnect.AuthorizerFunc(func(req *http.Request) error {
+			req.Header.Set("Authorization", "Bearer secret-token")
+			return nil
+		}),
+		HTTPClient: rec.Client(),
+	})
+	apps, err := asc.Apps().List(context.Background(), AppStoreConnect.NewQuery().Limit(7).Sort("name"))
+	if err != nil {
+		t.Fatalf("Apps.List: %v", err)
+	}
+	return string(info.SignedTransactionInfo), apps.Data[0].Id
+}
+
+func TestRecordThenReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fixtures", "lookup.json")
+	srv := apple(t)
+	key := newTestKeyPEM(t)
+
+	rec, err := replay.New(path, replay.ModeRecord)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jws, appID := exercise(t, rec, srv.URL, key)
+	if err := rec.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	srv.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "secret-token") || strings.Contains(string(data), "Bearer eyJ") {
+		t.Error("fixture contains the Authorization header")
+	}
+
+	player, err := replay.New(path, replay.ModeReplay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotJWS, gotAppID := exercise(t, player, srv.URL, key)
+	if gotJWS != jws || gotAppID != appID || gotAppID != "7" {
+		t.Errorf("replayed %q %q, recorded %q %q", gotJWS, gotAppID, jws, appID)
+	}
+	if unused := player.Unused(); len(unused) != 0 {
+		t.Errorf("unused interactions: %d", len(unused))
+	}
+
+	// A second run has nothing left to replay.
+	if _, err := player.Client().Get(srv.URL + "/inApps/v1/transactions/1"); err == nil ||
+		!strings.Contains(err.Error(), "no unused recorded response") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestReplay_MatchesNormalizedQueryInOrder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "poll.json")
+	fixture := `{"interactions": [
+  {"request": {"method": "GET", "url": "https://api.example/v1/x?b=2&a=1&a=0"}, "response": {"statusCode": 202, "body": {"text": "first"}}},
+  {"request": {"method": "GET", "url": "https://api.example/v1/x?a=0&a=1&b=2"}, "response": {"statusCode": 200, "body": {"base64": "H4sI"}}}
+]}`
+	if err := os.WriteFile(path, []byte(fixture), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	player, err := replay.New(path, replay.ModeReplay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := player.Client()
+	for _, want := range []int{202, 200} {
+		resp, err := client.Get("https://api.example/v1/x?a=1&b=2&a=0")
+		if err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Errorf("status = %d, want %d", resp.StatusCode, want)
+		}
+	}
+	if _, err := client.Get("https://api.example/v1/x?a=1"); err == nil {
+		t.Error("different query matched")
+	}
+}
+
+func TestReplay_MatchesHost(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fallback.json")
+	fixture := `{"interactions": [
+  {"request": {"method": "GET", "url": "https://api.storekit.itunes.apple.com/inApps/v1/transactions/1"}, "response": {"statusCode": 404, "body": {"text": "production"}}},
+  {"request": {"method": "GET", "url": "https://api.storekit-sandbox.itunes.apple.com/inApps/v1/transactions/1"}, "response": {"statusCode": 200, "body": {"text": "sandbox"}}}
+]}`
+	if err := os.WriteFile(path, []byte(fixture), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	get := func(c *http.Client, url string) int {
+		t.Helper()
+		resp, err := c.Get(url)
+		if err != nil {
+			t.Fatalf("Get %s: %v", url, err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	// The sandbox call is answered by the sandbox recording even when
+	// it comes first.
+	player, _ := replay.New(path, replay.ModeReplay)
+	if got := get(player.Client(), "https://api.storekit-sandbox.itunes.apple.com/inApps/v1/transactions/1"); got != 200 {
+		t.Errorf("sandbox status = %d, want 200", got)
+	}
+	if _, err := player.Client().Get("https://other.host/inApps/v1/transactions/1"); err == nil {
+		t.Error("different host matched")
+	}
+
+	// WithMatchHost(false) serves fixtures recorded on another host.
+	player, _ = replay.New(path, replay.ModeReplay, replay.WithMatchHost(false))
+	if got := get(player.Client(), "http://127.0.0.1:1234/inApps/v1/transactions/1"); got != 404 {
+		t.Errorf("host-agnostic status = %d, want 404", got)
+	}
+}
+
+func TestRecord_ScrubJWS(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scrubbed.json")
+	srv := apple(t)
+	rec, _ := replay.New(path, replay.ModeRecord, replay.WithScrubJWS(true))
+	resp, err := rec.Client().Get(srv.URL + "/inApps/v1/transactions/1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if err := rec.Save(); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), signedTransaction) {
+		t.Error("JWS not scrubbed")
+	}
+}
+
+// transportFunc adapts a function to http.RoundTripper.
+type transportFunc func(*http.Request) (*http.Response, error)
+
+func (f transportFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestRecord_DoesNotModifyRequest(t *testing.T) {
+	var sent *http.Request
+	var sentBody string
+	inner := transportFunc(func(req *http.Request) (*http.Response, error) {
+		sent = req
+		b, _ := io.ReadAll(req.Body)
+		sentBody = string(b)
+		return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(strings.NewReader("{}")), Request: req}, nil
+	})
+	rec, err := replay.New(filepath.Join(t.TempDir(), "post.json"), replay.ModeRecord, replay.WithTransport(inner))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body := io.NopCloser(strings.NewReader(`{"a":1}`))
+	req, _ := http.NewRequest(http.MethodPost, "https://api.example.com/x", nil)
+	req.Body = body
+	if _, err := rec.RoundTrip(req); err != nil {
+		t.Fatal(err)
+	}
+	if sent == req || req.Body != body {
+		t.Error("Recorder passed on or modified the caller's request")
+	}
+	if sentBody != `{"a":1}` {
+		t.Errorf("sent body = %q", sentBody)
+	}
+}
+
+func TestNew_ReplayNeedsFixture(t *testing.T) {
+	if _, err := replay.New(filepath.Join(t.TempDir(), "missing.json"), replay.ModeReplay); err == nil {
+		t.Error("missing fixture accepted")
+	}
+}
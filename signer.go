@@ -0,0 +1,93 @@
+package Apple
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// es256SignatureSize is the length of an ES256 signature in the IEEE
+// P1363 (r ‖ s) form JWS requires.
+const es256SignatureSize = 64
+
+// KeySource supplies the key ID and signer a [Client] uses for what it
+// signs per call: scoped App Store Connect tokens and
+// [Client.SignJWS] payloads. A source that rotates keys, such as
+// credentials.Keyring, lets both follow the rotation.
+//
+// Implementations must be safe for concurrent use.
+type KeySource interface {
+	SigningKey() (kid string, signer crypto.Signer, err error)
+}
+
+// WithKeySource replaces the [KeySource] of the client and of any
+// client derived from it via [Client.ForService]. Bearer tokens are
+// unaffected; set their sources with [WithTokenSource].
+func WithKeySource(source KeySource) ClientOption {
+	return func(client *Client) { client.keys = source }
+}
+
+// staticKey is the [KeySource] for the key a client was built with.
+// err is the parse or validation failure of that key, if any.
+type staticKey struct {
+	kid    string
+	signer crypto.Signer
+	err    error
+}
+
+func (k staticKey) SigningKey() (string, crypto.Signer, error) {
+	return k.kid, k.signer, k.err
+}
+
+// signJWT serialises token and signs it with signer. The token's
+// signing method must be ES256.
+//
+// crypto.Signer implementations (the stdlib ECDSA key, cloud KMS
+// clients, PKCS#11 bindings) return ASN.1 DER signatures, while JWS
+// wants the fixed-size r ‖ s form, so the signature is converted here.
+// Signers that already return the 64-byte form are accepted as-is.
+func signJWT(token *jwt.Token, signer crypto.Signer) (string, error) {
+	signingString, err := token.SigningString()
+	if err != nil {
+		return "", err
+	}
+	digest := sha256.Sum256([]byte(signingString))
+	sig, err := signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	if err != nil {
+		return "", err
+	}
+	p1363, err := derToP1363(sig)
+	if err != nil {
+		return "", err
+	}
+	return signingString + "." + token.EncodeSegment(p1363), nil
+}
+
+// derToP1363 converts an ASN.1 DER ECDSA-Sig-Value into the 64-byte
+// r ‖ s encoding used by ES256.
+func derToP1363(sig []byte) ([]byte, error) {
+	var parsed struct {
+		R, S *big.Int
+	}
+	rest, err := asn1.Unmarshal(sig, &parsed)
+	if err != nil || len(rest) > 0 {
+		if len(sig) == es256SignatureSize {
+			return sig, nil
+		}
+		return nil, fmt.Errorf("signature is neither ASN.1 DER nor %d-byte P1363", es256SignatureSize)
+	}
+	if parsed.R.Sign() <= 0 || parsed.S.Sign() <= 0 ||
+		parsed.R.BitLen() > 256 || parsed.S.BitLen() > 256 {
+		return nil, errors.New("signature values out of range for P-256")
+	}
+	out := make([]byte, es256SignatureSize)
+	parsed.R.FillBytes(out[:32])
+	parsed.S.FillBytes(out[32:])
+	return out, nil
+}
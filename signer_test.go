@@ -0,0 +1,125 @@
+package Apple
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// opaqueSigner hides the concrete key type, like a KMS or PKCS#11
+// handle would. With rawSignature set it returns r ‖ s instead of DER.
+type opaqueSigner struct {
+	key          *ecdsa.PrivateKey
+	rawSignature bool
+	calls        int
+}
+
+func (s *opaqueSigner) Public() crypto.PublicKey { return &s.key.PublicKey }
+
+func (s *opaqueSigner) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	s.calls++
+	if !s.rawSignature {
+		return s.key.Sign(rand, digest, opts)
+	}
+	r, sig, err := ecdsa.Sign(rand, s.key, digest)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, 64)
+	r.FillBytes(out[:32])
+	sig.FillBytes(out[32:])
+	return out, nil
+}
+
+func TestSignJWT_VerifiesWithPublicKey(t *testing.T) {
+	for _, raw := range []bool{false, true} {
+		signer := &opaqueSigner{key: newTestKey(t), rawSignature: raw}
+		token, err := NewAppStoreServerTokenSource("KID", "iss", "com.example", signer).Token()
+		if err != nil {
+			t.Fatalf("raw=%v: Token: %v", raw, err)
+		}
+		_, err = jwt.Parse(token, func(*jwt.Token) (any, error) { return &signer.key.PublicKey, nil },
+			jwt.WithValidMethods([]string{"ES256"}))
+		if err != nil {
+			t.Errorf("raw=%v: parse: %v", raw, err)
+		}
+	}
+}
+
+func TestValidateSigner_RejectsOtherCurves(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewAppStoreConnectTokenSource("KID", "iss", key).Token(); err == nil ||
+		!strings.Contains(err.Error(), "P-256") {
+		t.Errorf("err = %v, want P-256 error", err)
+	}
+	if _, err := NewAppStoreConnectTokenSource("KID", "iss", nil).Token(); err == nil {
+		t.Error("nil signer accepted")
+	}
+}
+
+func TestDerToP1363_RejectsGarbage(t *testing.T) {
+	if _, err := derToP1363([]byte("not a signature")); err == nil {
+		t.Error("garbage signature accepted")
+	}
+}
+
+func TestNewClientWithSigner(t *testing.T) {
+	signer := &opaqueSigner{key: newTestKey(t)}
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+	}))
+	defer srv.Close()
+
+	client := NewClientWithSigner(false, "KID", "iss", "com.example", signer).
+		ForService(AppStoreServerClient, srv.URL)
+	if err := client.Request(RequestParams{Method: http.MethodGet, Path: "/"}); err != nil {
+		t.Fatalf("Request: %v", err)
+	}
+	token, ok := strings.CutPrefix(auth, "Bearer ")
+	if !ok {
+		t.Fatalf("Authorization = %q", auth)
+	}
+	if _, err := jwt.Parse(token, func(*jwt.Token) (any, error) { return &signer.key.PublicKey, nil }); err != nil {
+		t.Errorf("parse: %v", err)
+	}
+
+	scoped, err := NewClientWithSigner(false, "KID", "iss", "", signer).
+		GenerateAppStoreConnectAuthorizationJWT(http.MethodGet, "/v1/apps")
+	if err != nil || !strings.HasPrefix(scoped, "Bearer ") {
+		t.Errorf("scoped token = %q, %v", scoped, err)
+	}
+}
+
+func TestClient_SignJWS(t *testing.T) {
+	signer := &opaqueSigner{key: newTestKey(t)}
+	client := NewClientWithSigner(false, "KID", "iss", "com.example", signer)
+
+	signed, err := client.SignJWS(jwt.MapClaims{"aud": "test-aud", "bid": "com.override"})
+	if err != nil {
+		t.Fatalf("SignJWS: %v", err)
+	}
+	claims := jwt.MapClaims{}
+	token, err := jwt.ParseWithClaims(signed, claims, func(*jwt.Token) (any, error) { return &signer.key.PublicKey, nil },
+		jwt.WithValidMethods([]string{"ES256"}))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if token.Header["kid"] != "KID" || token.Header["typ"] != "JWT" {
+		t.Errorf("header = %v", token.Header)
+	}
+	if claims["iss"] != "iss" || claims["bid"] != "com.override" || claims["aud"] != "test-aud" || claims["iat"] == nil {
+		t.Errorf("claims = %v", claims)
+	}
+}
@@ -0,0 +1,153 @@
+package Apple
+
+import (
+	"crypto"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/godrealms/go-apple-sdk/internal/es256"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Token lifetimes. Apple rejects App Store Connect tokens that live
+// longer than 20 minutes; the App Store Server API allows up to 60 but
+// we stay well below.
+const (
+	AppStoreServerTokenLifetime  = 5 * time.Minute
+	AppStoreConnectTokenLifetime = 20 * time.Minute
+
+	// tokenRefreshMargin is how long before exp a cached token is
+	// replaced, so a token never expires while a request is in flight.
+	tokenRefreshMargin = time.Minute
+)
+
+// TokenSource supplies signed bearer tokens (without the "Bearer "
+// prefix) for Apple's APIs.
+//
+// Implementations must be safe for concurrent use.
+type TokenSource interface {
+	Token() (string, error)
+}
+
+// JWTTokenSource is a [TokenSource] that signs ES256 JWTs with an App
+// Store Connect API key and caches each token until shortly before it
+// expires. The key is any P-256 [crypto.Signer]. Concurrent callers
+// share one cached token; when it is due for renewal exactly one of
+// them signs a new one.
+//
+// A JWTTokenSource also satisfies AppStoreConnect.Authorizer, so it can
+// be passed directly as AppStoreConnect.Config.Authorizer.
+type JWTTokenSource struct {
+	signer   crypto.Signer
+	kid      string
+	claims   jwt.MapClaims
+	lifetime time.Duration
+	now      func() time.Time
+	err      error // permanent construction error, returned by Token
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+// NewAppStoreServerTokenSource returns a cached token source for the
+// App Store Server API and App Store Server Notifications. The token
+// carries the app's bundle ID in the "bid" claim.
+func NewAppStoreServerTokenSource(kid, iss, bid string, signer crypto.Signer) *JWTTokenSource {
+	return newJWTTokenSource(signer, kid, jwt.MapClaims{
+		"iss": iss,
+		"aud": "appstoreconnect-v1",
+		"bid": bid,
+	}, AppStoreServerTokenLifetime)
+}
+
+// NewAppStoreConnectTokenSource returns a cached token source for the
+// App Store Connect API. Tokens are unscoped, so one token is valid for
+// every endpoint.
+func NewAppStoreConnectTokenSource(kid, iss string, signer crypto.Signer) *JWTTokenSource {
+	return newJWTTokenSource(signer, kid, jwt.MapClaims{
+		"iss": iss,
+		"aud": "appstoreconnect-v1",
+	}, AppStoreConnectTokenLifetime)
+}
+
+func newJWTTokenSource(signer crypto.Signer, kid string, claims jwt.MapClaims, lifetime time.Duration) *JWTTokenSource {
+	if err := es256.ValidateSigner(signer); err != nil {
+		return failedTokenSource(err)
+	}
+	return &JWTTokenSource{
+		signer:   signer,
+		kid:      kid,
+		claims:   claims,
+		lifetime: lifetime,
+		now:      time.Now,
+	}
+}
+
+// failedTokenSource returns a token source whose Token always fails
+// with err. It is used when the key cannot be parsed or is not P-256,
+// so the error surfaces on the first request instead of at
+// construction (NewClient has no error return).
+func failedTokenSource(err error) *JWTTokenSource {
+	return &JWTTokenSource{err: err}
+}
+
+// Token returns the cached token, signing a new one when there is none
+// yet or the cached one expires within a minute.
+func (ts *JWTTokenSource) Token() (string, error) {
+	if ts.err != nil {
+		return "", ts.err
+	}
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	now := ts.now()
+	if ts.token != "" && now.Add(tokenRefreshMargin).Before(ts.expiresAt) {
+		return ts.token, nil
+	}
+	expiresAt := now.Add(ts.lifetime)
+	claims := jwt.MapClaims{
+		"iat": now.Unix(),
+		"exp": expiresAt.Unix(),
+	}
+	for k, v := range ts.claims {
+		claims[k] = v
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+	token.Header["kid"] = ts.kid
+	signed, err := signJWT(token, ts.signer)
+	if err != nil {
+		return "", fmt.Errorf("sign token: %w", err)
+	}
+	ts.token = signed
+	ts.expiresAt = expiresAt
+	return signed, nil
+}
+
+// Authorize sets the "Authorization: Bearer <token>" header on req. It
+// makes JWTTokenSource satisfy AppStoreConnect.Authorizer.
+func (ts *JWTTokenSource) Authorize(req *http.Request) error {
+	token, err := ts.Token()
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+// WithTokenSource replaces the token source the client uses to sign
+// requests for service. AppStoreServerClient and
+// AppStoreServerNotificationsClient share one source; setting either
+// replaces both.
+func WithTokenSource(service AppleClient, source TokenSource) ClientOption {
+	return func(client *Client) {
+		switch service {
+		case AppStoreConnectClient:
+			client.connectTokens = source
+		case AppStoreServerClient, AppStoreServerNotificationsClient:
+			client.serverTokens = source
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package Apple
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func newTestKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	return key
+}
+
+func TestJWTTokenSource_CachesUntilRefreshMargin(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	ts := NewAppStoreServerTokenSource("KID", "iss", "com.example", newTestKey(t))
+	ts.now = func() time.Time { return now }
+
+	first, err := ts.Token()
+	if err != nil {
+		t.Fatalf("Token: %v", err)
+	}
+	now = now.Add(AppStoreServerTokenLifetime - tokenRefreshMargin - time.Second)
+	second, _ := ts.Token()
+	if second != first {
+		t.Error("token re-signed before the refresh margin")
+	}
+	now = now.Add(2 * time.Second)
+	third, _ := ts.Token()
+	if third == first {
+		t.Error("token not refreshed inside the refresh margin")
+	}
+}
+
+func TestJWTTokenSource_Claims(t *testing.T) {
+	key := newTestKey(t)
+	ts := NewAppStoreServerTokenSource("KID", "issuer", "com.example", key)
+	raw, err := ts.Token()
+	if err != nil {
+		t.Fatalf("Token: %v", err)
+	}
+	parsed, err := jwt.Parse(raw, func(*jwt.Token) (any, error) { return &key.PublicKey, nil },
+		jwt.WithValidMethods([]string{"ES256"}), jwt.WithAudience("appstoreconnect-v1"))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if parsed.Header["kid"] != "KID" {
+		t.Errorf("kid = %v", parsed.Header["kid"])
+	}
+	claims := parsed.Claims.(jwt.MapClaims)
+	if claims["iss"] != "issuer" || claims["bid"] != "com.example" {
+		t.Errorf("claims = %v", claims)
+	}
+
+	connect, err := NewAppStoreConnectTokenSource("KID", "issuer", key).Token()
+	if err != nil {
+		t.Fatalf("Token: %v", err)
+	}
+	parsed, err = jwt.Parse(connect, func(*jwt.Token) (any, error) { return &key.PublicKey, nil })
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if _, ok := parsed.Claims.(jwt.MapClaims)["bid"]; ok {
+		t.Error("App Store Connect token must not carry bid")
+	}
+}
+
+func TestJWTTokenSource_Concurrent(t *testing.T) {
+	ts := NewAppStoreConnectTokenSource("KID", "iss", newTestKey(t))
+	const N = 32
+	tokens := make([]string, N)
+	var wg sync.WaitGroup
+	wg.Add(N)
+	for i := 0; i < N; i++ {
+		go func(i int) {
+			defer wg.Done()
+			tokens[i], _ = ts.Token()
+		}(i)
+	}
+	wg.Wait()
+	for i := 1; i < N; i++ {
+		if tokens[i] != tokens[0] {
+			t.Fatalf("token[%d] differs; concurrent callers should share one token", i)
+		}
+	}
+}
+
+func TestJWTTokenSource_Authorize(t *testing.T) {
+	ts := NewAppStoreConnectTokenSource("KID", "iss", newTestKey(t))
+	req, _ := http.NewRequest("GET", "https://example.invalid", nil)
+	if err := ts.Authorize(req); err != nil {
+		t.Fatalf("Authorize: %v", err)
+	}
+	token, _ := ts.Token()
+	if req.Header.Get("Authorization") != "Bearer "+token {
+		t.Errorf("Authorization = %q", req.Header.Get("Authorization"))
+	}
+}
+
+func TestNewClient_InvalidKeyFailsOnUse(t *testing.T) {
+	client := NewClient(true, "KID", "iss", "bid", "not a key")
+	if _, err := client.GenerateAppStoreServerAuthorizationJWT(); err == nil {
+		t.Error("expected parse error from server token")
+	}
+	if _, err := client.GenerateAppStoreConnectAuthorizationJWT("GET", "/v1/apps"); err == nil {
+		t.Error("expected parse error from scoped token")
+	}
+}
+
+func TestWithTokenSource(t *testing.T) {
+	want := errors.New("custom source")
+	client := NewClient(true, "KID", "iss", "bid", newTestKeyPEM(t),
+		WithTokenSource(AppStoreServerClient, tokenSourceFunc(func() (string, error) { return "", want })))
+	if _, err := client.GenerateAppStoreServerAuthorizationJWT(); !errors.Is(err, want) {
+		t.Errorf("err = %v, want custom source error", err)
+	}
+}
+
+type tokenSourceFunc func() (string, error)
+
+func (f tokenSourceFunc) Token() (string, error) { return f() }
@@ -0,0 +1,85 @@
+package types
+
+import "github.com/godrealms/go-apple-sdk/jws"
+
+// OriginalPlatform The platform on which the customer originally purchased the app.
+type OriginalPlatform string
+
+const (
+	OriginalPlatformIOS      OriginalPlatform = "iOS"      // The customer purchased the app on iOS or iPadOS.
+	OriginalPlatformMacOS    OriginalPlatform = "macOS"    // The customer purchased the app on macOS.
+	OriginalPlatformTVOS     OriginalPlatform = "tvOS"     // The customer purchased the app on tvOS.
+	OriginalPlatformVisionOS OriginalPlatform = "visionOS" // The customer purchased the app on visionOS.
+)
+
+// EnvironmentXcode is the receiptType of an app transaction created in
+// Xcode's StoreKit testing environment.
+const EnvironmentXcode Environment = "Xcode"
+
+// JWSAppTransactionDecodedPayload Information that represents the customer’s purchase of the app,
+// cryptographically signed by the App Store.
+type JWSAppTransactionDecodedPayload struct {
+	// The unique identifier the App Store uses to identify the app.
+	AppAppleId AppAppleId `json:"appAppleId"`
+
+	// The unique identifier of the app download transaction.
+	AppTransactionId string `json:"appTransactionId"`
+
+	// The app version that the app transaction applies to.
+	ApplicationVersion BundleVersion `json:"applicationVersion"`
+
+	// The bundle identifier that the app transaction applies to.
+	BundleId BundleId `json:"bundleId"`
+
+	// A base64-encoded SHA-384 hash that allows you to confirm the app transaction is valid for the device.
+	DeviceVerification string `json:"deviceVerification"`
+
+	// The UUID the device used to compute DeviceVerification.
+	DeviceVerificationNonce UUID `json:"deviceVerificationNonce"`
+
+	// The app version that the customer originally purchased from the App Store.
+	OriginalApplicationVersion BundleVersion `json:"originalApplicationVersion"`
+
+	// The platform on which the customer originally purchased the app.
+	OriginalPlatform OriginalPlatform `json:"originalPlatform"`
+
+	// The UNIX time, in milliseconds, that the customer originally purchased the app from the App Store.
+	OriginalPurchaseDate Timestamp `json:"originalPurchaseDate"`
+
+	// The UNIX time, in milliseconds, that the customer ordered the app before its release, if it was a preorder.
+	PreorderDate Timestamp `json:"preorderDate"`
+
+	// The UNIX time, in milliseconds, that the App Store created the app transaction.
+	ReceiptCreationDate Timestamp `json:"receiptCreationDate"`
+
+	// The server environment that signs the app transaction: Production, Sandbox or Xcode.
+	ReceiptType Environment `json:"receiptType"`
+
+	// The UNIX time, in milliseconds, that the App Store signed the JSON Web Signature (JWS) data.
+	SignedDate Timestamp `json:"signedDate"`
+
+	// The identifier App Store Connect assigns to the app version the app transaction applies to.
+	VersionExternalIdentifier int64 `json:"versionExternalIdentifier"`
+}
+
+// IsPreorder reports whether the customer preordered the app.
+func (p *JWSAppTransactionDecodedPayload) IsPreorder() bool {
+	return p.PreorderDate > 0
+}
+
+// JWSAppTransaction is the signed AppTransaction returned by Get App
+// Transaction Info. Like [JWSTransaction], Decrypt verifies it with the
+// package-default Verifier and DecryptWith accepts a custom one.
+type JWSAppTransaction string
+
+// Decrypt verifies the JWS chain + signature and returns the decoded
+// payload. Returns *jws.VerificationError on failure.
+func (j JWSAppTransaction) Decrypt() (*JWSAppTransactionDecodedPayload, error) {
+	return jws.VerifyAndDecode[JWSAppTransactionDecodedPayload](jws.DefaultVerifier(), string(j))
+}
+
+// DecryptWith verifies using the supplied Verifier instead of the
+// package default.
+func (j JWSAppTransaction) DecryptWith(v *jws.Verifier) (*JWSAppTransactionDecodedPayload, error) {
+	return jws.VerifyAndDecode[JWSAppTransactionDecodedPayload](v, string(j))
+}
@@ -0,0 +1,39 @@
+package types
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/godrealms/go-apple-sdk/internal/testchain"
+	"github.com/godrealms/go-apple-sdk/jws"
+)
+
+func TestJWSAppTransaction_DecryptWith(t *testing.T) {
+	tc := testchain.New(t)
+	raw := JWSAppTransaction(tc.SignJWS(t, JWSAppTransactionDecodedPayload{
+		AppTransactionId:           "704289572311",
+		OriginalApplicationVersion: "1.0",
+		OriginalPlatform:           OriginalPlatformIOS,
+		OriginalPurchaseDate:       1700000000000,
+		ReceiptType:                EnvironmentXcode,
+		VersionExternalIdentifier:  834289833,
+	}))
+	v := jws.NewVerifier(
+		jws.WithRootCAs(tc.RootPool),
+		jws.WithRequiredOIDs(jws.OIDAppleReceiptSigning),
+	)
+	out, err := raw.DecryptWith(v)
+	if err != nil {
+		t.Fatalf("DecryptWith: %v", err)
+	}
+	if out.AppTransactionId != "704289572311" || out.OriginalApplicationVersion != "1.0" ||
+		out.OriginalPlatform != OriginalPlatformIOS || out.ReceiptType != EnvironmentXcode || out.VersionExternalIdentifier != 834289833 || out.IsPreorder() {
+		t.Errorf("payload = %+v", out)
+	}
+
+	_, err = raw.Decrypt()
+	var ve *jws.VerificationError
+	if !errors.As(err, &ve) || ve.Reason != jws.ReasonChain {
+		t.Errorf("default verifier: err = %v, want ReasonChain", err)
+	}
+}
@@ -0,0 +1,8 @@
+package types
+
+// ConsumptionPercentage An integer that indicates the percentage, in milliunits, of the in-app purchase the customer consumed.
+// Valid values are 0 (nothing consumed) through 100000 (fully consumed).
+type ConsumptionPercentage int32
+
+// MaxConsumptionPercentage is a fully consumed purchase: 100% in milliunits.
+const MaxConsumptionPercentage ConsumptionPercentage = 100000
@@ -9,3 +9,16 @@ package types
 // 4: The app didn’t deliver the consumable in-app purchase due to an in-game currency change.
 // 5: The app didn’t deliver the consumable in-app purchase for other reasons.
 type DeliveryStatus int32
+
+// DeliveryStatusV2
+// A value that indicates whether the app successfully delivered an in-app purchase that works properly,
+// as sent to the version 2 Send Consumption Information endpoint.
+type DeliveryStatusV2 string
+
+const (
+	DELIVERY_STATUS_DELIVERED                 DeliveryStatusV2 = "DELIVERED"                 // The app delivered the in-app purchase and it’s working properly.
+	DELIVERY_STATUS_UNDELIVERED_QUALITY_ISSUE DeliveryStatusV2 = "UNDELIVERED_QUALITY_ISSUE" // The app didn’t deliver the in-app purchase due to a quality issue.
+	DELIVERY_STATUS_UNDELIVERED_WRONG_ITEM    DeliveryStatusV2 = "UNDELIVERED_WRONG_ITEM"    // The app delivered the wrong item.
+	DELIVERY_STATUS_UNDELIVERED_SERVER_OUTAGE DeliveryStatusV2 = "UNDELIVERED_SERVER_OUTAGE" // The app didn’t deliver the in-app purchase due to a server outage.
+	DELIVERY_STATUS_UNDELIVERED_OTHER         DeliveryStatusV2 = "UNDELIVERED_OTHER"         // The app didn’t deliver the in-app purchase for other reasons.
+)
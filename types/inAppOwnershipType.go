@@ -2,3 +2,8 @@ package types
 
 // InAppOwnershipType A string that describes whether the transaction was purchased by the customer, or is available to them through Family Sharing.
 type InAppOwnershipType string
+
+const (
+	IN_APP_OWNERSHIP_TYPE_FAMILY_SHARED InAppOwnershipType = "FAMILY_SHARED" // The transaction belongs to a family member who benefits from service.
+	IN_APP_OWNERSHIP_TYPE_PURCHASED     InAppOwnershipType = "PURCHASED"     // The transaction belongs to the purchaser.
+)
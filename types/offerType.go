@@ -0,0 +1,17 @@
+package types
+
+// Values of the offerType field of transactions and renewal info.
+const (
+	OFFER_TYPE_INTRODUCTORY            offerType = 1
+	OFFER_TYPE_PROMOTIONAL             offerType = 2
+	OFFER_TYPE_SUBSCRIPTION_OFFER_CODE offerType = 3
+	OFFER_TYPE_WIN_BACK                offerType = 4
+)
+
+// Values of the offerDiscountType field of transactions and renewal
+// info.
+const (
+	OFFER_DISCOUNT_TYPE_FREE_TRIAL    offerDiscountType = "FREE_TRIAL"
+	OFFER_DISCOUNT_TYPE_PAY_AS_YOU_GO offerDiscountType = "PAY_AS_YOU_GO"
+	OFFER_DISCOUNT_TYPE_PAY_UP_FRONT  offerDiscountType = "PAY_UP_FRONT"
+)
@@ -4,3 +4,8 @@ package types
 // 0: The orderId that you provided in the Look Up Order ID request is valid and contains at least one in-app purchase for your app.
 // 1: The orderId is invalid or doesn’t contain any in-app purchases for your app.
 type OrderLookupStatus int32
+
+const (
+	OrderLookupStatusValid   OrderLookupStatus = 0 // The order ID is valid and contains in-app purchases for your app.
+	OrderLookupStatusInvalid OrderLookupStatus = 1 // The order ID is invalid or contains no in-app purchases for your app.
+)
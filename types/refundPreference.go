@@ -7,3 +7,14 @@ package types
 // 2: You prefer that Apple declines the refund.
 // 3: You have no preference whether Apple grants or declines the refund.
 type RefundPreference int32
+
+// RefundPreferenceV2
+// A value that indicates your preferred outcome for the refund request,
+// as sent to the version 2 Send Consumption Information endpoint.
+type RefundPreferenceV2 string
+
+const (
+	REFUND_PREFERENCE_DECLINE        RefundPreferenceV2 = "DECLINE"        // You prefer that Apple declines the refund.
+	REFUND_PREFERENCE_GRANT_FULL     RefundPreferenceV2 = "GRANT_FULL"     // You prefer that Apple grants a full refund.
+	REFUND_PREFERENCE_GRANT_PRORATED RefundPreferenceV2 = "GRANT_PRORATED" // You prefer that Apple grants a prorated refund.
+)
@@ -1,8 +1,16 @@
 package types
 
+import "github.com/google/uuid"
+
 // RequestIdentifier A string that contains a unique identifier for a subscription-renewal-date extension request.
 type RequestIdentifier string // UUID
 
 func (r RequestIdentifier) String() string {
 	return string(r)
 }
+
+// NewRequestIdentifier returns a random (version 4) UUID suitable as
+// the requestIdentifier of a renewal-date extension request.
+func NewRequestIdentifier() RequestIdentifier {
+	return RequestIdentifier(uuid.NewString())
+}
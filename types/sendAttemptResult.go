@@ -0,0 +1,18 @@
+package types
+
+// SendAttemptResult The success or error information the App Store server records when it attempts to send an App Store server notification to your server.
+type SendAttemptResult string
+
+const (
+	SendAttemptResultSuccess                      SendAttemptResult = "SUCCESS"
+	SendAttemptResultTimedOut                     SendAttemptResult = "TIMED_OUT"
+	SendAttemptResultTLSIssue                     SendAttemptResult = "TLS_ISSUE"
+	SendAttemptResultCircularRedirect             SendAttemptResult = "CIRCULAR_REDIRECT"
+	SendAttemptResultNoResponse                   SendAttemptResult = "NO_RESPONSE"
+	SendAttemptResultSocketIssue                  SendAttemptResult = "SOCKET_ISSUE"
+	SendAttemptResultUnsupportedCharset           SendAttemptResult = "UNSUPPORTED_CHARSET"
+	SendAttemptResultInvalidResponse              SendAttemptResult = "INVALID_RESPONSE"
+	SendAttemptResultPrematureClose               SendAttemptResult = "PREMATURE_CLOSE"
+	SendAttemptResultUnsuccessfulHTTPResponseCode SendAttemptResult = "UNSUCCESSFUL_HTTP_RESPONSE_CODE"
+	SendAttemptResultOther                        SendAttemptResult = "OTHER"
+)
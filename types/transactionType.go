@@ -0,0 +1,10 @@
+package types
+
+// Values of the type field of a transaction
+// (JWSTransactionDecodedPayload.Type).
+const (
+	TRANSACTION_TYPE_AUTO_RENEWABLE_SUBSCRIPTION = "Auto-Renewable Subscription"
+	TRANSACTION_TYPE_NON_RENEWING_SUBSCRIPTION   = "Non-Renewing Subscription"
+	TRANSACTION_TYPE_NON_CONSUMABLE              = "Non-Consumable"
+	TRANSACTION_TYPE_CONSUMABLE                  = "Consumable"
+)